@@ -12,13 +12,14 @@
 package main
 
 import (
-	"encoding/json"
+	"encoding/base64"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"os/exec"
 	"testing"
 
+	"github.com/eclipse/codewind-installer/pkg/globals"
 	"github.com/eclipse/codewind-installer/pkg/security"
 	"github.com/eclipse/codewind-installer/pkg/test"
 	"github.com/stretchr/testify/assert"
@@ -70,14 +71,14 @@ func testUseInsecureKeyring(t *testing.T) {
 		assert.Nil(t, readErr)
 		require.NotNil(t, file)
 
-		secrets := []security.KeyringSecret{}
-		unmarshalErr := json.Unmarshal([]byte(file), &secrets)
-		assert.Nil(t, unmarshalErr)
-		require.Len(t, secrets, 1)
+		assert.NotContains(t, string(file), "seCretphrase")
+		assert.NotContains(t, string(file), base64.StdEncoding.EncodeToString([]byte("seCretphrase")))
 
-		secret := secrets[0]
-		assert.Equal(t, "testuser", string(secret.Username))
-		require.Equal(t, "seCretphrase", string(secret.Password))
+		globals.SetUseInsecureKeyring(true)
+		defer globals.SetUseInsecureKeyring(false)
+		secret, secErr := security.SecKeyGetSecret("local", "testuser")
+		assert.Nil(t, secErr)
+		require.Equal(t, "seCretphrase", secret)
 
 		os.Remove(security.GetPathToInsecureKeyring())
 	})
@@ -99,14 +100,14 @@ func testUseInsecureKeyring(t *testing.T) {
 		assert.Nil(t, readErr)
 		require.NotNil(t, file)
 
-		secrets := []security.KeyringSecret{}
-		unmarshalErr := json.Unmarshal([]byte(file), &secrets)
-		assert.Nil(t, unmarshalErr)
-		require.Len(t, secrets, 1)
+		assert.NotContains(t, string(file), "seCretphrase")
+		assert.NotContains(t, string(file), base64.StdEncoding.EncodeToString([]byte("seCretphrase")))
 
-		secret := secrets[0]
-		assert.Equal(t, "testuser", string(secret.Username))
-		require.Equal(t, "seCretphrase", string(secret.Password))
+		globals.SetUseInsecureKeyring(true)
+		defer globals.SetUseInsecureKeyring(false)
+		secret, secErr := security.SecKeyGetSecret("local", "testuser")
+		assert.Nil(t, secErr)
+		require.Equal(t, "seCretphrase", secret)
 
 		os.Remove(security.GetPathToInsecureKeyring())
 	})
@@ -16,11 +16,15 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/appconstants"
 	desktoputils "github.com/eclipse/codewind-installer/pkg/desktop_utils"
 	"github.com/eclipse/codewind-installer/pkg/errors"
 	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/workspace"
 	logr "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
@@ -72,12 +76,13 @@ func Commands() {
 					Usage: "Create a project on disk",
 
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "url, u", Usage: "URL of project to download", Required: true},
+						cli.StringFlag{Name: "url, u", Usage: "URL of project to download, or a file:// URL or path of a local template directory or tar.gz", Required: true},
 						cli.StringFlag{Name: "path, p", Usage: "The path at which to create the new project", Required: true},
 						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id of PFE which will be used to validate the project", Required: false},
 						cli.StringFlag{Name: "username", Usage: "Username for GitHub account authorized to download the provided URL. Takes precedence over git credentials stored in keychain", Required: false},
 						cli.StringFlag{Name: "password", Usage: "Password for GitHub account authorized to download the provided URL. Takes precedence over git credentials stored in keychain", Required: false},
 						cli.StringFlag{Name: "personalAccessToken", Usage: "PersonalAccessToken authorized to download the provided URL. Takes precedence over git credentials stored in keychain", Required: false},
+						cli.BoolFlag{Name: "offline", Usage: "Only create the project from the local template cache, without downloading the template"},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectCreate(c)
@@ -87,7 +92,7 @@ func Commands() {
 				{
 					Name:    "validate",
 					Aliases: []string{""},
-					Usage:   "Returns the predicted language and build type for a project, and writes a default .cw-settings if one does not already exist",
+					Usage:   "Returns the predicted language and build type for a project, with the detection rule that matched, and writes a default .cw-settings if one does not already exist",
 
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "type, t", Usage: "Known build type of project", Required: false},
@@ -103,11 +108,15 @@ func Commands() {
 					Name:  "bind",
 					Usage: "Bind a project to codewind for building and running",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "name, n", Usage: "The name of the project", Required: true},
-						cli.StringFlag{Name: "language, l", Usage: "The project language", Required: true},
-						cli.StringFlag{Name: "type, t", Usage: "The type of the project", Required: true},
-						cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: true},
+						cli.StringFlag{Name: "name, n", Usage: "The name of the project (required unless scanning)", Required: false},
+						cli.StringFlag{Name: "language, l", Usage: "The project language (required unless scanning)", Required: false},
+						cli.StringFlag{Name: "type, t", Usage: "The type of the project (required unless scanning)", Required: false},
+						cli.StringFlag{Name: "path, p", Usage: "The path to the project (required unless scanning)", Required: false},
 						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id for the project", Required: false},
+						cli.StringFlag{Name: "scan", Usage: "Find the projects in a directory and bind those accepted", Required: false},
+						cli.BoolFlag{Name: "yes, y", Usage: "Bind all the projects found by --scan without asking"},
+						cli.BoolFlag{Name: "wait, w", Usage: "Wait until the project has started, failing if its build fails or its application stops while starting"},
+						cli.DurationFlag{Name: "timeout", Value: 5 * time.Minute, Usage: "How long to wait for the project to start"},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectBind(c)
@@ -132,13 +141,27 @@ func Commands() {
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "path, p", Usage: "the path to the project", Required: true},
 						cli.StringFlag{Name: "id, i", Usage: "the project id", Required: true},
-						cli.StringFlag{Name: "time, t", Usage: "UNIX timestamp of the last sync for the given project, in milliseconds", Required: true},
+						cli.StringFlag{Name: "time, t", Usage: "UNIX timestamp of the last sync for the given project, in milliseconds. Only used if there is no record of the previous sync, when files modified since then are uploaded. 0 always uploads every file", Required: false},
+						cli.IntFlag{Name: "concurrency, c", Usage: "the maximum number of files to upload at once, overriding syncConcurrency in the project's .cw-settings (default: " + strconv.Itoa(project.DefaultSyncConcurrency) + ")", Required: false},
+						cli.BoolFlag{Name: "watch, w", Usage: "keep running, and sync the project each time its files change"},
+						cli.BoolFlag{Name: "dry-run", Usage: "print the files that would be added, modified and deleted, without uploading anything"},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectSync(c)
 						return nil
 					},
 				},
+				{
+					Name:  "ignored",
+					Usage: "List the files and directories in a project that are not synced, and the rule that excludes each of them",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "path, p", Usage: "the path to the project", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectIgnored(c)
+						return nil
+					},
+				},
 				{
 					Name:    "list",
 					Aliases: []string{"ls"},
@@ -151,6 +174,22 @@ func Commands() {
 						return nil
 					},
 				},
+				{
+					Name:  "status",
+					Usage: "Show the status of the projects on every connection",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "status", Usage: "Only show projects with this build or application status, such as failed or started"},
+						cli.StringFlag{Name: "language", Usage: "Only show projects in this language"},
+						cli.StringFlag{Name: "conid", Usage: "Only show projects on the connection with this ID or label"},
+						cli.StringFlag{Name: "sort", Value: "name", Usage: "Sort the projects by name, status, language, connection or sync"},
+						cli.BoolFlag{Name: "watch, w", Usage: "Keep refreshing the status"},
+						cli.DurationFlag{Name: "interval", Value: 2 * time.Second, Usage: "How often to refresh the status when watching"},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectStatus(c)
+						return nil
+					},
+				},
 				{
 					Name:  "get",
 					Usage: "Get a single project, requires either 'id' or 'name'",
@@ -171,12 +210,119 @@ func Commands() {
 						cli.StringFlag{Name: "id,i", Usage: "Project ID", Required: true},
 						cli.StringFlag{Name: "startmode, s", Usage: "Start Mode of the project; can be run, debug, or debugNoInit", Required: true},
 						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id of the remote deployment to use", Required: false},
+						cli.BoolFlag{Name: "with-dependents", Usage: "Also restart, in their current start modes, the projects that link to the project, each once the projects it links to have started. Requires --wait"},
+						cli.BoolFlag{Name: "wait, w", Usage: "Wait until the project has started, failing if its build fails or its application stops while starting"},
+						cli.DurationFlag{Name: "timeout", Value: 5 * time.Minute, Usage: "How long to wait for the project to start"},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectRestart(c)
 						return nil
 					},
 				},
+				{
+					Name:  "logs",
+					Usage: "Show the build or application logs of a project",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+						cli.StringFlag{Name: "type, t", Value: "app", Usage: "The logs to show; can be build or app"},
+						cli.BoolFlag{Name: "follow, f", Usage: "Keep showing the logs as they grow"},
+						cli.IntFlag{Name: "tail", Usage: "The number of lines to show from the end of each log, or all lines if 0"},
+						cli.BoolFlag{Name: "timestamps", Usage: "Show the time docker recorded for each line of a local project's application logs"},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectLogs(c)
+						return nil
+					},
+				},
+				{
+					Name:  "move",
+					Usage: "Move a project to another connection, recreating its links where their target projects are there too",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+						cli.StringFlag{Name: "to", Usage: "The ID of the connection to move the project to", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectMove(c)
+						return nil
+					},
+				},
+				{
+					Name:  "export",
+					Usage: "Export a project's settings, ref paths, connection, start mode and links, to import elsewhere",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+						cli.StringFlag{Name: "file, f", Usage: "The file to write the export to, instead of printing it"},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectExport(c)
+						return nil
+					},
+				},
+				{
+					Name:  "import",
+					Usage: "Bind a checkout of an exported project, recreating its settings and links",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "file, f", Usage: "The export to import", Required: true},
+						cli.StringFlag{Name: "path, p", Usage: "The path to the checkout of the project", Required: true},
+						cli.StringFlag{Name: "conid", Usage: "The connection to bind the project to, instead of the one it was exported from"},
+						cli.StringFlag{Name: "name, n", Usage: "The name to bind the project with, instead of its exported name"},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectImport(c)
+						return nil
+					},
+				},
+				{
+					Name:  "settings",
+					Usage: "Get, set and validate the .cw-settings of a project",
+					Subcommands: []cli.Command{
+						{
+							Name:  "get",
+							Usage: "Print the settings, or the value of one key",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: true},
+								cli.StringFlag{Name: "key, k", Usage: "The key to print the value of"},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectSettingsGet(c)
+								return nil
+							},
+						},
+						{
+							Name:  "set",
+							Usage: "Set the value of a key, leaving the rest of the file as it is",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: true},
+								cli.StringFlag{Name: "key, k", Usage: "The key to set", Required: true},
+								cli.StringFlag{Name: "value, v", Usage: "The value; true or false for booleans, and a JSON array or comma separated list for arrays", Required: true},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectSettingsSet(c)
+								return nil
+							},
+						},
+						{
+							Name:  "validate",
+							Usage: "Check the settings against the schema, reporting unknown keys and invalid values",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: true},
+								cli.StringFlag{Name: "type, t", Usage: "The project type, to warn about keys it doesn't use; detected if not given"},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectSettingsValidate(c)
+								return nil
+							},
+						},
+						{
+							Name:  "schema",
+							Usage: "Print the JSON schema of the settings",
+							Action: func(c *cli.Context) error {
+								ProjectSettingsSchema(c)
+								return nil
+							},
+						},
+					},
+				},
 				{
 					Name:  "link",
 					Usage: "Manage project links",
@@ -232,6 +378,18 @@ func Commands() {
 								return nil
 							},
 						},
+						{
+							Name:  "graph",
+							Usage: "Shows the links between all the projects on a connection, with any cycles and links to removed projects",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id of the remote deployment to use", Required: false},
+								cli.StringFlag{Name: "format, f", Value: "json", Usage: "Output format; can be json or dot", Required: false},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectLinkGraph(c)
+								return nil
+							},
+						},
 					},
 				},
 			},
@@ -413,6 +571,22 @@ func Commands() {
 						return nil
 					},
 				},
+				{
+					Name:  "pull",
+					Usage: "Download every template from the enabled template repos to the local cache, so projects can be created from them offline",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:     "conid",
+							Value:    "local",
+							Usage:    "Connection ID",
+							Required: false,
+						},
+					},
+					Action: func(c *cli.Context) error {
+						PullTemplates(c)
+						return nil
+					},
+				},
 				{
 					Name:  "styles",
 					Usage: "List available template styles",
@@ -456,7 +630,7 @@ func Commands() {
 							Flags: []cli.Flag{
 								cli.StringFlag{
 									Name:  "url",
-									Usage: "URL of the template repo",
+									Usage: "URL of the template repo, or a file:// URL or path of a local templates.json or index.json, or of a directory containing one",
 								},
 								cli.StringFlag{
 									Name:  "description",
@@ -502,7 +676,7 @@ func Commands() {
 							Flags: []cli.Flag{
 								cli.StringFlag{
 									Name:  "url",
-									Usage: "URL of the template repo",
+									Usage: "URL of the template repo, or the file:// URL or path it was added with",
 								},
 								cli.StringFlag{
 									Name:     "conid",
@@ -553,6 +727,35 @@ func Commands() {
 			},
 		},
 
+		{
+			Name:  "workspace",
+			Usage: "Manage the projects, links and template repos of a workspace from a manifest",
+			Subcommands: []cli.Command{
+				{
+					Name:  "diff",
+					Usage: "Show the changes applying a workspace manifest would make",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "file, f", Value: workspace.DefaultManifestFilename, Usage: "The workspace manifest"},
+					},
+					Action: func(c *cli.Context) error {
+						WorkspaceDiff(c)
+						return nil
+					},
+				},
+				{
+					Name:  "apply",
+					Usage: "Bind the projects and create the links and template repos in a workspace manifest that don't already exist",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "file, f", Value: workspace.DefaultManifestFilename, Usage: "The workspace manifest"},
+					},
+					Action: func(c *cli.Context) error {
+						WorkspaceApply(c)
+						return nil
+					},
+				},
+			},
+		},
+
 		//  Security //
 		{
 			Name:    "sectoken",
@@ -586,6 +789,21 @@ func Commands() {
 						SecurityTokenRefresh(c)
 						return nil
 					},
+				}, {
+					Name:    "login",
+					Aliases: []string{"l"},
+					Usage:   "Log in without a password and store the access_token and refresh_token in the keyring",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID", Required: true},
+						cli.BoolFlag{Name: "browser", Usage: "Log in using the browser, with the authorization code flow and PKCE"},
+						cli.BoolFlag{Name: "device", Usage: "Log in on another device, for machines without a browser"},
+						cli.IntFlag{Name: "port", Value: 0, Usage: "The loopback port the browser is redirected to (default: any free port)"},
+						cli.DurationFlag{Name: "timeout", Value: 5 * time.Minute, Usage: "How long to wait for the browser login to complete"},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityTokenLogin(c)
+						return nil
+					},
 				},
 			},
 		},
@@ -619,6 +837,26 @@ func Commands() {
 						SecurityKeyValidate(c)
 						return nil
 					},
+				}, {
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "List the Codewind secrets in the keyring, without their values",
+					Action: func(c *cli.Context) error {
+						SecurityKeyList(c)
+						return nil
+					},
+				}, {
+					Name:    "delete",
+					Aliases: []string{"rm"},
+					Usage:   "Delete a Codewind secret, or all the secrets of a connection, from the keyring",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID (see the connections cmd)", Required: true},
+						cli.StringFlag{Name: "username,u", Usage: "The secret to delete, such as a username or refresh_token (default: all the connection's secrets)"},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityKeyDelete(c)
+						return nil
+					},
 				},
 			},
 		},
@@ -808,7 +1046,7 @@ func Commands() {
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "label", Usage: "A displayable name", Required: true},
 						cli.StringFlag{Name: "url", Usage: "The ingress URL of Codewind gatekeeper", Required: true},
-						cli.StringFlag{Name: "username,u", Usage: "Username", Required: true},
+						cli.StringFlag{Name: "username,u", Usage: "Username, not needed for connections logged in to in the browser or authenticated as a service account"},
 					},
 					Action: func(c *cli.Context) error {
 						ConnectionAddToList(c)
@@ -823,7 +1061,7 @@ func Commands() {
 						cli.StringFlag{Name: "conid", Usage: "Connection ID to update", Required: true},
 						cli.StringFlag{Name: "label", Usage: "A displayable name", Required: true},
 						cli.StringFlag{Name: "url", Usage: "The ingress URL of Codewind gatekeeper", Required: true},
-						cli.StringFlag{Name: "username,u", Usage: "Username", Required: true},
+						cli.StringFlag{Name: "username,u", Usage: "Username, not needed for connections logged in to in the browser or authenticated as a service account"},
 					},
 					Action: func(c *cli.Context) error {
 						ConnectionUpdate(c)
@@ -854,6 +1092,37 @@ func Commands() {
 						return nil
 					},
 				},
+				{
+					Name:  "serviceaccount",
+					Usage: "Authenticate a connection as a service account, using the client credentials grant, for pipelines",
+					Subcommands: []cli.Command{
+						{
+							Name:  "set",
+							Usage: "Authenticate as a confidential client, whose secret is read from an environment variable or a file",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "conid", Usage: "Connection ID", Required: true},
+								cli.StringFlag{Name: "clientid", Usage: "The client ID of the service account", Required: true},
+								cli.StringFlag{Name: "secretenv", Usage: "The environment variable that holds the client secret"},
+								cli.StringFlag{Name: "secretfile", Usage: "The file that holds the client secret"},
+							},
+							Action: func(c *cli.Context) error {
+								ConnectionServiceAccountSet(c)
+								return nil
+							},
+						},
+						{
+							Name:  "remove",
+							Usage: "Authenticate as a user again",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "conid", Usage: "Connection ID", Required: true},
+							},
+							Action: func(c *cli.Context) error {
+								ConnectionServiceAccountRemove(c)
+								return nil
+							},
+						},
+					},
+				},
 				{
 					Name:    "list",
 					Aliases: []string{"ls"},
@@ -82,6 +82,48 @@ func ConnectionGetByID(c *cli.Context) {
 	os.Exit(0)
 }
 
+// ConnectionServiceAccountSet : Configure a connection to authenticate as a service account
+func ConnectionServiceAccountSet(c *cli.Context) {
+	connectionID := strings.TrimSpace(c.String("conid"))
+	account := &connections.ServiceAccount{
+		ClientID:   strings.TrimSpace(c.String("clientid")),
+		SecretEnv:  strings.TrimSpace(c.String("secretenv")),
+		SecretFile: strings.TrimSpace(c.String("secretfile")),
+	}
+	connection, conErr := connections.SetServiceAccount(connectionID, account)
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		os.Exit(1)
+	}
+	printServiceAccountResult(connection, "Service account set")
+}
+
+// ConnectionServiceAccountRemove : Configure a connection to authenticate as a user again
+func ConnectionServiceAccountRemove(c *cli.Context) {
+	connectionID := strings.TrimSpace(c.String("conid"))
+	connection, conErr := connections.SetServiceAccount(connectionID, nil)
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		os.Exit(1)
+	}
+	printServiceAccountResult(connection, "Service account removed")
+}
+
+func printServiceAccountResult(connection *connections.Connection, message string) {
+	if printAsJSON {
+		type Result struct {
+			Status        string `json:"status"`
+			StatusMessage string `json:"status_message"`
+			ConID         string `json:"id"`
+		}
+		response, _ := json.Marshal(Result{Status: "OK", StatusMessage: message, ConID: strings.ToUpper(connection.ID)})
+		fmt.Println(string(response))
+	} else {
+		logr.Printf("%s for connection %v", message, strings.ToUpper(connection.ID))
+	}
+	os.Exit(0)
+}
+
 // ConnectionRemoveFromList : Removes a connection from the connections config file
 // and from associated secrets from the keychain
 func ConnectionRemoveFromList(c *cli.Context) {
@@ -12,12 +12,16 @@
 package actions
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
@@ -48,6 +52,7 @@ func ProjectCreate(c *cli.Context) {
 	username := c.String("username")
 	password := c.String("password")
 	personalAccessToken := c.String("personalAccessToken")
+	offline := c.Bool("offline")
 
 	gitCredentials, err := utils.ExtractGitCredentials(username, password, personalAccessToken)
 	if err != nil {
@@ -55,7 +60,8 @@ func ProjectCreate(c *cli.Context) {
 		HandleTemplateError(templateErr)
 		os.Exit(1)
 	}
-	if gitCredentials == nil {
+	// credentials are only needed to download templates that aren't cached
+	if gitCredentials == nil && !offline {
 		gitCredentials, err = templates.GetGitCredentialsFromKeychain(conID, url)
 		if err != nil {
 			err := &TemplateError{errOpGetGitCredsFromKeychain, err, err.Error()}
@@ -64,7 +70,7 @@ func ProjectCreate(c *cli.Context) {
 		}
 	}
 
-	result, projErr := project.DownloadTemplate(destination, url, gitCredentials)
+	result, projErr := project.DownloadTemplate(destination, url, gitCredentials, offline)
 	if projErr != nil {
 		HandleProjectError(projErr)
 		os.Exit(1)
@@ -80,6 +86,12 @@ func ProjectCreate(c *cli.Context) {
 
 // ProjectSync : Does a project Sync
 func ProjectSync(c *cli.Context) {
+	if c.Bool("dry-run") {
+		ProjectSyncDryRun(c)
+	}
+	if c.Bool("watch") {
+		ProjectSyncWatch(c)
+	}
 	response, err := project.SyncProject(c)
 	if err != nil {
 		HandleProjectError(err)
@@ -95,8 +107,96 @@ func ProjectSync(c *cli.Context) {
 	os.Exit(0)
 }
 
+// ProjectSyncDryRun : Prints the changes a project sync would make, without making them
+func ProjectSyncDryRun(c *cli.Context) {
+	plan, err := project.PlanSync(c)
+	if err != nil && plan == nil {
+		HandleProjectError(err)
+		os.Exit(1)
+	}
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(plan)
+		fmt.Println(string(jsonResponse))
+	} else {
+		for _, file := range plan.Added {
+			fmt.Println("A " + file)
+		}
+		for _, file := range plan.Modified {
+			fmt.Println("M " + file)
+		}
+		for _, file := range plan.Deleted {
+			fmt.Println("D " + file)
+		}
+		for _, directory := range plan.DeletedDirectories {
+			fmt.Println("D " + directory + "/")
+		}
+	}
+	if err != nil {
+		HandleProjectError(err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// ProjectSyncWatch : Syncs a project each time its files change, printing the result of every sync
+func ProjectSyncWatch(c *cli.Context) {
+	err := project.WatchProject(c, func(response *project.SyncResponse, err *project.ProjectError) {
+		if err != nil {
+			HandleProjectError(err)
+		} else if printAsJSON {
+			jsonResponse, _ := json.Marshal(response)
+			fmt.Println(string(jsonResponse))
+		} else {
+			fmt.Println("Status: " + response.Status)
+		}
+	})
+	if err != nil {
+		HandleProjectError(err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// ProjectIgnored : Lists the paths in a project that sync ignores
+func ProjectIgnored(c *cli.Context) {
+	projectPath := strings.TrimSpace(c.String("path"))
+	ignored, err := project.ListIgnoredPaths(projectPath)
+	if err != nil {
+		HandleProjectError(err)
+		os.Exit(1)
+	}
+
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(ignored)
+		fmt.Println(string(jsonResponse))
+	} else if len(ignored) == 0 {
+		fmt.Println("No files are ignored")
+	} else {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "PATH \tPATTERN \tSOURCE")
+		for _, path := range ignored {
+			name := path.Path
+			if path.IsDirectory {
+				name += "/"
+			}
+			source := path.Source
+			if path.Line > 0 {
+				source = fmt.Sprintf("%s:%d", path.Source, path.Line)
+			}
+			fmt.Fprintln(w, name+"\t"+path.Pattern+"\t"+source)
+		}
+		fmt.Fprintln(w)
+		w.Flush()
+	}
+	os.Exit(0)
+}
+
 // ProjectBind : Does a project bind
 func ProjectBind(c *cli.Context) {
+	if scanDir := strings.TrimSpace(c.String("scan")); scanDir != "" {
+		ProjectBindScan(c, scanDir)
+	}
 	response, err := project.BindProject(c)
 	if err != nil {
 		HandleProjectError(err)
@@ -110,9 +210,129 @@ func ProjectBind(c *cli.Context) {
 			fmt.Println("Status: " + response.Status)
 		}
 	}
+
+	if c.Bool("wait") {
+		conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+		conInfo, conInfoErr := connections.GetConnectionByID(conID)
+		if conInfoErr != nil {
+			HandleConnectionError(conInfoErr)
+			os.Exit(1)
+		}
+		conURL, conErr := config.PFEOriginFromConnection(conInfo)
+		if conErr != nil {
+			HandleConfigError(conErr)
+			os.Exit(1)
+		}
+		_, projErr := project.WaitForProject(http.DefaultClient, conInfo, conURL, response.ProjectID, c.Duration("timeout"), nil, printProjectStatus)
+		if projErr != nil {
+			HandleProjectError(projErr)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+// printProjectStatus : Prints the build and application status of a project when either changes
+func printProjectStatus(p project.Project) {
+	if printAsJSON {
+		status, _ := json.Marshal(struct {
+			ProjectID   string `json:"projectID"`
+			Name        string `json:"name"`
+			BuildStatus string `json:"buildStatus"`
+			AppStatus   string `json:"appStatus"`
+		}{p.ProjectID, p.Name, p.BuildStatus, p.AppStatus})
+		fmt.Println(string(status))
+		return
+	}
+	buildStatus, appStatus := p.BuildStatus, p.AppStatus
+	if buildStatus == "" {
+		buildStatus = "unknown"
+	}
+	if appStatus == "" {
+		appStatus = "unknown"
+	}
+	fmt.Printf("%s %s: build %s, application %s\n", time.Now().Format("15:04:05"), p.Name, buildStatus, appStatus)
+}
+
+// ProjectBindScan : Finds the projects in a directory and binds those the user accepts
+func ProjectBindScan(c *cli.Context, scanDir string) {
+	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	candidates, err := project.ScanProjects(scanDir)
+	if err != nil {
+		HandleProjectError(err)
+		os.Exit(1)
+	}
+
+	if !c.Bool("yes") {
+		if printAsJSON {
+			// without --yes, JSON output lists the proposed projects so that they can be bound individually
+			jsonResponse, _ := json.Marshal(candidates)
+			fmt.Println(string(jsonResponse))
+			os.Exit(0)
+		}
+		if len(candidates) == 0 {
+			fmt.Println("No projects found in " + scanDir)
+			os.Exit(0)
+		}
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "# \tNAME \tLANGUAGE \tTYPE \tRULE \tPATH")
+		for i, candidate := range candidates {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s (%.0f%%)\t%s\n", i+1, candidate.Name, candidate.Language, candidate.BuildType, candidate.Rule, candidate.Confidence*100, candidate.Path)
+		}
+		fmt.Fprintln(w)
+		w.Flush()
+
+		fmt.Print("Projects to bind, as numbers or ranges such as 1,3-5, 'all' or 'none' [all]: ")
+		input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		selected, selectErr := selectScanCandidates(candidates, input)
+		if selectErr != nil {
+			fmt.Println(selectErr.Error())
+			os.Exit(1)
+		}
+		candidates = selected
+	}
+
+	response := project.BindProjects(candidates, conID)
+	utils.PrettyPrintJSON(response)
 	os.Exit(0)
 }
 
+// selectScanCandidates : Pick the candidates chosen by the user, by their numbers in the proposed list
+func selectScanCandidates(candidates []project.ScanCandidate, input string) ([]project.ScanCandidate, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	switch input {
+	case "", "all":
+		return candidates, nil
+	case "none":
+		return []project.ScanCandidate{}, nil
+	}
+
+	chosen := make([]bool, len(candidates))
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' }) {
+		first, last := field, field
+		if dash := strings.Index(field, "-"); dash > 0 {
+			first, last = field[:dash], field[dash+1:]
+		}
+		from, fromErr := strconv.Atoi(first)
+		to, toErr := strconv.Atoi(last)
+		if fromErr != nil || toErr != nil || from < 1 || to > len(candidates) || from > to {
+			return nil, fmt.Errorf("'%s' is not a project number between 1 and %d", field, len(candidates))
+		}
+		for i := from; i <= to; i++ {
+			chosen[i-1] = true
+		}
+	}
+
+	selected := []project.ScanCandidate{}
+	for i, candidate := range candidates {
+		if chosen[i] {
+			selected = append(selected, candidate)
+		}
+	}
+	return selected, nil
+}
+
 // ProjectRemove : Does a project remove
 func ProjectRemove(c *cli.Context) {
 	err := project.RemoveProject(c)
@@ -178,6 +398,77 @@ func ProjectList(c *cli.Context) {
 	os.Exit(0)
 }
 
+// ProjectStatus : Prints the status of the projects on every connection, refreshing it in place when watching
+func ProjectStatus(c *cli.Context) {
+	filter := project.StatusFilter{
+		Status:     strings.TrimSpace(c.String("status")),
+		Language:   strings.TrimSpace(c.String("language")),
+		Connection: strings.TrimSpace(c.String("conid")),
+	}
+	sortBy := strings.TrimSpace(strings.ToLower(c.String("sort")))
+	watch := c.Bool("watch")
+
+	client := &http.Client{Timeout: project.StatusRequestTimeout}
+	for {
+		report, projErr := project.GetStatusReport(client, filter.Connection)
+		if projErr != nil {
+			HandleProjectError(projErr)
+			os.Exit(1)
+		}
+		report.Filter(filter)
+		if projErr = report.Sort(sortBy); projErr != nil {
+			HandleProjectError(projErr)
+			os.Exit(1)
+		}
+
+		if printAsJSON {
+			response, _ := json.Marshal(report)
+			fmt.Println(string(response))
+		} else {
+			if watch {
+				// move to the top left and clear the screen, so the table refreshes in place
+				fmt.Print("\033[H\033[2J")
+				fmt.Printf("Every %s: %s\n\n", c.Duration("interval"), time.Now().Format("15:04:05"))
+			}
+			PrintTable(statusTableRows(report))
+			for _, conErr := range report.Errors {
+				fmt.Printf("Unable to get the projects on %s: %s\n", conErr.ConnectionLabel, conErr.Error)
+			}
+		}
+
+		if !watch {
+			os.Exit(0)
+		}
+		time.Sleep(c.Duration("interval"))
+	}
+}
+
+// statusTableRows : Lay out a status report as the rows of a table
+func statusTableRows(report *project.StatusReport) []string {
+	rows := []string{"NAME\tCONNECTION\tLANGUAGE\tBUILD STATUS\tAPP STATUS\tSTART MODE\tPORTS\tLAST SYNC"}
+	for _, p := range report.Projects {
+		ports := "-"
+		if p.Ports.ExposedPort != "" {
+			ports = p.Ports.ExposedPort + "->" + p.Ports.InternalPort
+			if p.Ports.ExposedDebugPort != "" {
+				ports += ", " + p.Ports.ExposedDebugPort + "->" + p.Ports.InternalDebugPort + " (debug)"
+			}
+		}
+		lastSync := "-"
+		if p.LastSync > 0 {
+			lastSync = time.Unix(0, p.LastSync*int64(time.Millisecond)).Format("2006-01-02 15:04:05")
+		}
+		columns := []string{p.Name, p.ConnectionLabel, p.Language, p.BuildStatus, p.AppStatus, p.StartMode, ports, lastSync}
+		for i, column := range columns {
+			if column == "" {
+				columns[i] = "-"
+			}
+		}
+		rows = append(rows, strings.Join(columns, "\t"))
+	}
+	return rows
+}
+
 // ProjectGet : Prints information about a given project using its ID
 func ProjectGet(c *cli.Context) {
 	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
@@ -256,17 +547,327 @@ func ProjectRestart(c *cli.Context) {
 		os.Exit(1)
 	}
 
+	var waitTimeout time.Duration
+	if c.Bool("wait") {
+		waitTimeout = c.Duration("timeout")
+	}
+
+	if c.Bool("with-dependents") {
+		results, projErr := project.RestartWithDependents(http.DefaultClient, conInfo, conURL, projectID, startMode, waitTimeout, printProjectStatus)
+		if projErr != nil {
+			HandleProjectError(projErr)
+			os.Exit(1)
+		}
+		if printAsJSON {
+			response, _ := json.Marshal(results)
+			fmt.Println(string(response))
+		} else {
+			PrintTable(restartTableRows(results))
+		}
+		for _, result := range results {
+			if result.Status != project.RestartStatusRequested && result.Status != project.RestartStatusStarted {
+				os.Exit(1)
+			}
+		}
+		os.Exit(0)
+	}
+
+	// the project is read before restarting it, so that waiting can tell when the restart has happened
+	var before *project.Project
+	if waitTimeout > 0 {
+		var projErr *project.ProjectError
+		before, projErr = project.GetProjectFromID(http.DefaultClient, conInfo, conURL, projectID)
+		if projErr != nil {
+			HandleProjectError(projErr)
+			os.Exit(1)
+		}
+	}
+
 	err := project.RestartProject(http.DefaultClient, conInfo, conURL, projectID, startMode)
 	if err != nil {
 		fmt.Println(err.Error())
 		os.Exit(1)
 	}
 
+	if waitTimeout > 0 {
+		_, projErr := project.WaitForProject(http.DefaultClient, conInfo, conURL, projectID, waitTimeout, before, printProjectStatus)
+		if projErr != nil {
+			HandleProjectError(projErr)
+			os.Exit(1)
+		}
+		response, _ := json.Marshal(project.Result{Status: "OK", StatusMessage: "Project restarted"})
+		fmt.Println(string(response))
+		os.Exit(0)
+	}
+
 	response, _ := json.Marshal(project.Result{Status: "OK", StatusMessage: "Project restart request accepted"})
 	fmt.Println(string(response))
 	os.Exit(0)
 }
 
+// restartTableRows : Lay out the outcome of restarting a project and its dependents as the rows of a table
+func restartTableRows(results []project.RestartResult) []string {
+	rows := []string{"NAME\tPROJECT ID\tSTART MODE\tSTATUS"}
+	for _, result := range results {
+		status := result.Status
+		if result.Error != "" {
+			status += ": " + result.Error
+		}
+		columns := []string{result.Name, result.ProjectID, result.StartMode, status}
+		for i, column := range columns {
+			if column == "" {
+				columns[i] = "-"
+			}
+		}
+		rows = append(rows, strings.Join(columns, "\t"))
+	}
+	return rows
+}
+
+// ProjectLogs : prints the build or application logs of a project, optionally following them as they grow
+func ProjectLogs(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	options := project.LogOptions{
+		Type:       strings.TrimSpace(strings.ToLower(c.String("type"))),
+		Follow:     c.Bool("follow"),
+		Timestamps: c.Bool("timestamps"),
+		Tail:       c.Int("tail"),
+	}
+
+	conID, getConnectionIDErr := project.GetConnectionID(projectID)
+	if getConnectionIDErr != nil {
+		HandleProjectError(getConnectionIDErr)
+		os.Exit(1)
+	}
+
+	conInfo, conInfoErr := connections.GetConnectionByID(conID)
+	if conInfoErr != nil {
+		HandleConnectionError(conInfoErr)
+		os.Exit(1)
+	}
+
+	conURL, conErr := config.PFEOriginFromConnection(conInfo)
+	if conErr != nil {
+		HandleConfigError(conErr)
+		os.Exit(1)
+	}
+
+	projErr := project.StreamProjectLogs(http.DefaultClient, conInfo, conURL, projectID, options, os.Stdout)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// ProjectMove : Moves a project to another connection
+func ProjectMove(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	toConID := strings.TrimSpace(strings.ToLower(c.String("to")))
+
+	result, projErr := project.MoveProject(projectID, toConID)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	if printAsJSON {
+		response, _ := json.Marshal(result)
+		fmt.Println(string(response))
+	} else {
+		fmt.Printf("Moved project from %s to %s, its new ID is %s\n", result.From, result.To, result.ProjectID)
+		for _, link := range result.Links {
+			if link.Status == project.LinkRecreated {
+				fmt.Printf("Recreated link %s to %s\n", link.EnvName, link.TargetName)
+			} else {
+				fmt.Printf("Skipped link %s, as %s is not on %s\n", link.EnvName, link.TargetName, result.To)
+			}
+		}
+	}
+	os.Exit(0)
+}
+
+// ProjectExport : Exports a project's settings, connection and links, to a file or stdout
+func ProjectExport(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	filename := strings.TrimSpace(c.String("file"))
+
+	export, projErr := project.ExportProject(projectID)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	content, _ := json.MarshalIndent(export, "", "  ")
+	if filename == "" {
+		fmt.Println(string(content))
+		os.Exit(0)
+	}
+	err := ioutil.WriteFile(filename, append(content, '\n'), 0644)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	if printAsJSON {
+		response, _ := json.Marshal(project.Result{Status: "OK", StatusMessage: "Project exported to " + filename})
+		fmt.Println(string(response))
+	} else {
+		fmt.Println("Project exported to " + filename)
+	}
+	os.Exit(0)
+}
+
+// ProjectImport : Binds a checkout of an exported project
+func ProjectImport(c *cli.Context) {
+	filename := strings.TrimSpace(c.String("file"))
+	projectPath := strings.TrimSpace(c.String("path"))
+	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	name := strings.TrimSpace(c.String("name"))
+
+	export, projErr := project.LoadProjectExport(filename)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+	result, projErr := project.ImportProject(export, projectPath, conID, name)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	if printAsJSON {
+		response, _ := json.Marshal(result)
+		fmt.Println(string(response))
+	} else {
+		fmt.Printf("Imported project %s to %s, its ID is %s\n", result.Name, result.Connection, result.ProjectID)
+		for _, file := range result.FilesWritten {
+			fmt.Println("Wrote " + file)
+		}
+		for _, link := range result.Links {
+			if link.Status == project.LinkRecreated {
+				fmt.Printf("Recreated link %s to %s\n", link.EnvName, link.TargetName)
+			} else {
+				fmt.Printf("Skipped link %s, as %s is not on %s\n", link.EnvName, link.TargetName, result.Connection)
+			}
+		}
+		for _, warning := range result.Warnings {
+			fmt.Println("Warning: " + warning)
+		}
+	}
+	os.Exit(0)
+}
+
+// ProjectSettingsGet : Prints a project's .cw-settings, or the value of one of its keys
+func ProjectSettingsGet(c *cli.Context) {
+	projectPath := strings.TrimSpace(c.String("path"))
+	key := strings.TrimSpace(c.String("key"))
+
+	value, projErr := project.GetProjectSetting(projectPath, key)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+	fmt.Println(strings.TrimSpace(string(value)))
+	os.Exit(0)
+}
+
+// ProjectSettingsSet : Sets a key of a project's .cw-settings, leaving the rest of the file as it was
+func ProjectSettingsSet(c *cli.Context) {
+	projectPath := strings.TrimSpace(c.String("path"))
+	key := strings.TrimSpace(c.String("key"))
+	value := c.String("value")
+
+	projErr := project.SetProjectSetting(projectPath, key, value)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+	if printAsJSON {
+		response, _ := json.Marshal(project.Result{Status: "OK", StatusMessage: "Set " + key})
+		fmt.Println(string(response))
+	} else {
+		fmt.Println("Set " + key)
+	}
+	os.Exit(0)
+}
+
+// ProjectSettingsValidate : Validates a project's .cw-settings against the schema, exiting with an error if it is invalid
+func ProjectSettingsValidate(c *cli.Context) {
+	projectPath := strings.TrimSpace(c.String("path"))
+	buildType := strings.TrimSpace(c.String("type"))
+
+	validation, projErr := project.ValidateProjectSettings(projectPath, buildType)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	if printAsJSON {
+		response, _ := json.Marshal(validation)
+		fmt.Println(string(response))
+	} else {
+		for _, issue := range validation.Issues {
+			if issue.Key == "" {
+				fmt.Printf("%s: %s\n", issue.Severity, issue.Message)
+			} else {
+				fmt.Printf("%s: %s: %s\n", issue.Severity, issue.Key, issue.Message)
+			}
+		}
+		if validation.Valid {
+			fmt.Println(".cw-settings is valid")
+		} else {
+			fmt.Println(".cw-settings is invalid")
+		}
+	}
+	if !validation.Valid {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// ProjectSettingsSchema : Prints the JSON schema of .cw-settings
+func ProjectSettingsSchema(c *cli.Context) {
+	schema, _ := json.MarshalIndent(project.SettingsSchema(), "", "  ")
+	fmt.Println(string(schema))
+	os.Exit(0)
+}
+
+// ProjectLinkGraph : prints the graph of the links between the projects on a connection
+func ProjectLinkGraph(c *cli.Context) {
+	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	format := strings.TrimSpace(strings.ToLower(c.String("format")))
+	if format != "json" && format != "dot" {
+		fmt.Println("Error: format must be json or dot")
+		os.Exit(1)
+	}
+
+	conInfo, conInfoErr := connections.GetConnectionByID(conID)
+	if conInfoErr != nil {
+		HandleConnectionError(conInfoErr)
+		os.Exit(1)
+	}
+
+	conURL, conErr := config.PFEOriginFromConnection(conInfo)
+	if conErr != nil {
+		HandleConfigError(conErr)
+		os.Exit(1)
+	}
+
+	graph, projErr := project.GetLinkGraph(http.DefaultClient, conInfo, conURL)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		os.Exit(1)
+	}
+
+	if format == "dot" {
+		fmt.Print(graph.DOT())
+	} else {
+		response, _ := json.Marshal(graph)
+		fmt.Println(string(response))
+	}
+	os.Exit(0)
+}
+
 // ProjectLinkList : lists all the links for a project
 func ProjectLinkList(c *cli.Context) {
 	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
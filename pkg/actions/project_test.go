@@ -0,0 +1,85 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectScanCandidates(t *testing.T) {
+	candidates := []project.ScanCandidate{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+	tests := map[string]struct {
+		input     string
+		wantNames []string
+		wantErr   bool
+	}{
+		"empty input selects all": {input: "\n", wantNames: []string{"a", "b", "c", "d"}},
+		"all":                     {input: "ALL", wantNames: []string{"a", "b", "c", "d"}},
+		"none":                    {input: "none", wantNames: []string{}},
+		"numbers and ranges":      {input: "4, 1-2", wantNames: []string{"a", "b", "d"}},
+		"out of range number":     {input: "5", wantErr: true},
+		"backwards range":         {input: "3-2", wantErr: true},
+		"not a number":            {input: "b", wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := selectScanCandidates(candidates, test.input)
+			if test.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			names := []string{}
+			for _, candidate := range got {
+				names = append(names, candidate.Name)
+			}
+			assert.Equal(t, test.wantNames, names)
+		})
+	}
+}
+
+func TestStatusTableRows(t *testing.T) {
+	report := &project.StatusReport{Projects: []project.ProjectStatus{
+		{
+			Project:         project.Project{Name: "api", Language: "java", BuildStatus: "success", AppStatus: "started", StartMode: "debug", Ports: project.Ports{ExposedPort: "32768", InternalPort: "9080", ExposedDebugPort: "32769", InternalDebugPort: "7777"}},
+			ConnectionLabel: "Local",
+		},
+		{
+			Project:         project.Project{Name: "web", Language: "nodejs", BuildStatus: "failed", AppStatus: "stopped"},
+			ConnectionLabel: "Remote",
+		},
+	}}
+	rows := statusTableRows(report)
+	assert.Equal(t, []string{
+		"NAME\tCONNECTION\tLANGUAGE\tBUILD STATUS\tAPP STATUS\tSTART MODE\tPORTS\tLAST SYNC",
+		"api\tLocal\tjava\tsuccess\tstarted\tdebug\t32768->9080, 32769->7777 (debug)\t-",
+		"web\tRemote\tnodejs\tfailed\tstopped\t-\t-\t-",
+	}, rows)
+}
+
+func TestRestartTableRows(t *testing.T) {
+	results := []project.RestartResult{
+		{ProjectID: "dbID", Name: "db", StartMode: "debug", Status: project.RestartStatusStarted},
+		{ProjectID: "apiID", Name: "api", StartMode: "run", Status: project.RestartStatusFailed, Error: "application stopped"},
+		{ProjectID: "webID", Name: "web", Status: project.RestartStatusSkipped},
+	}
+	rows := restartTableRows(results)
+	assert.Equal(t, []string{
+		"NAME\tPROJECT ID\tSTART MODE\tSTATUS",
+		"db\tdbID\tdebug\tstarted",
+		"api\tapiID\trun\tfailed: application stopped",
+		"web\twebID\t-\tskipped",
+	}, rows)
+}
@@ -47,6 +47,18 @@ func SecurityTokenRefresh(c *cli.Context) {
 	os.Exit(0)
 }
 
+// SecurityTokenLogin : Log in without a password and save the access and refresh tokens in the keyring
+func SecurityTokenLogin(c *cli.Context) {
+	authTokens, secErr := security.SecLogin(http.DefaultClient, c)
+	if secErr == nil && authTokens != nil {
+		utils.PrettyPrintJSON(authTokens)
+	} else {
+		fmt.Println(secErr.Error())
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
 // SecurityCreateRealm : Create a realm in Keycloak
 func SecurityCreateRealm(c *cli.Context) {
 	err := security.SecRealmCreate(c)
@@ -191,3 +203,29 @@ func SecurityKeyValidate(c *cli.Context) {
 	fmt.Println(string(response))
 	os.Exit(0)
 }
+
+// SecurityKeyList : Lists the secrets in the keyring, without their values
+func SecurityKeyList(c *cli.Context) {
+	entries, err := security.SecKeyList()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	response, _ := json.Marshal(entries)
+	fmt.Println(string(response))
+	os.Exit(0)
+}
+
+// SecurityKeyDelete : Deletes a secret, or all the secrets of a connection, from the keyring
+func SecurityKeyDelete(c *cli.Context) {
+	connectionID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	username := strings.TrimSpace(strings.ToLower(c.String("username")))
+	deleted, err := security.SecKeyDelete(connectionID, username)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	response, _ := json.Marshal(deleted)
+	fmt.Println(string(response))
+	os.Exit(0)
+}
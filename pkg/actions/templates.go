@@ -27,19 +27,31 @@ func ListTemplates(c *cli.Context) {
 	projectStyle := c.String("projectStyle")
 	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
 	showEnabledOnly := c.Bool("showEnabledOnly")
-	templates, err := apiroutes.GetTemplates(conID, projectStyle, showEnabledOnly)
+	templateList, err := templates.GetTemplates(conID, projectStyle, showEnabledOnly)
 	if err != nil {
 		templateErr := &TemplateError{errOpListTemplates, err, err.Error()}
 		HandleTemplateError(templateErr)
 		return
 	}
-	if len(templates) > 0 {
-		utils.PrettyPrintJSON(templates)
+	if len(templateList) > 0 {
+		utils.PrettyPrintJSON(templateList)
 	} else {
-		fmt.Println(templates)
+		fmt.Println(templateList)
 	}
 }
 
+// PullTemplates downloads the enabled templates of which Codewind is aware to the local cache.
+func PullTemplates(c *cli.Context) {
+	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	pulled, err := templates.PullTemplates(conID)
+	if err != nil {
+		templateErr := &TemplateError{errOpPullTemplates, err, err.Error()}
+		HandleTemplateError(templateErr)
+		return
+	}
+	utils.PrettyPrintJSON(pulled)
+}
+
 // ListTemplateStyles lists all template styles of which Codewind is aware.
 func ListTemplateStyles(c *cli.Context) {
 	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
@@ -55,7 +67,7 @@ func ListTemplateStyles(c *cli.Context) {
 // ListTemplateRepos lists all template repos of which Codewind is aware.
 func ListTemplateRepos(c *cli.Context) {
 	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
-	repos, err := apiroutes.GetTemplateRepos(conID)
+	repos, err := templates.GetTemplateRepos(conID)
 	if err != nil {
 		templateErr := &TemplateError{errOpListRepos, err, err.Error()}
 		HandleTemplateError(templateErr)
@@ -117,7 +129,7 @@ func DeleteTemplateRepo(c *cli.Context) {
 // EnableTemplateRepos enables templates repo of which Codewind is aware.
 func EnableTemplateRepos(c *cli.Context) {
 	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
-	repos, err := apiroutes.EnableTemplateRepos(conID, c.Args())
+	repos, err := templates.EnableTemplateRepos(conID, c.Args())
 	if err != nil {
 		templateErr := &TemplateError{errOpEnableRepo, err, err.Error()}
 		HandleTemplateError(templateErr)
@@ -129,7 +141,7 @@ func EnableTemplateRepos(c *cli.Context) {
 // DisableTemplateRepos disables templates repo of which Codewind is aware.
 func DisableTemplateRepos(c *cli.Context) {
 	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
-	repos, err := apiroutes.DisableTemplateRepos(conID, c.Args())
+	repos, err := templates.DisableTemplateRepos(conID, c.Args())
 	if err != nil {
 		templateErr := &TemplateError{errOpDisableRepo, err, err.Error()}
 		HandleTemplateError(templateErr)
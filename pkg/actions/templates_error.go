@@ -23,6 +23,7 @@ type TemplateError struct {
 const (
 	errOpListTemplates           = "LIST_TEMPLATES_ERROR"
 	errOpListStyles              = "LIST_STYLES_ERROR"
+	errOpPullTemplates           = "PULL_TEMPLATES_ERROR"
 	errOpListRepos               = "LIST_REPOS_ERROR"
 	errOpAddRepo                 = "ADD_REPO_ERROR"
 	errOpDeleteRepo              = "DELETE_REPO_ERROR"
@@ -21,6 +21,7 @@ import (
 	"github.com/eclipse/codewind-installer/pkg/docker"
 	"github.com/eclipse/codewind-installer/pkg/project"
 	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/eclipse/codewind-installer/pkg/workspace"
 	logr "github.com/sirupsen/logrus"
 )
 
@@ -90,6 +91,15 @@ func HandleRegistryError(err *RegistryError) {
 	}
 }
 
+// HandleWorkspaceError prints a Workspace error, in JSON format if the global flag is set and as a string if not
+func HandleWorkspaceError(err *workspace.WorkspaceError) {
+	if printAsJSON {
+		fmt.Println(err.Error())
+	} else {
+		logr.Error(err.Desc)
+	}
+}
+
 // PrintTable prints a formatted table into the terminal
 func PrintTable(content []string) {
 	w := new(tabwriter.Writer)
@@ -0,0 +1,84 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/workspace"
+	"github.com/urfave/cli"
+)
+
+// workspaceActionSymbols are shown before each change in the text output
+var workspaceActionSymbols = map[string]string{
+	workspace.ActionNone:     "=",
+	workspace.ActionAdd:      "+",
+	workspace.ActionReplace:  "~",
+	workspace.ActionConflict: "!",
+}
+
+// WorkspaceDiff : Prints the changes applying a workspace manifest would make
+func WorkspaceDiff(c *cli.Context) {
+	manifest, wsErr := workspace.LoadManifest(strings.TrimSpace(c.String("file")))
+	if wsErr != nil {
+		HandleWorkspaceError(wsErr)
+		os.Exit(1)
+	}
+	changes, wsErr := workspace.Diff(manifest)
+	if wsErr != nil {
+		HandleWorkspaceError(wsErr)
+		os.Exit(1)
+	}
+	printWorkspaceChanges(changes)
+	os.Exit(0)
+}
+
+// WorkspaceApply : Applies a workspace manifest, exiting with an error if any change failed
+func WorkspaceApply(c *cli.Context) {
+	manifest, wsErr := workspace.LoadManifest(strings.TrimSpace(c.String("file")))
+	if wsErr != nil {
+		HandleWorkspaceError(wsErr)
+		os.Exit(1)
+	}
+	changes, wsErr := workspace.Apply(manifest)
+	if wsErr != nil {
+		HandleWorkspaceError(wsErr)
+		os.Exit(1)
+	}
+	printWorkspaceChanges(changes)
+	for _, change := range changes {
+		if change.Error != "" {
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+func printWorkspaceChanges(changes []workspace.Change) {
+	if printAsJSON {
+		jsonResponse, _ := json.Marshal(changes)
+		fmt.Println(string(jsonResponse))
+		return
+	}
+	lines := []string{"\tRESOURCE\tCONNECTION\tNAME\tDETAIL"}
+	for _, change := range changes {
+		detail := change.Detail
+		if change.Error != "" {
+			detail = "failed: " + change.Error
+		}
+		lines = append(lines, strings.Join([]string{workspaceActionSymbols[change.Action], change.Resource, change.Connection, change.Name, detail}, "\t"))
+	}
+	PrintTable(lines)
+}
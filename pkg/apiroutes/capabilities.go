@@ -0,0 +1,92 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package apiroutes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// CapabilityUploadArchive : PFE accepts a project's changed files as a single tar.gz upload
+const CapabilityUploadArchive = "upload_archive"
+
+// CapabilityUploadSymlink : PFE recreates a file uploaded with a symlink target as a symlink
+const CapabilityUploadSymlink = "upload_symlink"
+
+var (
+	// pfeCapabilities : The capabilities advertised by each PFE, by connection and URL. They are only
+	// fetched once per process, as they don't change while PFE is running
+	pfeCapabilities      = map[string][]string{}
+	pfeCapabilitiesMutex sync.Mutex
+)
+
+// PFESupports : Check whether PFE advertises a capability in its environment API.
+// Older versions of PFE do not advertise any capabilities, so errors are treated as unsupported
+func PFESupports(httpClient utils.HTTPClient, connection *connections.Connection, conURL string, capability string) bool {
+	pfeCapabilitiesMutex.Lock()
+	defer pfeCapabilitiesMutex.Unlock()
+
+	key := connection.ID + " " + conURL
+	capabilities, ok := pfeCapabilities[key]
+	if !ok {
+		var err error
+		capabilities, err = getPFECapabilities(httpClient, connection, conURL)
+		if err != nil {
+			// not cached, as PFE may just not have been reachable
+			return false
+		}
+		pfeCapabilities[key] = capabilities
+	}
+
+	for _, supported := range capabilities {
+		if supported == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// getPFECapabilities : Get the capabilities PFE advertises in its environment API
+func getPFECapabilities(httpClient utils.HTTPClient, connection *connections.Connection, conURL string) ([]string, error) {
+	req, err := http.NewRequest("GET", conURL+"/api/v1/environment", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, connection)
+	if httpSecError != nil {
+		return nil, httpSecError
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("PFE responded with status code %d", resp.StatusCode)
+	}
+
+	byteArray, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var env EnvResponse
+	err = json.Unmarshal(byteArray, &env)
+	if err != nil {
+		return nil, err
+	}
+	return env.Capabilities, nil
+}
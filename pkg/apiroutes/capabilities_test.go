@@ -0,0 +1,63 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package apiroutes
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PFESupports(t *testing.T) {
+	mockConnection := connections.Connection{ID: "local"}
+	tests := map[string]struct {
+		statusCode int
+		env        EnvResponse
+		want       bool
+	}{
+		"returns true when PFE advertises the capability": {
+			statusCode: http.StatusOK,
+			env:        EnvResponse{Version: "x.x.dev", Capabilities: []string{CapabilityUploadArchive}},
+			want:       true,
+		},
+		"returns false when PFE does not advertise any capabilities": {
+			statusCode: http.StatusOK,
+			env:        EnvResponse{Version: "x.x.dev"},
+			want:       false,
+		},
+		"returns false when the environment API fails": {
+			statusCode: http.StatusNotFound,
+			env:        EnvResponse{Capabilities: []string{CapabilityUploadArchive}},
+			want:       false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &MockResponse{StatusCode: test.statusCode, Body: CreateMockResponseBody(test.env)}
+			got := PFESupports(mockClient, &mockConnection, "http://"+strings.Replace(name, " ", "-", -1), CapabilityUploadArchive)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	t.Run("caches the capabilities of each PFE", func(t *testing.T) {
+		env := EnvResponse{Version: "x.x.dev", Capabilities: []string{CapabilityUploadArchive}}
+		mockClient := &MockResponse{StatusCode: http.StatusOK, Body: CreateMockResponseBody(env)}
+		assert.True(t, PFESupports(mockClient, &mockConnection, "http://cached", CapabilityUploadArchive))
+
+		failingClient := &MockResponse{StatusCode: http.StatusNotFound, Body: CreateMockResponseBody(EnvResponse{})}
+		assert.True(t, PFESupports(failingClient, &mockConnection, "http://cached", CapabilityUploadArchive))
+		assert.False(t, PFESupports(failingClient, &mockConnection, "http://other", CapabilityUploadArchive))
+	})
+}
@@ -41,8 +41,9 @@ type (
 
 	// EnvResponse : The relevant response fields from the remote environment API
 	EnvResponse struct {
-		Version        string `json:"codewind_version"`
-		ImageBuildTime string `json:"image_build_time"`
+		Version        string   `json:"codewind_version"`
+		ImageBuildTime string   `json:"image_build_time"`
+		Capabilities   []string `json:"capabilities,omitempty"`
 	}
 )
 
@@ -45,6 +45,8 @@ type Connection struct {
 	Realm    string `json:"realm"`
 	ClientID string `json:"clientid"`
 	Username string `json:"username"`
+	// ServiceAccount is set when the connection authenticates as a confidential client rather than a user
+	ServiceAccount *ServiceAccount `json:"serviceaccount,omitempty"`
 }
 
 const actionUpdateEntry = 0x01
@@ -158,6 +160,7 @@ func updateConnectionList(action int, httpClient utils.HTTPClient, connectionID
 	}
 
 	// check the connection already exists
+	var serviceAccount *ServiceAccount
 	if action == actionUpdateEntry {
 		connInfo, conErr := GetConnectionByID(connectionID)
 		if conErr != nil {
@@ -167,6 +170,7 @@ func updateConnectionList(action int, httpClient utils.HTTPClient, connectionID
 			err := errors.New("Unable to update connection")
 			return nil, &ConError{errOpNotFound, err, err.Error()}
 		}
+		serviceAccount = connInfo.ServiceAccount
 	}
 
 	gatekeeperEnv, err := gatekeeper.GetGatekeeperEnvironment(httpClient, url)
@@ -176,13 +180,14 @@ func updateConnectionList(action int, httpClient utils.HTTPClient, connectionID
 
 	// create the new connection
 	newConnection := Connection{
-		ID:       connectionID,
-		Label:    label,
-		URL:      url,
-		AuthURL:  gatekeeperEnv.AuthURL,
-		Realm:    gatekeeperEnv.Realm,
-		ClientID: gatekeeperEnv.ClientID,
-		Username: username,
+		ID:             connectionID,
+		Label:          label,
+		URL:            url,
+		AuthURL:        gatekeeperEnv.AuthURL,
+		Realm:          gatekeeperEnv.Realm,
+		ClientID:       gatekeeperEnv.ClientID,
+		Username:       username,
+		ServiceAccount: serviceAccount,
 	}
 
 	switch action {
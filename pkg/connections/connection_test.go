@@ -195,3 +195,38 @@ func Test_RemoveConnectionFromList(t *testing.T) {
 		assert.Len(t, result.Connections, 1)
 	})
 }
+
+func Test_SetServiceAccount(t *testing.T) {
+	tests := map[string]struct {
+		conID     string
+		account   *ServiceAccount
+		wantErrOp string
+	}{
+		"fail case - the local connection": {
+			conID:     "local",
+			account:   &ServiceAccount{ClientID: "pipeline", SecretEnv: "CW_CLIENT_SECRET"},
+			wantErrOp: errOpProtected,
+		},
+		"fail case - no client ID": {
+			conID:     "remote",
+			account:   &ServiceAccount{SecretEnv: "CW_CLIENT_SECRET"},
+			wantErrOp: errOpInvalidOptions,
+		},
+		"fail case - no secret": {
+			conID:     "remote",
+			account:   &ServiceAccount{ClientID: "pipeline"},
+			wantErrOp: errOpInvalidOptions,
+		},
+		"fail case - both an environment variable and a file": {
+			conID:     "remote",
+			account:   &ServiceAccount{ClientID: "pipeline", SecretEnv: "CW_CLIENT_SECRET", SecretFile: "secret"},
+			wantErrOp: errOpInvalidOptions,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, conErr := SetServiceAccount(test.conID, test.account)
+			assert.Equal(t, test.wantErrOp, conErr.Op)
+		})
+	}
+}
@@ -23,14 +23,15 @@ type ConError struct {
 }
 
 const (
-	errOpFileParse    = "con_parse"
-	errOpFileLoad     = "con_load"
-	errOpFileWrite    = "con_write"
-	errOpSchemaUpdate = "con_schema_update"
-	errOpConflict     = "con_conflict"
-	errOpNotFound     = "con_not_found"
-	errOpProtected    = "con_protected"
-	errOpGetEnv       = "con_environment"
+	errOpFileParse      = "con_parse"
+	errOpFileLoad       = "con_load"
+	errOpFileWrite      = "con_write"
+	errOpSchemaUpdate   = "con_schema_update"
+	errOpConflict       = "con_conflict"
+	errOpNotFound       = "con_not_found"
+	errOpProtected      = "con_protected"
+	errOpGetEnv         = "con_environment"
+	errOpInvalidOptions = "con_invalid_options"
 )
 
 const (
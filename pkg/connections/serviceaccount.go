@@ -0,0 +1,69 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// ServiceAccount : The confidential client a connection authenticates as, with the client credentials grant,
+// instead of a user. Its secret is read from an environment variable or a file each time it is needed, and is
+// never saved in the connections file or the keyring
+type ServiceAccount struct {
+	ClientID   string `json:"clientid"`
+	SecretEnv  string `json:"secretenv,omitempty"`
+	SecretFile string `json:"secretfile,omitempty"`
+}
+
+// SetServiceAccount : Configure a connection to authenticate as a service account, or as a user again if
+// the account is nil
+func SetServiceAccount(conID string, account *ServiceAccount) (*Connection, *ConError) {
+	if strings.EqualFold(conID, "LOCAL") {
+		err := errors.New("Local is a required connection that must not be modified")
+		return nil, &ConError{errOpProtected, err, err.Error()}
+	}
+	if account != nil {
+		if account.ClientID == "" {
+			err := errors.New("A service account must have a client ID")
+			return nil, &ConError{errOpInvalidOptions, err, err.Error()}
+		}
+		if (account.SecretEnv == "") == (account.SecretFile == "") {
+			err := errors.New("A service account's secret must come from either an environment variable or a file")
+			return nil, &ConError{errOpInvalidOptions, err, err.Error()}
+		}
+		if account.SecretFile != "" {
+			secretFile, err := filepath.Abs(account.SecretFile)
+			if err != nil {
+				return nil, &ConError{errOpInvalidOptions, err, err.Error()}
+			}
+			account.SecretFile = secretFile
+		}
+	}
+
+	data, conErr := loadConnectionsConfigFile()
+	if conErr != nil {
+		return nil, conErr
+	}
+	for i := range data.Connections {
+		if strings.EqualFold(data.Connections[i].ID, conID) {
+			data.Connections[i].ServiceAccount = account
+			if conErr := saveConnectionsConfigFile(data); conErr != nil {
+				return nil, conErr
+			}
+			return &data.Connections[i], nil
+		}
+	}
+	err := errors.New("Connection " + strings.ToUpper(conID) + " not found")
+	return nil, &ConError{errOpNotFound, err, err.Error()}
+}
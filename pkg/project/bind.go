@@ -15,6 +15,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -32,8 +33,10 @@ import (
 type (
 	// ProjectType represents the information Codewind requires to build a project.
 	ProjectType struct {
-		Language  string `json:"language"`
-		BuildType string `json:"projectType"`
+		Language   string  `json:"language"`
+		BuildType  string  `json:"projectType"`
+		Rule       string  `json:"detectionRule,omitempty"`
+		Confidence float64 `json:"confidence,omitempty"`
 	}
 
 	// BindRequest represents the response to validating a project on the users filesystem
@@ -67,6 +70,10 @@ func BindProject(c *cli.Context) (*BindResponse, *ProjectError) {
 	language := strings.TrimSpace(c.String("language"))
 	buildType := strings.TrimSpace(c.String("type"))
 	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	if projectPath == "" || name == "" || language == "" || buildType == "" {
+		err := errors.New("the name, language, type and path of the project must be given, or a directory to scan")
+		return nil, &ProjectError{errOpInvalidOptions, err, err.Error()}
+	}
 	return Bind(projectPath, name, language, buildType, conID)
 }
 
@@ -106,7 +113,32 @@ func Bind(projectPath string, name string, language string, projectType string,
 	projectID := projectInfo.ProjectID
 
 	// Sync all the project files
-	syncInfo, syncErr := syncFiles(&http.Client{}, projectPath, projectID, conURL, 0, conInfo)
+	options := syncOptions{
+		lastSync:    0,
+		manifest:    newSyncManifest(),
+		concurrency: projectSyncConcurrency(projectPath),
+		pfeSupports: pfeCapabilityCheck(client, conInfo, conURL),
+	}
+	return bindFiles(client, projectPath, projectID, conURL, conInfo, options)
+}
+
+// bindFiles : Upload all the files of a project being bound, then tell PFE the bind is complete
+func bindFiles(client utils.HTTPClient, projectPath string, projectID string, conURL string, conInfo *connections.Connection, options syncOptions) (*BindResponse, *ProjectError) {
+	syncInfo, syncErr := syncFiles(client, projectPath, projectID, conURL, conInfo, options)
+	if syncInfo == nil {
+		return nil, syncErr
+	}
+
+	// Completing the bind with files missing would leave PFE building a partial project
+	if syncInfo.failedUploads > 0 {
+		text := fmt.Sprintf("%d files failed to upload, remove the project and bind it again", syncInfo.failedUploads)
+		response := BindResponse{
+			ProjectID:     projectID,
+			UploadedFiles: syncInfo.UploadedFileList,
+			Status:        "Incomplete",
+		}
+		return &response, &ProjectError{errOpBind, errors.New(text), text}
+	}
 
 	// Call bind/end to complete
 	completeStatus, completeStatusCode := completeBind(client, projectID, conURL, conInfo)
@@ -116,6 +148,11 @@ func Bind(projectPath string, name string, language string, projectType string,
 		Status:        completeStatus,
 		StatusCode:    completeStatusCode,
 	}
+
+	// Only record what was uploaded once PFE has accepted the bind, as with a sync
+	if completeStatusCode == http.StatusOK || completeStatusCode == http.StatusAccepted {
+		saveSyncManifest(projectID, syncInfo.manifest)
+	}
 	return &response, syncErr
 }
 
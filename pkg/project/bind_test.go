@@ -17,6 +17,8 @@ import (
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"path"
 	"testing"
 	"time"
 
@@ -121,3 +123,30 @@ func TestCompleteBind(t *testing.T) {
 	_, gotStatusCode := completeBind(mockClient, "testID", "dummyURL", &mockConnection)
 	assert.Equal(t, http.StatusOK, gotStatusCode)
 }
+
+func TestBindFiles(t *testing.T) {
+	t.Run("fail case - the project can't be walked", func(t *testing.T) {
+		mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte{}))}
+		mockConnection := connections.Connection{ID: "local"}
+		options := syncOptions{manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency}
+		got, projErr := bindFiles(mockClient, "bind_test_missing_folder", "mockID", "dummyURL", &mockConnection, options)
+		assert.Nil(t, got)
+		assert.Equal(t, errOpSync, projErr.Op)
+	})
+
+	t.Run("fail case - files fail to upload, so the bind is not completed", func(t *testing.T) {
+		testDir := "bind_test_failed_upload"
+		os.Mkdir(testDir, 0777)
+		defer os.RemoveAll(testDir)
+		ioutil.WriteFile(path.Join(testDir, "test"), []byte("content"), 0644)
+
+		mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusInternalServerError, Body: ioutil.NopCloser(bytes.NewReader([]byte{}))}
+		mockConnection := connections.Connection{ID: "local"}
+		options := syncOptions{manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency}
+		got, projErr := bindFiles(mockClient, testDir, "mockID", "dummyURL", &mockConnection, options)
+		assert.Equal(t, "Incomplete", got.Status)
+		assert.Equal(t, []UploadedFile{{FilePath: "test", StatusCode: http.StatusInternalServerError}}, got.UploadedFiles)
+		assert.Equal(t, errOpBind, projErr.Op)
+		assert.Equal(t, "1 files failed to upload, remove the project and bind it again", projErr.Desc)
+	})
+}
@@ -14,12 +14,12 @@ package project
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
 	"path"
-	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -50,11 +50,16 @@ type (
 		MavenProfiles     []string `json:"mavenProfiles,omitempty"`
 		MavenProperties   []string `json:"mavenProperties,omitempty"`
 		StatusPingTimeout string   `json:"statusPingTimeout"`
+		UseGitignore      bool     `json:"useGitignore,omitempty"`
+		UseDockerignore   bool     `json:"useDockerignore,omitempty"`
+		Symlinks          string   `json:"symlinks,omitempty"`
+		SyncConcurrency   string   `json:"syncConcurrency,omitempty"`
 	}
 )
 
-// DownloadTemplate using the url/link provided
-func DownloadTemplate(destination, url string, gitCredentials *utils.GitCredentials) (*Result, *ProjectError) {
+// DownloadTemplate using the url/link provided, copying it from the local template cache if it
+// has been pulled. If offline is set, templates that aren't cached are not downloaded
+func DownloadTemplate(destination, url string, gitCredentials *utils.GitCredentials, offline bool) (*Result, *ProjectError) {
 	projErr := checkProjectDirIsEmpty(destination)
 	if projErr != nil {
 		return nil, projErr
@@ -70,7 +75,16 @@ func DownloadTemplate(destination, url string, gitCredentials *utils.GitCredenti
 		projectName = "PROJ_NAME_PLACEHOLDER"
 	}
 
-	err := utils.DownloadFromURLThenExtract(url, destination, gitCredentials)
+	cached, err := utils.CopyCachedTemplate(utils.TemplateCacheDir(connections.GetConnectionConfigDir()), url, destination)
+	if err != nil {
+		return nil, &ProjectError{errOpCreateProject, err, err.Error()}
+	}
+	if !cached && offline {
+		return nil, errTemplateNotCached(url)
+	}
+	if !cached {
+		err = utils.DownloadFromURLThenExtract(url, destination, gitCredentials)
+	}
 	if err != nil {
 		errOp := errOpCreateProject
 		// if 401 error, use invalid credentials error code
@@ -89,6 +103,12 @@ func DownloadTemplate(destination, url string, gitCredentials *utils.GitCredenti
 	return &response, nil
 }
 
+// errTemplateNotCached : The error returned when a template is needed offline but hasn't been pulled
+func errTemplateNotCached(url string) *ProjectError {
+	err := fmt.Errorf("template %s is not in the local cache, run 'templates pull' while online to cache it", url)
+	return &ProjectError{errOpCreateProject, err, err.Error()}
+}
+
 // checkIsExtension checks if a project is an extension project and run associated commands as necessary
 func checkIsExtension(conID, projectPath string, c *cli.Context) (string, error) {
 	extensions, err := apiroutes.GetExtensions(conID)
@@ -152,18 +172,26 @@ func ValidateProject(c *cli.Context) (*ValidationResponse, *ProjectError) {
 	validationStatus := "success"
 	// result could be ProjectType or string, so define as an interface
 	var validationResult interface{}
-	language, buildType := determineProjectInfo(projectPath)
+	detected, projErr := detectProjectType(projectPath)
+	if projErr != nil {
+		return nil, projErr
+	}
+	language, buildType := detected.Language, detected.BuildType
 	validationResult = ProjectType{
-		Language:  language,
-		BuildType: buildType,
+		Language:   language,
+		BuildType:  buildType,
+		Rule:       detected.Rule,
+		Confidence: detected.Confidence,
 	}
 
 	extensionType, err := checkIsExtension(conID, projectPath, c)
 	if extensionType != "" {
 		if err == nil {
 			validationResult = ProjectType{
-				Language:  language,
-				BuildType: extensionType,
+				Language:   language,
+				BuildType:  extensionType,
+				Rule:       "extension",
+				Confidence: 1,
 			}
 		} else {
 			validationStatus = "failed"
@@ -251,67 +279,14 @@ func checkProjectPathExists(projectPath string) *ProjectError {
 	return nil
 }
 
-// determineProjectInfo returns the language and build-type of a project
-func determineProjectInfo(projectPath string) (string, string) {
-	language, buildType := "unknown", "docker"
-	if utils.PathExists(path.Join(projectPath, "pom.xml")) {
-		language = "java"
-		buildType = determineJavaBuildType(projectPath)
-	} else if utils.PathExists(path.Join(projectPath, "package.json")) {
-		language = "javascript"
-		buildType = "nodejs"
-	} else if utils.PathExists(path.Join(projectPath, "Package.swift")) {
-		language = "swift"
-		buildType = "swift"
-	} else {
-		determinedLanguage, err := determineProjectLanguage(projectPath)
-		if err != nil {
-			language = "unknown"
-		}
-		language = determinedLanguage
-		buildType = "docker"
-	}
-	return language, buildType
-}
-
-func determineJavaBuildType(projectPath string) string {
-	pathToPomXML := path.Join(projectPath, "pom.xml")
-	pomXMLContents, err := ioutil.ReadFile(pathToPomXML)
-	// if there is an error reading the pom.xml, we build as docker
-	if err != nil {
-		return "docker"
-	}
-	pomXMLString := string(pomXMLContents)
-	if strings.Contains(pomXMLString, "<groupId>org.springframework.boot</groupId>") {
-		return "spring"
-	}
-	pathToDockerfile := path.Join(projectPath, "Dockerfile")
-	dockerfileContents, err := ioutil.ReadFile(pathToDockerfile)
-	dockerfileString := string(dockerfileContents)
-	if strings.Contains(dockerfileString, "FROM websphere-liberty") {
-		return "liberty"
-	}
-	return "docker"
-}
-
-func determineProjectLanguage(projectPath string) (string, *ProjectError) {
-	projectFiles, err := ioutil.ReadDir(projectPath)
-	if err != nil {
-		return "", &ProjectError{errOpCreateProject, err, err.Error()}
-	}
-	for _, file := range projectFiles {
-		if !file.IsDir() {
-			switch filepath.Ext(file.Name()) {
-			case ".py":
-				return "python", nil
-			case ".go":
-				return "go", nil
-			default:
-				continue
-			}
-		}
+// determineProjectInfo returns the language and build-type of a project, or an error if the
+// user rules file can't be used
+func determineProjectInfo(projectPath string) (string, string, *ProjectError) {
+	detected, projErr := detectProjectType(projectPath)
+	if projErr != nil {
+		return "", "", projErr
 	}
-	return "unknown", nil
+	return detected.Language, detected.BuildType, nil
 }
 
 // RenameLegacySettings renames a .mc-settings file to .cw-settings
@@ -364,9 +339,13 @@ func getDefaultCwSettings(httpClient utils.HTTPClient, connection *connections.C
 	}, nil
 }
 
+// The project types that use the build type specific fields of .cw-settings
+var (
+	projectTypesWithInternalDebugPort = []string{"liberty", "spring", "nodejs"}
+	projectTypesWithMavenSettings     = []string{"liberty", "spring"}
+)
+
 func addNonDefaultFieldsToCwSettings(cwSettings CWSettings, ProjectType string) CWSettings {
-	projectTypesWithInternalDebugPort := []string{"liberty", "spring", "nodejs"}
-	projectTypesWithMavenSettings := []string{"liberty", "spring"}
 	if stringInSlice(ProjectType, projectTypesWithInternalDebugPort) {
 		// We use a pointer, as an empty string would be removed due to omitempty on struct
 		defaultValue := ""
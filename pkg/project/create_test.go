@@ -40,7 +40,7 @@ func TestDownloadTemplate(t *testing.T) {
 		dest := filepath.Join(testDir, "insecureTemplateRepo")
 		url := test.PublicGHRepoURL
 
-		out, err := DownloadTemplate(dest, url, nil)
+		out, err := DownloadTemplate(dest, url, nil, false)
 
 		assert.Equal(t, "success", out.Status)
 		assert.Nil(t, err)
@@ -60,7 +60,7 @@ func TestDownloadTemplate(t *testing.T) {
 			Password: test.GHEPassword,
 		}
 
-		out, err := DownloadTemplate(dest, url, gitCredentials)
+		out, err := DownloadTemplate(dest, url, gitCredentials, false)
 
 		assert.NotNil(t, out)
 		assert.Nil(t, err)
@@ -79,7 +79,7 @@ func TestDownloadTemplate(t *testing.T) {
 			PersonalAccessToken: test.GHEPersonalAccessToken,
 		}
 
-		out, err := DownloadTemplate(dest, url, gitCredentials)
+		out, err := DownloadTemplate(dest, url, gitCredentials, false)
 
 		assert.NotNil(t, out)
 		assert.Nil(t, err)
@@ -95,7 +95,7 @@ func TestDownloadTemplate(t *testing.T) {
 			Password: "badpassword",
 		}
 
-		out, err := DownloadTemplate(dest, url, gitCredentials)
+		out, err := DownloadTemplate(dest, url, gitCredentials, false)
 
 		assert.Nil(t, out)
 		assert.Equal(t, errOpInvalidCredentials, err.Op)
@@ -112,7 +112,7 @@ func TestDownloadTemplate(t *testing.T) {
 			Password: "badpersonalaccesstoken",
 		}
 
-		out, err := DownloadTemplate(dest, url, gitCredentials)
+		out, err := DownloadTemplate(dest, url, gitCredentials, false)
 
 		assert.Nil(t, out)
 		assert.Equal(t, errOpInvalidCredentials, err.Op)
@@ -120,6 +120,46 @@ func TestDownloadTemplate(t *testing.T) {
 	})
 }
 
+func TestDownloadTemplateOffline(t *testing.T) {
+	testFolder := "template_cache_test_folder_delete_me"
+	os.Mkdir(testFolder, 0777)
+	defer cleanupTestFolder(t, testFolder)
+
+	originalHome := os.Getenv("HOME")
+	absTestDir, _ := os.Getwd()
+	os.Setenv("HOME", path.Join(absTestDir, testFolder))
+	defer os.Setenv("HOME", originalHome)
+
+	// put a template in the cache as `templates pull` would
+	templateSource := path.Join(testFolder, "source")
+	os.MkdirAll(templateSource, 0755)
+	ioutil.WriteFile(path.Join(templateSource, "package.json"), []byte(`{"name": "[PROJ_NAME_PLACEHOLDER]"}`), 0644)
+	cachedURL := "file://" + filepath.ToSlash(path.Join(absTestDir, templateSource))
+	err := utils.CacheTemplate(utils.TemplateCacheDir(connections.GetConnectionConfigDir()), cachedURL, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	// the cached copy is used even once the template has gone
+	os.RemoveAll(templateSource)
+
+	t.Run("success case - a cached template is created offline", func(t *testing.T) {
+		dest := path.Join(testFolder, "projects", "myproject")
+		out, err := DownloadTemplate(dest, cachedURL, nil, true)
+		assert.Nil(t, err)
+		assert.Equal(t, "success", out.Status)
+		content, _ := ioutil.ReadFile(path.Join(dest, "package.json"))
+		assert.Equal(t, `{"name": "myproject"}`, string(content))
+	})
+
+	t.Run("fail case - a template that isn't cached can't be created offline", func(t *testing.T) {
+		dest := path.Join(testFolder, "projects", "uncached")
+		out, err := DownloadTemplate(dest, "https://github.com/codewind-resources/notCached", nil, true)
+		assert.Nil(t, out)
+		assert.Equal(t, errOpCreateProject, err.Op)
+		assert.False(t, utils.PathExists(dest))
+	})
+}
+
 func TestDetermineProjectInfo(t *testing.T) {
 	tests := map[string]struct {
 		in            string
@@ -160,8 +200,9 @@ func TestDetermineProjectInfo(t *testing.T) {
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			gotLanguage, gotBuildType := determineProjectInfo(test.in)
+			gotLanguage, gotBuildType, err := determineProjectInfo(test.in)
 
+			assert.Nil(t, err)
 			assert.Equal(t, test.wantLanguage, gotLanguage)
 			assert.Equal(t, test.wantBuildType, gotBuildType)
 		})
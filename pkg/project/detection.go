@@ -0,0 +1,234 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+)
+
+type (
+	// detectionRule is a rule for recognising the language and build type of a project.
+	// Every condition given must hold for the rule to match
+	detectionRule struct {
+		Name       string  `json:"name"`
+		Language   string  `json:"language"`
+		BuildType  string  `json:"buildType"`
+		Confidence float64 `json:"confidence"`
+		// File is a glob, relative to the project root, that must match at least one file
+		File string `json:"file,omitempty"`
+		// Contains is a regular expression that the contents of a file matching File must match
+		Contains string `json:"contains,omitempty"`
+		// DockerfileFrom is a regular expression that a base image in the project's Dockerfile must match
+		DockerfileFrom string `json:"dockerfileFrom,omitempty"`
+
+		contains       *regexp.Regexp
+		dockerfileFrom *regexp.Regexp
+	}
+
+	// detectionRulesFile is the user rules file, whose rules are tried before the built in ones
+	detectionRulesFile struct {
+		Rules []detectionRule `json:"rules"`
+	}
+
+	// detectionResult is the project type found by the first matching rule
+	detectionResult struct {
+		Language   string
+		BuildType  string
+		Rule       string
+		Confidence float64
+	}
+)
+
+// builtinDetectionRules are tried in order, so more specific rules come before more general ones
+var builtinDetectionRules = []detectionRule{
+	{Name: "spring-maven", Language: "java", BuildType: "spring", Confidence: 0.9, File: "pom.xml", Contains: `<groupId>org\.springframework\.boot</groupId>`},
+	{Name: "liberty-maven", Language: "java", BuildType: "liberty", Confidence: 0.9, File: "pom.xml", DockerfileFrom: `^websphere-liberty`},
+	{Name: "quarkus-maven", Language: "java", BuildType: "docker", Confidence: 0.8, File: "pom.xml", Contains: `<groupId>io\.quarkus</groupId>`},
+	{Name: "maven", Language: "java", BuildType: "docker", Confidence: 0.7, File: "pom.xml"},
+	{Name: "quarkus-gradle", Language: "java", BuildType: "docker", Confidence: 0.8, File: "build.gradle*", Contains: `io\.quarkus`},
+	{Name: "gradle", Language: "java", BuildType: "docker", Confidence: 0.7, File: "build.gradle*"},
+	{Name: "nodejs", Language: "javascript", BuildType: "nodejs", Confidence: 0.9, File: "package.json"},
+	{Name: "swift", Language: "swift", BuildType: "swift", Confidence: 0.9, File: "Package.swift"},
+	{Name: "dotnet-csharp", Language: "csharp", BuildType: "docker", Confidence: 0.8, File: "*.csproj"},
+	{Name: "dotnet-fsharp", Language: "fsharp", BuildType: "docker", Confidence: 0.8, File: "*.fsproj"},
+	{Name: "dotnet-solution", Language: "csharp", BuildType: "docker", Confidence: 0.6, File: "*.sln"},
+	{Name: "rust-cargo", Language: "rust", BuildType: "docker", Confidence: 0.9, File: "Cargo.toml"},
+	{Name: "go-modules", Language: "go", BuildType: "docker", Confidence: 0.9, File: "go.mod"},
+	{Name: "python-pyproject", Language: "python", BuildType: "docker", Confidence: 0.8, File: "pyproject.toml"},
+	{Name: "python-requirements", Language: "python", BuildType: "docker", Confidence: 0.8, File: "requirements.txt"},
+	{Name: "python-setuptools", Language: "python", BuildType: "docker", Confidence: 0.8, File: "setup.py"},
+	{Name: "python-source", Language: "python", BuildType: "docker", Confidence: 0.5, File: "*.py"},
+	{Name: "go-source", Language: "go", BuildType: "docker", Confidence: 0.5, File: "*.go"},
+}
+
+// unknownProjectType is the result when no rule matches
+var unknownProjectType = detectionResult{Language: "unknown", BuildType: "docker"}
+
+// detectProjectType : Find the language and build type of a project using the first matching rule,
+// trying the rules in the user rules file before the built in rules
+func detectProjectType(projectPath string) (detectionResult, *ProjectError) {
+	rules, projErr := loadAllDetectionRules()
+	if projErr != nil {
+		return unknownProjectType, projErr
+	}
+	return matchDetectionRules(projectPath, rules), nil
+}
+
+// loadAllDetectionRules : Get the compiled user rules followed by the built in rules
+func loadAllDetectionRules() ([]detectionRule, *ProjectError) {
+	userRules, projErr := loadDetectionRules(getDetectionRulesFilename())
+	if projErr != nil {
+		return nil, projErr
+	}
+	return compileDetectionRules(append(userRules, builtinDetectionRules...))
+}
+
+// matchDetectionRules : Find the first rule matching a project
+func matchDetectionRules(projectPath string, rules []detectionRule) detectionResult {
+	baseImages := dockerfileBaseImages(projectPath)
+	for _, rule := range rules {
+		if rule.matches(projectPath, baseImages) {
+			return detectionResult{rule.Language, rule.BuildType, rule.Name, rule.Confidence}
+		}
+	}
+	return unknownProjectType
+}
+
+// matches : Check whether every condition of a rule holds for a project
+func (rule *detectionRule) matches(projectPath string, baseImages []string) bool {
+	if rule.File != "" {
+		files, _ := filepath.Glob(filepath.Join(projectPath, rule.File))
+		found := false
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			if rule.contains != nil {
+				contents, err := ioutil.ReadFile(file)
+				if err != nil || !rule.contains.Match(contents) {
+					continue
+				}
+			}
+			found = true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	if rule.dockerfileFrom != nil {
+		found := false
+		for _, image := range baseImages {
+			if rule.dockerfileFrom.MatchString(image) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// compileDetectionRules : Check each rule has a name and a condition, and compile its regular expressions
+func compileDetectionRules(rules []detectionRule) ([]detectionRule, *ProjectError) {
+	compiled := make([]detectionRule, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" || rule.Language == "" || rule.BuildType == "" {
+			err := fmt.Errorf("detection rule %d must have a name, language and buildType", i+1)
+			return nil, &ProjectError{errOpFileParse, err, err.Error()}
+		}
+		if rule.File == "" && rule.DockerfileFrom == "" {
+			err := fmt.Errorf("detection rule '%s' must have a file or dockerfileFrom condition", rule.Name)
+			return nil, &ProjectError{errOpFileParse, err, err.Error()}
+		}
+		if rule.Contains != "" && rule.File == "" {
+			err := fmt.Errorf("detection rule '%s' has a contains condition but no file", rule.Name)
+			return nil, &ProjectError{errOpFileParse, err, err.Error()}
+		}
+		var err error
+		if rule.Contains != "" {
+			if rule.contains, err = regexp.Compile(rule.Contains); err != nil {
+				err = fmt.Errorf("detection rule '%s' has an invalid contains expression: %v", rule.Name, err)
+				return nil, &ProjectError{errOpFileParse, err, err.Error()}
+			}
+		}
+		if rule.DockerfileFrom != "" {
+			if rule.dockerfileFrom, err = regexp.Compile(rule.DockerfileFrom); err != nil {
+				err = fmt.Errorf("detection rule '%s' has an invalid dockerfileFrom expression: %v", rule.Name, err)
+				return nil, &ProjectError{errOpFileParse, err, err.Error()}
+			}
+		}
+		compiled[i] = rule
+	}
+	return compiled, nil
+}
+
+// loadDetectionRules : Read the rules in a user rules file, which need not exist
+func loadDetectionRules(filename string) ([]detectionRule, *ProjectError) {
+	file, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	var rulesFile detectionRulesFile
+	err = json.Unmarshal(file, &rulesFile)
+	if err != nil {
+		err = fmt.Errorf("unable to parse detection rules file %s: %v", filename, err)
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	return rulesFile.Rules, nil
+}
+
+// dockerfileBaseImages : Get the images named by the FROM instructions of a project's Dockerfile
+func dockerfileBaseImages(projectPath string) []string {
+	contents, err := ioutil.ReadFile(filepath.Join(projectPath, "Dockerfile"))
+	if err != nil {
+		return nil
+	}
+	var images []string
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		// skip flags such as --platform
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "--") {
+				images = append(images, field)
+				break
+			}
+		}
+	}
+	return images
+}
+
+// getDetectionRulesFilename : Get the user rules file, in the Codewind home directory
+func getDetectionRulesFilename() string {
+	return path.Join(path.Dir(connections.GetConnectionConfigDir()), "detection-rules.json")
+}
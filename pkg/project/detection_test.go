@@ -0,0 +1,137 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectProjectType(t *testing.T) {
+	testFolder := "detection_test_folder_delete_me"
+	os.Mkdir(testFolder, 0777)
+	defer cleanupTestFolder(t, testFolder)
+
+	originalHome := os.Getenv("HOME")
+	absTestDir, _ := os.Getwd()
+	os.Setenv("HOME", path.Join(absTestDir, testFolder))
+	defer os.Setenv("HOME", originalHome)
+	os.MkdirAll(path.Dir(getDetectionRulesFilename()), 0755)
+
+	tests := map[string]struct {
+		files         map[string]string
+		wantLanguage  string
+		wantBuildType string
+		wantRule      string
+	}{
+		"spring project": {
+			files:         map[string]string{"pom.xml": "<groupId>org.springframework.boot</groupId>"},
+			wantLanguage:  "java",
+			wantBuildType: "spring",
+			wantRule:      "spring-maven",
+		},
+		"liberty project with a tagged base image": {
+			files:         map[string]string{"pom.xml": "<project/>", "Dockerfile": "FROM --platform=linux/amd64 websphere-liberty:19.0.0.3-webProfile7\n"},
+			wantLanguage:  "java",
+			wantBuildType: "liberty",
+			wantRule:      "liberty-maven",
+		},
+		"quarkus maven project": {
+			files:         map[string]string{"pom.xml": "<groupId>io.quarkus</groupId>"},
+			wantLanguage:  "java",
+			wantBuildType: "docker",
+			wantRule:      "quarkus-maven",
+		},
+		"gradle kotlin project": {
+			files:         map[string]string{"build.gradle.kts": "plugins { java }"},
+			wantLanguage:  "java",
+			wantBuildType: "docker",
+			wantRule:      "gradle",
+		},
+		".NET project": {
+			files:         map[string]string{"service.csproj": "<Project/>"},
+			wantLanguage:  "csharp",
+			wantBuildType: "docker",
+			wantRule:      "dotnet-csharp",
+		},
+		"rust project": {
+			files:         map[string]string{"Cargo.toml": "[package]"},
+			wantLanguage:  "rust",
+			wantBuildType: "docker",
+			wantRule:      "rust-cargo",
+		},
+		"python project with requirements and no top level source": {
+			files:         map[string]string{"requirements.txt": "flask"},
+			wantLanguage:  "python",
+			wantBuildType: "docker",
+			wantRule:      "python-requirements",
+		},
+		"unrecognised project": {
+			files:         map[string]string{"README.md": "# readme"},
+			wantLanguage:  "unknown",
+			wantBuildType: "docker",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			projectPath := path.Join(testFolder, "projects", name)
+			os.MkdirAll(projectPath, 0755)
+			for filename, contents := range test.files {
+				ioutil.WriteFile(path.Join(projectPath, filename), []byte(contents), 0644)
+			}
+
+			got, err := detectProjectType(projectPath)
+			assert.Nil(t, err)
+			assert.Equal(t, test.wantLanguage, got.Language)
+			assert.Equal(t, test.wantBuildType, got.BuildType)
+			assert.Equal(t, test.wantRule, got.Rule)
+		})
+	}
+
+	t.Run("user rules are tried before the built in rules", func(t *testing.T) {
+		rules := `{"rules": [{"name": "vertx", "language": "java", "buildType": "docker", "confidence": 0.95, "file": "pom.xml", "contains": "io\\.vertx"}]}`
+		ioutil.WriteFile(getDetectionRulesFilename(), []byte(rules), 0644)
+		defer os.Remove(getDetectionRulesFilename())
+
+		projectPath := path.Join(testFolder, "projects", "vertx")
+		os.MkdirAll(projectPath, 0755)
+		ioutil.WriteFile(path.Join(projectPath, "pom.xml"), []byte("<groupId>io.vertx</groupId>"), 0644)
+
+		got, err := detectProjectType(projectPath)
+		assert.Nil(t, err)
+		assert.Equal(t, detectionResult{"java", "docker", "vertx", 0.95}, got)
+	})
+
+	t.Run("fail case - a user rule with an invalid expression is reported", func(t *testing.T) {
+		rules := `{"rules": [{"name": "bad", "language": "java", "buildType": "docker", "file": "pom.xml", "contains": "("}]}`
+		ioutil.WriteFile(getDetectionRulesFilename(), []byte(rules), 0644)
+		defer os.Remove(getDetectionRulesFilename())
+
+		_, err := detectProjectType(testFolder)
+		assert.Equal(t, errOpFileParse, err.Op)
+	})
+
+	t.Run("fail case - a malformed user rules file is reported rather than falling back to the built in rules", func(t *testing.T) {
+		ioutil.WriteFile(getDetectionRulesFilename(), []byte(`{"rules": [`), 0644)
+		defer os.Remove(getDetectionRulesFilename())
+
+		projectPath := path.Join("../..", "resources", "test", "node-project")
+		language, buildType, err := determineProjectInfo(projectPath)
+		assert.Equal(t, errOpFileParse, err.Op)
+		assert.Equal(t, "", language)
+		assert.Equal(t, "", buildType)
+	})
+}
@@ -0,0 +1,286 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// ProjectExport : Everything needed to bind a checkout of a project on another machine or connection the
+	// way it is bound here. The settings and ref paths files are kept as they are, so fields this CLI doesn't
+	// know about are kept too
+	ProjectExport struct {
+		Version     int              `json:"version"`
+		ExportedAt  int64            `json:"exportedAt"` // milliseconds since the epoch
+		Name        string           `json:"name"`
+		Language    string           `json:"language"`
+		ProjectType string           `json:"projectType"`
+		StartMode   string           `json:"startMode,omitempty"`
+		Connection  ExportConnection `json:"connection"`
+		Settings    json.RawMessage  `json:"settings,omitempty"`
+		RefPaths    json.RawMessage  `json:"refPaths,omitempty"`
+		Links       []ExportedLink   `json:"links"`
+	}
+
+	// ExportConnection : The connection a project was bound to when it was exported
+	ExportConnection struct {
+		ID    string `json:"id"`
+		Label string `json:"label"`
+		URL   string `json:"url,omitempty"`
+	}
+
+	// ExportedLink : A link of an exported project, to a project identified by name
+	ExportedLink struct {
+		EnvName    string `json:"envName"`
+		TargetName string `json:"targetName"`
+	}
+
+	// ImportResult : The project bound by an import, what happened to its links, and anything the import couldn't do
+	ImportResult struct {
+		ProjectID    string          `json:"projectID"`
+		Name         string          `json:"name"`
+		Connection   string          `json:"connection"`
+		FilesWritten []string        `json:"filesWritten"`
+		Links        []RecreatedLink `json:"links"`
+		Warnings     []string        `json:"warnings"`
+	}
+)
+
+// exportVersion : The version of the export format, which is increased when it changes incompatibly
+const exportVersion = 1
+
+// importStartTimeout : How long an imported project may take to start before it is restarted in its exported mode
+const importStartTimeout = 5 * time.Minute
+
+// The project files kept in an export
+const (
+	exportSettingsFile = ".cw-settings"
+	exportRefPathsFile = ".cw-refpaths.json"
+)
+
+// ExportProject : Export the settings, ref paths, connection, start mode and links of a project
+func ExportProject(projectID string) (*ProjectExport, *ProjectError) {
+	conInfo, conURL, projErr := getProjectConnection(projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	return exportProject(http.DefaultClient, conInfo, conURL, projectID)
+}
+
+func exportProject(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string) (*ProjectExport, *ProjectError) {
+	project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	links, projErr := GetProjectLinks(httpClient, conInfo, conURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	export := &ProjectExport{
+		Version:     exportVersion,
+		ExportedAt:  time.Now().UnixNano() / 1000000,
+		Name:        project.Name,
+		Language:    project.Language,
+		ProjectType: project.ProjectType,
+		StartMode:   project.StartMode,
+		Connection:  ExportConnection{ID: conInfo.ID, Label: conInfo.Label, URL: conInfo.URL},
+		Links:       []ExportedLink{},
+	}
+	if export.Settings, projErr = readExportFile(project.LocationOnDisk, exportSettingsFile); projErr != nil {
+		return nil, projErr
+	}
+	if export.RefPaths, projErr = readExportFile(project.LocationOnDisk, exportRefPathsFile); projErr != nil {
+		return nil, projErr
+	}
+	for _, link := range links {
+		export.Links = append(export.Links, ExportedLink{EnvName: link.EnvName, TargetName: link.ProjectName})
+	}
+	return export, nil
+}
+
+// readExportFile : Read a JSON file of a project, or nothing if it doesn't exist
+func readExportFile(projectPath string, filename string) (json.RawMessage, *ProjectError) {
+	content, err := ioutil.ReadFile(filepath.Join(projectPath, filename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	if !json.Valid(content) {
+		err := fmt.Errorf("%s is not valid JSON", filename)
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	var compacted bytes.Buffer
+	json.Compact(&compacted, content)
+	return compacted.Bytes(), nil
+}
+
+// LoadProjectExport : Load a project export from a file
+func LoadProjectExport(filename string) (*ProjectExport, *ProjectError) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	var export ProjectExport
+	if err := json.Unmarshal(content, &export); err != nil {
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	if export.Version != exportVersion {
+		err := fmt.Errorf("export version %d is not supported, this CLI imports version %d", export.Version, exportVersion)
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	if export.Name == "" || export.Language == "" || export.ProjectType == "" {
+		err := fmt.Errorf("export must have a name, language and project type")
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	return &export, nil
+}
+
+// ImportProject : Bind a checkout of an exported project, writing the settings and ref paths files it doesn't
+// have and recreating the links to projects with the same names on the connection. The project is bound to the
+// connection it was exported from unless another is given, and keeps its name unless another is given
+func ImportProject(export *ProjectExport, projectPath string, conID string, name string) (*ImportResult, *ProjectError) {
+	exportedConnection := conID == ""
+	if exportedConnection {
+		conID = export.Connection.ID
+	}
+	target, projErr := getMoveEndpoint(conID)
+	if projErr != nil {
+		if exportedConnection {
+			err := fmt.Errorf("the project was exported from connection %s (%s), which isn't available here, so a connection must be given: %s", conID, export.Connection.Label, projErr.Desc)
+			return nil, &ProjectError{errOpConNotFound, err, err.Error()}
+		}
+		return nil, projErr
+	}
+	if name == "" {
+		name = export.Name
+	}
+	return importProject(http.DefaultClient, target, export, projectPath, name)
+}
+
+func importProject(httpClient utils.HTTPClient, target *moveEndpoint, export *ProjectExport, projectPath string, name string) (*ImportResult, *ProjectError) {
+	info, err := os.Stat(projectPath)
+	if err != nil || !info.IsDir() {
+		err := fmt.Errorf("%s: %s", textProjectPathDoesNotExist, projectPath)
+		return nil, &ProjectError{errBadPath, err, err.Error()}
+	}
+	targetProjects, projErr := GetAll(httpClient, target.conInfo, target.url)
+	if projErr != nil {
+		return nil, projErr
+	}
+	for _, targetProject := range targetProjects {
+		if targetProject.Name == name {
+			err := fmt.Errorf("a project named %s is already bound to connection %s", name, target.conInfo.ID)
+			return nil, &ProjectError{errOpConflict, err, err.Error()}
+		}
+	}
+
+	result := &ImportResult{Name: name, Connection: target.conInfo.ID, FilesWritten: []string{}, Warnings: []string{}}
+
+	// the files must be in place before the bind, as they control what is synced
+	files := []struct {
+		name    string
+		content json.RawMessage
+	}{{exportSettingsFile, export.Settings}, {exportRefPathsFile, export.RefPaths}}
+	for _, file := range files {
+		written, differs, projErr := writeImportFile(projectPath, file.name, file.content)
+		if projErr != nil {
+			removeImportFiles(projectPath, result.FilesWritten)
+			return nil, projErr
+		}
+		if written {
+			result.FilesWritten = append(result.FilesWritten, file.name)
+		}
+		if differs {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("kept the checkout's %s, which differs from the exported one", file.name))
+		}
+	}
+
+	result.ProjectID, projErr = bindToEndpoint(httpClient, target, projectPath, name, export.Language, export.ProjectType, errOpImport)
+	if projErr != nil {
+		removeImportFiles(projectPath, result.FilesWritten)
+		return nil, projErr
+	}
+
+	links := []Link{}
+	for _, link := range export.Links {
+		links = append(links, Link{ProjectName: link.TargetName, EnvName: link.EnvName})
+	}
+	result.Links, projErr = recreateLinks(httpClient, target, result.ProjectID, links, targetProjects)
+	if projErr != nil {
+		removeImportFiles(projectPath, result.FilesWritten)
+		return nil, rollbackBind(httpClient, target, result.ProjectID, errOpImport, projErr)
+	}
+
+	// projects are bound in run mode, and PFE refuses to restart a project that is still building, so it is
+	// restarted in the exported mode once it has started. If it doesn't start, the user must restart it later
+	if export.StartMode != "" && export.StartMode != "run" {
+		var err error
+		if _, projErr := WaitForProject(httpClient, target.conInfo, target.url, result.ProjectID, importStartTimeout, nil, nil); projErr != nil {
+			err = projErr
+		} else {
+			err = RestartProject(httpClient, target.conInfo, target.url, result.ProjectID, export.StartMode)
+		}
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("project was exported in %s mode, restart it in that mode once it has built", export.StartMode))
+		}
+	}
+	return result, nil
+}
+
+// removeImportFiles : Remove the files an import wrote into a checkout, leaving the checkout as it was
+// when the import fails
+func removeImportFiles(projectPath string, files []string) {
+	for _, file := range files {
+		os.Remove(filepath.Join(projectPath, file))
+	}
+}
+
+// writeImportFile : Write an exported file into a checkout if the checkout doesn't have it, reporting whether it
+// was written, or whether the checkout's file differs from the exported one
+func writeImportFile(projectPath string, filename string, content json.RawMessage) (bool, bool, *ProjectError) {
+	if len(content) == 0 {
+		return false, false, nil
+	}
+	existing, projErr := readExportFile(projectPath, filename)
+	if projErr != nil {
+		return false, false, projErr
+	}
+	if existing != nil {
+		var compacted bytes.Buffer
+		json.Compact(&compacted, content)
+		return false, !bytes.Equal(existing, compacted.Bytes()), nil
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, content, "", "  "); err != nil {
+		return false, false, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	indented.WriteString("\n")
+	if err := ioutil.WriteFile(filepath.Join(projectPath, filename), indented.Bytes(), 0644); err != nil {
+		return false, false, &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	return true, false, nil
+}
@@ -0,0 +1,204 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+// clientMockExport serves a project, its links and the projects on its connection, recording the
+// requests that change anything. Links are created with linkStatus if it is set
+type clientMockExport struct {
+	projectPath string
+	projects    string
+	linkStatus  int
+	calls       []string
+}
+
+func (c *clientMockExport) Do(req *http.Request) (*http.Response, error) {
+	call := req.Method + " " + req.URL.Path
+	status, body := http.StatusOK, ""
+	switch call {
+	case "GET /api/v1/projects/mockID/":
+		project := Project{ProjectID: "mockID", Name: "api", Language: "java", ProjectType: "liberty", StartMode: "debug", LocationOnDisk: c.projectPath}
+		projectJSON, _ := json.Marshal(project)
+		body = string(projectJSON)
+	case "GET /api/v1/projects/newID/":
+		body = `{"projectID": "newID", "name": "api", "appStatus": "started", "buildStatus": "success"}`
+	case "GET /api/v1/projects/mockID/links":
+		body = `[{"projectID": "dbID", "projectName": "db", "envName": "DB_URL"}]`
+	case "GET /api/v1/projects/":
+		body = c.projects
+	case "POST /api/v1/projects/newID/links":
+		c.calls = append(c.calls, call)
+		status = http.StatusAccepted
+		if c.linkStatus != 0 {
+			status = c.linkStatus
+		}
+	default:
+		c.calls = append(c.calls, call)
+		status = http.StatusAccepted
+	}
+	return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+}
+
+func TestExportProject(t *testing.T) {
+	projectPath, _ := ioutil.TempDir("", "export")
+	defer os.RemoveAll(projectPath)
+	ioutil.WriteFile(filepath.Join(projectPath, ".cw-settings"), []byte("{\n  \"contextRoot\": \"/api\",\n  \"futureField\": 1\n}\n"), 0644)
+
+	mockClient := &clientMockExport{projectPath: projectPath}
+	export, err := exportProject(mockClient, &connections.Connection{ID: "local", Label: "Local"}, "http://mockURL", "mockID")
+	assert.Nil(t, err)
+	assert.Equal(t, "api", export.Name)
+	assert.Equal(t, "liberty", export.ProjectType)
+	assert.Equal(t, "debug", export.StartMode)
+	assert.Equal(t, ExportConnection{ID: "local", Label: "Local"}, export.Connection)
+	assert.Equal(t, `{"contextRoot":"/api","futureField":1}`, string(export.Settings))
+	assert.Nil(t, export.RefPaths)
+	assert.Equal(t, []ExportedLink{{EnvName: "DB_URL", TargetName: "db"}}, export.Links)
+}
+
+func TestImportProject(t *testing.T) {
+	originalBind := bindProjectTo
+	defer func() { bindProjectTo = originalBind }()
+	bindNewID := func(projectPath string, name string, language string, projectType string, conID string) (*BindResponse, *ProjectError) {
+		return &BindResponse{ProjectID: "newID", StatusCode: http.StatusAccepted}, nil
+	}
+	bindProjectTo = bindNewID
+	target := &moveEndpoint{&connections.Connection{ID: "local"}, "http://mockURL"}
+	export := &ProjectExport{
+		Version:     exportVersion,
+		Name:        "api",
+		Language:    "java",
+		ProjectType: "liberty",
+		StartMode:   "debug",
+		Settings:    json.RawMessage(`{"contextRoot":"/api"}`),
+		RefPaths:    json.RawMessage(`{"refPaths":[{"from":"../lib","to":"lib"}]}`),
+		Links:       []ExportedLink{{EnvName: "DB_URL", TargetName: "db"}, {EnvName: "CACHE_URL", TargetName: "cache"}},
+	}
+
+	t.Run("success case - writes missing files, keeps existing ones and recreates links", func(t *testing.T) {
+		projectPath, _ := ioutil.TempDir("", "import")
+		defer os.RemoveAll(projectPath)
+		ioutil.WriteFile(filepath.Join(projectPath, ".cw-settings"), []byte(`{"contextRoot": "/v2"}`), 0644)
+
+		mockClient := &clientMockExport{projects: `[{"projectID": "dbID", "name": "db"}]`}
+		result, err := importProject(mockClient, target, export, projectPath, "api")
+		assert.Nil(t, err)
+		assert.Equal(t, "newID", result.ProjectID)
+		assert.Equal(t, []string{".cw-refpaths.json"}, result.FilesWritten)
+		assert.Equal(t, []RecreatedLink{{"DB_URL", "db", LinkRecreated}, {"CACHE_URL", "cache", LinkSkipped}}, result.Links)
+		assert.Equal(t, []string{"kept the checkout's .cw-settings, which differs from the exported one"}, result.Warnings)
+		assert.Equal(t, []string{"POST /api/v1/projects/newID/links", "POST /api/v1/projects/newID/restart"}, mockClient.calls)
+
+		settings, _ := ioutil.ReadFile(filepath.Join(projectPath, ".cw-settings"))
+		assert.Equal(t, `{"contextRoot": "/v2"}`, string(settings))
+		refPaths, _ := readExportFile(projectPath, ".cw-refpaths.json")
+		assert.Equal(t, string(export.RefPaths), string(refPaths))
+	})
+
+	t.Run("fail case - a project with the same name is already bound", func(t *testing.T) {
+		projectPath, _ := ioutil.TempDir("", "import")
+		defer os.RemoveAll(projectPath)
+
+		mockClient := &clientMockExport{projects: `[{"projectID": "otherID", "name": "api"}]`}
+		_, err := importProject(mockClient, target, export, projectPath, "api")
+		assert.Equal(t, errOpConflict, err.Op)
+		assert.Nil(t, mockClient.calls)
+		_, statErr := os.Stat(filepath.Join(projectPath, ".cw-settings"))
+		assert.True(t, os.IsNotExist(statErr))
+	})
+
+	t.Run("fail case - the bind fails, so the files written are removed", func(t *testing.T) {
+		projectPath, _ := ioutil.TempDir("", "import")
+		defer os.RemoveAll(projectPath)
+		ioutil.WriteFile(filepath.Join(projectPath, ".cw-settings"), []byte(`{"contextRoot": "/v2"}`), 0644)
+		bindProjectTo = func(projectPath string, name string, language string, projectType string, conID string) (*BindResponse, *ProjectError) {
+			err := errors.New(textDupName)
+			return nil, &ProjectError{errOpResponse, err, textDupName}
+		}
+		defer func() { bindProjectTo = originalBind }()
+
+		mockClient := &clientMockExport{projects: `[]`}
+		_, err := importProject(mockClient, target, export, projectPath, "api")
+		assert.NotNil(t, err)
+		_, statErr := os.Stat(filepath.Join(projectPath, ".cw-refpaths.json"))
+		assert.True(t, os.IsNotExist(statErr))
+		settings, _ := ioutil.ReadFile(filepath.Join(projectPath, ".cw-settings"))
+		assert.Equal(t, `{"contextRoot": "/v2"}`, string(settings))
+	})
+
+	t.Run("fail case - a link can't be recreated, so the project is unbound and the files written are removed", func(t *testing.T) {
+		projectPath, _ := ioutil.TempDir("", "import")
+		defer os.RemoveAll(projectPath)
+		bindProjectTo = bindNewID
+
+		mockClient := &clientMockExport{projects: `[{"projectID": "dbID", "name": "db"}]`, linkStatus: http.StatusInternalServerError}
+		_, err := importProject(mockClient, target, export, projectPath, "api")
+		assert.Equal(t, errOpImport, err.Op)
+		assert.Contains(t, mockClient.calls, "POST /api/v1/projects/newID/unbind")
+		for _, file := range []string{".cw-settings", ".cw-refpaths.json"} {
+			_, statErr := os.Stat(filepath.Join(projectPath, file))
+			assert.True(t, os.IsNotExist(statErr), "%s was left in the checkout", file)
+		}
+	})
+}
+
+func TestLoadProjectExport(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "export")
+	defer os.RemoveAll(dir)
+
+	tests := map[string]struct {
+		content   string
+		wantErrOp string
+	}{
+		"success case - a current export": {
+			content: `{"version": 1, "name": "api", "language": "java", "projectType": "liberty", "links": []}`,
+		},
+		"fail case - a newer export": {
+			content:   `{"version": 2, "name": "api", "language": "java", "projectType": "liberty"}`,
+			wantErrOp: errOpFileParse,
+		},
+		"fail case - an export without a project type": {
+			content:   `{"version": 1, "name": "api", "language": "java"}`,
+			wantErrOp: errOpFileParse,
+		},
+		"fail case - not JSON": {
+			content:   `version: 1`,
+			wantErrOp: errOpFileParse,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(dir, "export.json")
+			ioutil.WriteFile(filename, []byte(test.content), 0644)
+			export, err := LoadProjectExport(filename)
+			if test.wantErrOp != "" {
+				assert.Equal(t, test.wantErrOp, err.Op)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, "api", export.Name)
+			}
+		})
+	}
+}
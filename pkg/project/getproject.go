@@ -17,9 +17,23 @@ type (
 		ProjectID      string `json:"projectID"`
 		Name           string `json:"name"`
 		Language       string `json:"language"`
+		ProjectType    string `json:"projectType"`
 		Host           string `json:"host"`
 		LocationOnDisk string `json:"locOnDisk"`
 		AppStatus      string `json:"appStatus"`
+		BuildStatus    string `json:"buildStatus"`
+		StartMode      string `json:"startMode"`
+		ContainerID    string `json:"containerId,omitempty"`
+		PodName        string `json:"podName,omitempty"`
+		Ports          Ports  `json:"ports"`
+	}
+
+	// Ports : The ports a project's application and debugger listen on, inside and outside its container
+	Ports struct {
+		ExposedPort       string `json:"exposedPort,omitempty"`
+		InternalPort      string `json:"internalPort,omitempty"`
+		ExposedDebugPort  string `json:"exposedDebugPort,omitempty"`
+		InternalDebugPort string `json:"internalDebugPort,omitempty"`
 	}
 )
 
@@ -0,0 +1,261 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type (
+	// IgnoredPath is a path in a project that is not synced, and the rule that excludes it
+	IgnoredPath struct {
+		Path        string `json:"path"`
+		IsDirectory bool   `json:"isDirectory"`
+		Pattern     string `json:"pattern"`
+		Source      string `json:"source"`
+		Line        int    `json:"line,omitempty"`
+	}
+
+	// ignoreRule is a single pattern from an ignore file, compiled to match slash separated relative paths
+	ignoreRule struct {
+		pattern string // the pattern as written
+		source  string // the file the pattern came from
+		line    int    // the line of the source file, if it has lines
+		negate  bool   // the pattern started with ! so re-includes matching paths
+		dirOnly bool   // the pattern ended with / so only matches directories
+		regex   *regexp.Regexp
+	}
+
+	// ignoreMatcher decides which paths in a project are ignored, using .gitignore semantics.
+	// Later rules override earlier ones
+	ignoreMatcher struct {
+		rules []ignoreRule
+	}
+)
+
+const (
+	ignoreSourceSettings  = ".cw-settings"
+	ignoreSourceGitignore = ".gitignore"
+	ignoreSourceDocker    = ".dockerignore"
+	ignoreSourceRefPaths  = ".cw-refpaths.json"
+)
+
+// loadIgnoreMatcher : Build the matcher for a project from the ignoredPaths in its .cw-settings,
+// preceded by the patterns in its .gitignore and .dockerignore if .cw-settings enables them
+func loadIgnoreMatcher(projectPath string) *ignoreMatcher {
+	matcher := &ignoreMatcher{}
+	cwSettings, _ := readCWSettings(projectPath)
+	if cwSettings.UseGitignore {
+		matcher.addIgnoreFile(filepath.Join(projectPath, ignoreSourceGitignore), ignoreSourceGitignore, false)
+	}
+	if cwSettings.UseDockerignore {
+		// .dockerignore patterns are always relative to the root of the build context
+		matcher.addIgnoreFile(filepath.Join(projectPath, ignoreSourceDocker), ignoreSourceDocker, true)
+	}
+	for _, pattern := range cwSettings.IgnoredPaths {
+		matcher.addPattern(pattern, ignoreSourceSettings, 0, false)
+	}
+	return matcher
+}
+
+// withRefPaths : Copy the matcher, also ignoring the files in the project that are
+// replaced by referenced files
+func (matcher *ignoreMatcher) withRefPaths(refPaths []refPath) *ignoreMatcher {
+	combined := &ignoreMatcher{append([]ignoreRule{}, matcher.rules...)}
+	for _, refPath := range refPaths {
+		to := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(refPath.To)), "/")
+		combined.rules = append(combined.rules, ignoreRule{
+			pattern: refPath.To,
+			source:  ignoreSourceRefPaths,
+			regex:   regexp.MustCompile("^" + regexp.QuoteMeta(to) + "$"),
+		})
+	}
+	return combined
+}
+
+// addIgnoreFile : Add the patterns in an ignore file, skipping blank lines and comments
+func (matcher *ignoreMatcher) addIgnoreFile(filename string, source string, anchored bool) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		matcher.addPattern(text, source, line, anchored)
+	}
+}
+
+// addPattern : Compile a pattern and add it to the matcher. Invalid patterns are skipped
+func (matcher *ignoreMatcher) addPattern(pattern string, source string, line int, anchored bool) {
+	rule, err := compileIgnoreRule(pattern, anchored)
+	if err != nil {
+		return
+	}
+	rule.source = source
+	rule.line = line
+	matcher.rules = append(matcher.rules, *rule)
+}
+
+// match : Find the rule that ignores a path, or nil if the path is not ignored.
+// A path is also ignored if any directory containing it is ignored
+func (matcher *ignoreMatcher) match(relativePath string, isDir bool) *ignoreRule {
+	segments := strings.Split(relativePath, "/")
+	for i := 1; i < len(segments); i++ {
+		rule := matcher.lastMatch(strings.Join(segments[:i], "/"), true)
+		if rule != nil && !rule.negate {
+			return rule
+		}
+	}
+	rule := matcher.lastMatch(relativePath, isDir)
+	if rule == nil || rule.negate {
+		return nil
+	}
+	return rule
+}
+
+// lastMatch : Find the last rule matching a path
+func (matcher *ignoreMatcher) lastMatch(relativePath string, isDir bool) *ignoreRule {
+	for i := len(matcher.rules) - 1; i >= 0; i-- {
+		rule := &matcher.rules[i]
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.regex.MatchString(relativePath) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// compileIgnoreRule : Convert a .gitignore pattern to a regular expression. Patterns containing a
+// slash, other than a trailing one, are anchored to the project root; other patterns match at any depth
+func compileIgnoreRule(pattern string, anchored bool) (*ignoreRule, error) {
+	rule := ignoreRule{pattern: pattern}
+
+	// trailing spaces are ignored unless escaped
+	for strings.HasSuffix(pattern, " ") && !strings.HasSuffix(pattern, "\\ ") {
+		pattern = pattern[:len(pattern)-1]
+	}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if strings.HasPrefix(pattern, "/") {
+		anchored = true
+		pattern = pattern[1:]
+	}
+	if strings.Contains(pattern, "/") {
+		anchored = true
+	}
+	if pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/") && (i == 0 || pattern[i-1] == '/'):
+			// leading or middle **/ matches zero or more directories
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**") && i+2 == len(pattern) && (i == 0 || pattern[i-1] == '/'):
+			// trailing /** matches everything inside
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case c == '[':
+			end := strings.Index(pattern[i+1:], "]")
+			if end < 0 {
+				expr.WriteString("\\[")
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.Replace(class, "\\", "\\\\", -1) + "]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+
+	regex, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, err
+	}
+	rule.regex = regex
+	return &rule, nil
+}
+
+// ListIgnoredPaths : List the files and directories in a project that sync skips, with the rule
+// that skips each of them. The contents of ignored directories are not listed
+func ListIgnoredPaths(projectPath string) ([]IgnoredPath, *ProjectError) {
+	projectPath = filepath.Clean(projectPath)
+	info, err := os.Stat(projectPath)
+	if err != nil || !info.IsDir() {
+		text := fmt.Sprintf("%v: %v", textProjectPathDoesNotExist, projectPath)
+		return nil, &ProjectError{errBadPath, errors.New(text), text}
+	}
+
+	matcher := loadIgnoreMatcher(projectPath).withRefPaths(retrieveRefPathsList(projectPath))
+	ignored := []IgnoredPath{}
+	err = walkPath(projectPath, projectPath, symlinkPolicy(projectPath), func(path string, realPath string, info os.FileInfo, err error) error {
+		if err != nil || path == projectPath {
+			return nil
+		}
+		relativePath := filepath.ToSlash(path[(len(projectPath) + 1):])
+		rule := matcher.match(relativePath, info.IsDir())
+		if rule == nil {
+			return nil
+		}
+		ignored = append(ignored, IgnoredPath{relativePath, info.IsDir(), rule.pattern, rule.source, rule.line})
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	return ignored, nil
+}
@@ -0,0 +1,282 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	tests := map[string]struct {
+		patterns        []string
+		path            string
+		isDir           bool
+		shouldBeIgnored bool
+	}{
+		"unanchored pattern matches at any depth": {
+			patterns:        []string{"*.swp"},
+			path:            "src/main/file.swp",
+			shouldBeIgnored: true,
+		},
+		"leading slash anchors pattern to the project root": {
+			patterns:        []string{"/build"},
+			path:            "src/build",
+			isDir:           true,
+			shouldBeIgnored: false,
+		},
+		"anchored pattern matches at the project root": {
+			patterns:        []string{"/build"},
+			path:            "build",
+			isDir:           true,
+			shouldBeIgnored: true,
+		},
+		"pattern with a middle slash is anchored": {
+			patterns:        []string{"src/generated"},
+			path:            "lib/src/generated",
+			isDir:           true,
+			shouldBeIgnored: false,
+		},
+		"trailing slash only matches directories": {
+			patterns:        []string{"logs/"},
+			path:            "logs",
+			isDir:           false,
+			shouldBeIgnored: false,
+		},
+		"trailing slash matches a directory": {
+			patterns:        []string{"logs/"},
+			path:            "app/logs",
+			isDir:           true,
+			shouldBeIgnored: true,
+		},
+		"leading ** matches in any directory": {
+			patterns:        []string{"**/target"},
+			path:            "a/b/target",
+			isDir:           true,
+			shouldBeIgnored: true,
+		},
+		"middle ** matches zero directories": {
+			patterns:        []string{"src/**/test.js"},
+			path:            "src/test.js",
+			shouldBeIgnored: true,
+		},
+		"middle ** matches many directories": {
+			patterns:        []string{"src/**/test.js"},
+			path:            "src/a/b/test.js",
+			shouldBeIgnored: true,
+		},
+		"trailing ** matches everything inside a directory": {
+			patterns:        []string{"dist/**"},
+			path:            "dist/js/app.js",
+			shouldBeIgnored: true,
+		},
+		"negation re-includes a file": {
+			patterns:        []string{"*.log", "!keep.log"},
+			path:            "keep.log",
+			shouldBeIgnored: false,
+		},
+		"later rule overrides negation": {
+			patterns:        []string{"*.log", "!keep.log", "keep.log"},
+			path:            "keep.log",
+			shouldBeIgnored: true,
+		},
+		"negation cannot re-include a file in an ignored directory": {
+			patterns:        []string{"node_modules", "!node_modules/keep.js"},
+			path:            "node_modules/keep.js",
+			shouldBeIgnored: true,
+		},
+		"character class matches": {
+			patterns:        []string{"file[0-9].txt"},
+			path:            "file3.txt",
+			shouldBeIgnored: true,
+		},
+		"negated character class": {
+			patterns:        []string{"file[!0-9].txt"},
+			path:            "file3.txt",
+			shouldBeIgnored: false,
+		},
+		"question mark does not match a slash": {
+			patterns:        []string{"a?b"},
+			path:            "a/b",
+			shouldBeIgnored: false,
+		},
+		"escaped characters match literally": {
+			patterns:        []string{"\\!important"},
+			path:            "!important",
+			shouldBeIgnored: true,
+		},
+		"directory called node_modules is ignored by a trailing wildcard": {
+			patterns:        []string{"node_modules*"},
+			path:            "node_modules",
+			isDir:           true,
+			shouldBeIgnored: true,
+		},
+		"directory called load-test-23498729 is ignored by a prefix": {
+			patterns:        []string{"load-test*"},
+			path:            "load-test-23498729",
+			isDir:           true,
+			shouldBeIgnored: true,
+		},
+		"directory called not-a-load-test-23498729 is not ignored by a prefix": {
+			patterns:        []string{"load-test*"},
+			path:            "not-a-load-test-23498729",
+			isDir:           true,
+			shouldBeIgnored: false,
+		},
+		"directory called noddy_modules is not ignored by node_modules": {
+			patterns:        []string{"node_modules"},
+			path:            "noddy_modules",
+			isDir:           true,
+			shouldBeIgnored: false,
+		},
+		"file called .DS_Store is ignored": {
+			patterns:        []string{".DS_Store"},
+			path:            ".DS_Store",
+			shouldBeIgnored: true,
+		},
+		"file called something.swp is ignored by *.swp": {
+			patterns:        []string{"*.swp"},
+			path:            "something.swp",
+			shouldBeIgnored: true,
+		},
+		"file called something.swpnot is not ignored by *.swp": {
+			patterns:        []string{"*.swp"},
+			path:            "something.swpnot",
+			shouldBeIgnored: false,
+		},
+		"file called node_modules is not ignored by node_modules/": {
+			patterns:        []string{"node_modules/"},
+			path:            "node_modules",
+			shouldBeIgnored: false,
+		},
+		"directory called noddy_modules is ignored by its name": {
+			patterns:        []string{"noddy_modules"},
+			path:            "noddy_modules",
+			isDir:           true,
+			shouldBeIgnored: true,
+		},
+		"file called file.iml is ignored by *.iml (IntelliJ metadata file)": {
+			patterns:        []string{"*.iml"},
+			path:            "file.iml",
+			shouldBeIgnored: true,
+		},
+		"directory called .idea is ignored by its name (IntelliJ metadata directory)": {
+			patterns:        []string{".idea"},
+			path:            ".idea",
+			isDir:           true,
+			shouldBeIgnored: true,
+		},
+		"invalid pattern is skipped": {
+			patterns:        []string{"[z-a]"},
+			path:            "z",
+			shouldBeIgnored: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			matcher := &ignoreMatcher{}
+			for _, pattern := range test.patterns {
+				matcher.addPattern(pattern, ignoreSourceSettings, 0, false)
+			}
+			ignored := matcher.match(test.path, test.isDir) != nil
+			assert.Equal(t, test.shouldBeIgnored, ignored)
+		})
+	}
+}
+
+func TestLoadIgnoreMatcher(t *testing.T) {
+	testDir := filepath.Join(".", "testDir", "ignore")
+	defer os.RemoveAll(filepath.Join(".", "testDir"))
+	os.MkdirAll(filepath.Join(testDir, "node_modules"), 0777)
+	os.MkdirAll(filepath.Join(testDir, "src"), 0777)
+	ioutil.WriteFile(filepath.Join(testDir, ".gitignore"), []byte("# comment\nnode_modules/\n*.log\n"), 0644)
+	ioutil.WriteFile(filepath.Join(testDir, ".dockerignore"), []byte("Dockerfile\n"), 0644)
+	ioutil.WriteFile(filepath.Join(testDir, "node_modules", "dep.js"), []byte{}, 0644)
+	ioutil.WriteFile(filepath.Join(testDir, "src", "Dockerfile"), []byte{}, 0644)
+	ioutil.WriteFile(filepath.Join(testDir, "src", "app.log"), []byte{}, 0644)
+	ioutil.WriteFile(filepath.Join(testDir, "src", "keep.log"), []byte{}, 0644)
+	ioutil.WriteFile(filepath.Join(testDir, "Dockerfile"), []byte{}, 0644)
+
+	t.Run("success case - ignore files are not used unless enabled", func(t *testing.T) {
+		ioutil.WriteFile(filepath.Join(testDir, ".cw-settings"), []byte(`{"ignoredPaths": ["*.log"]}`), 0644)
+		matcher := loadIgnoreMatcher(testDir)
+		assert.Nil(t, matcher.match("node_modules", true))
+		assert.NotNil(t, matcher.match("src/app.log", false))
+	})
+
+	t.Run("success case - .gitignore and .dockerignore are used when enabled", func(t *testing.T) {
+		ioutil.WriteFile(filepath.Join(testDir, ".cw-settings"), []byte(`{"ignoredPaths": ["!keep.log"], "useGitignore": true, "useDockerignore": true}`), 0644)
+		got, err := ListIgnoredPaths(testDir)
+		assert.Nil(t, err)
+		want := []IgnoredPath{
+			{Path: "Dockerfile", Pattern: "Dockerfile", Source: ".dockerignore", Line: 1},
+			{Path: "node_modules", IsDirectory: true, Pattern: "node_modules/", Source: ".gitignore", Line: 2},
+			{Path: "src/app.log", Pattern: "*.log", Source: ".gitignore", Line: 3},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("fail case - project path does not exist", func(t *testing.T) {
+		_, err := ListIgnoredPaths(filepath.Join(testDir, "missing"))
+		assert.Equal(t, errBadPath, err.Op)
+	})
+}
+
+func TestLoadIgnoreMatcherIgnoredPaths(t *testing.T) {
+	testDir := filepath.Join(".", "testDir", "ignoredPaths")
+	defer os.RemoveAll(filepath.Join(".", "testDir"))
+	settings := map[string]string{
+		"populated":      `{"ignoredPaths": ["testfile", "anothertestfile"]}`,
+		"empty":          `{"ignoredPaths": []}`,
+		"noIgnoredPaths": `{"field1": ["Something", "Else"], "field2": "Something Else"}`,
+		"invalid":        `not json`,
+	}
+	for dir, content := range settings {
+		os.MkdirAll(filepath.Join(testDir, dir), 0777)
+		ioutil.WriteFile(filepath.Join(testDir, dir, ".cw-settings"), []byte(content), 0644)
+	}
+
+	tests := map[string]struct {
+		projectPath  string
+		wantPatterns []string
+	}{
+		"success case: the ignoredPaths in .cw-settings are used": {
+			projectPath:  filepath.Join(testDir, "populated"),
+			wantPatterns: []string{"testfile", "anothertestfile"},
+		},
+		"success case: an empty ignoredPaths list ignores nothing": {
+			projectPath: filepath.Join(testDir, "empty"),
+		},
+		"success case: a path that doesn't exist ignores nothing": {
+			projectPath: "pathdoesntexist",
+		},
+		"success case: a .cw-settings that isn't valid JSON ignores nothing": {
+			projectPath: filepath.Join(testDir, "invalid"),
+		},
+		"success case: a .cw-settings without ignoredPaths ignores nothing": {
+			projectPath: filepath.Join(testDir, "noIgnoredPaths"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var patterns []string
+			for _, rule := range loadIgnoreMatcher(test.projectPath).rules {
+				patterns = append(patterns, rule.pattern)
+			}
+			assert.Equal(t, test.wantPatterns, patterns)
+		})
+	}
+}
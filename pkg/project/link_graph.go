@@ -0,0 +1,248 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// LinkGraph : The projects on a connection and the links between them. An edge goes from the
+	// project with the link to the project it links to, which it depends on
+	LinkGraph struct {
+		Nodes    []LinkGraphNode `json:"nodes"`
+		Edges    []LinkGraphEdge `json:"edges"`
+		Cycles   [][]string      `json:"cycles"`
+		Dangling []LinkGraphEdge `json:"dangling"`
+	}
+
+	// LinkGraphNode : A project in the link graph
+	LinkGraphNode struct {
+		ProjectID string `json:"projectID"`
+		Name      string `json:"name"`
+	}
+
+	// LinkGraphEdge : A link from one project to another
+	LinkGraphEdge struct {
+		From       string `json:"from"`
+		To         string `json:"to"`
+		TargetName string `json:"targetName"`
+		EnvName    string `json:"envName"`
+	}
+)
+
+// GetLinkGraph : Get the links of every project on a connection as a graph
+func GetLinkGraph(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string) (*LinkGraph, *ProjectError) {
+	projects, projErr := GetAll(httpClient, conInfo, conURL)
+	if projErr != nil {
+		return nil, projErr
+	}
+	links := map[string][]Link{}
+	for _, project := range projects {
+		projectLinks, projErr := GetProjectLinks(httpClient, conInfo, conURL, project.ProjectID)
+		if projErr != nil {
+			return nil, projErr
+		}
+		links[project.ProjectID] = projectLinks
+	}
+	return buildLinkGraph(projects, links), nil
+}
+
+// buildLinkGraph : Build the graph of the links between projects, finding the cycles in it and the
+// links whose target project has been removed
+func buildLinkGraph(projects []Project, links map[string][]Link) *LinkGraph {
+	graph := &LinkGraph{Nodes: []LinkGraphNode{}, Edges: []LinkGraphEdge{}, Cycles: [][]string{}, Dangling: []LinkGraphEdge{}}
+	exists := map[string]bool{}
+	for _, project := range projects {
+		graph.Nodes = append(graph.Nodes, LinkGraphNode{project.ProjectID, project.Name})
+		exists[project.ProjectID] = true
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].Name < graph.Nodes[j].Name })
+
+	for _, node := range graph.Nodes {
+		for _, link := range links[node.ProjectID] {
+			edge := LinkGraphEdge{From: node.ProjectID, To: link.ProjectID, TargetName: link.ProjectName, EnvName: link.EnvName}
+			if exists[link.ProjectID] {
+				graph.Edges = append(graph.Edges, edge)
+			} else {
+				graph.Dangling = append(graph.Dangling, edge)
+			}
+		}
+	}
+	graph.Cycles = graph.findCycles()
+	return graph
+}
+
+// findCycles : Find the groups of projects that depend on each other, using Tarjan's algorithm
+// for strongly connected components. A project linking to itself is a cycle too
+func (graph *LinkGraph) findCycles() [][]string {
+	dependencies := graph.dependencies()
+	index := map[string]int{}
+	lowLink := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	cycles := [][]string{}
+
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = len(index)
+		lowLink[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+		for _, next := range dependencies[id] {
+			if _, visited := index[next]; !visited {
+				visit(next)
+				if lowLink[next] < lowLink[id] {
+					lowLink[id] = lowLink[next]
+				}
+			} else if onStack[next] && index[next] < lowLink[id] {
+				lowLink[id] = index[next]
+			}
+		}
+		if lowLink[id] != index[id] {
+			return
+		}
+		var component []string
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == id {
+				break
+			}
+		}
+		if len(component) > 1 || stringInSlice(id, dependencies[id]) {
+			cycles = append(cycles, graph.sortByName(component))
+		}
+	}
+	for _, node := range graph.Nodes {
+		if _, visited := index[node.ProjectID]; !visited {
+			visit(node.ProjectID)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return graph.name(cycles[i][0]) < graph.name(cycles[j][0]) })
+	return cycles
+}
+
+// DependentsInOrder : Get the projects that depend on a project, directly or through other projects,
+// ordered so that each comes after the projects it depends on. Projects in a cycle are ordered by name
+func (graph *LinkGraph) DependentsInOrder(projectID string) ([]string, *ProjectError) {
+	if graph.name(projectID) == "" {
+		err := errors.New(textInvalidProjectID)
+		return nil, &ProjectError{errOpNotFound, err, err.Error()}
+	}
+
+	// find the dependents by following the edges backwards
+	dependents := map[string][]string{}
+	for _, edge := range graph.Edges {
+		dependents[edge.To] = append(dependents[edge.To], edge.From)
+	}
+	included := map[string]bool{projectID: true}
+	queue := []string{projectID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[id] {
+			if !included[dependent] {
+				included[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	// count the unrestarted dependencies of each dependent, ignoring the project itself which is restarted first
+	waitingOn := map[string]int{}
+	for _, edge := range graph.Edges {
+		if included[edge.From] && included[edge.To] && edge.To != projectID && edge.From != edge.To {
+			waitingOn[edge.From]++
+		}
+	}
+	delete(included, projectID)
+
+	var ordered []string
+	for len(included) > 0 {
+		var ready []string
+		for id := range included {
+			if waitingOn[id] == 0 {
+				ready = append(ready, id)
+			}
+		}
+		if len(ready) == 0 {
+			// the remaining projects are in a cycle, so break it at the first by name
+			for id := range included {
+				ready = append(ready, id)
+			}
+			ready = graph.sortByName(ready)[:1]
+		}
+		for _, id := range graph.sortByName(ready) {
+			ordered = append(ordered, id)
+			delete(included, id)
+			for _, dependent := range dependents[id] {
+				if included[dependent] && dependent != id {
+					waitingOn[dependent]--
+				}
+			}
+		}
+	}
+	return ordered, nil
+}
+
+// DOT : Write the graph in the Graphviz DOT language. Links to removed projects are dashed
+func (graph *LinkGraph) DOT() string {
+	var dot strings.Builder
+	dot.WriteString("digraph links {\n")
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(&dot, "  %q [label=%q];\n", node.ProjectID, node.Name)
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&dot, "  %q -> %q [label=%q];\n", edge.From, edge.To, edge.EnvName)
+	}
+	for _, edge := range graph.Dangling {
+		label := edge.TargetName + " (removed)"
+		fmt.Fprintf(&dot, "  %q [label=%q, style=dashed];\n", edge.To, label)
+		fmt.Fprintf(&dot, "  %q -> %q [label=%q, style=dashed];\n", edge.From, edge.To, edge.EnvName)
+	}
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+// dependencies : Get the projects each project links to
+func (graph *LinkGraph) dependencies() map[string][]string {
+	dependencies := map[string][]string{}
+	for _, edge := range graph.Edges {
+		dependencies[edge.From] = append(dependencies[edge.From], edge.To)
+	}
+	return dependencies
+}
+
+// name : Get the name of a project in the graph, or an empty string if it isn't in the graph
+func (graph *LinkGraph) name(projectID string) string {
+	for _, node := range graph.Nodes {
+		if node.ProjectID == projectID {
+			return node.Name
+		}
+	}
+	return ""
+}
+
+// sortByName : Sort project IDs by the names of their projects
+func (graph *LinkGraph) sortByName(projectIDs []string) []string {
+	sort.Slice(projectIDs, func(i, j int) bool { return graph.name(projectIDs[i]) < graph.name(projectIDs[j]) })
+	return projectIDs
+}
@@ -0,0 +1,96 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkGraph(t *testing.T) {
+	// web -> api -> db, worker -> api, worker -> queue -> worker, admin -> (removed) auth
+	projects := []Project{
+		{ProjectID: "api", Name: "api"},
+		{ProjectID: "db", Name: "db"},
+		{ProjectID: "web", Name: "web"},
+		{ProjectID: "worker", Name: "worker"},
+		{ProjectID: "queue", Name: "queue"},
+		{ProjectID: "admin", Name: "admin"},
+	}
+	links := map[string][]Link{
+		"web":    {{ProjectID: "api", ProjectName: "api", EnvName: "API_URL"}},
+		"api":    {{ProjectID: "db", ProjectName: "db", EnvName: "DB_URL"}},
+		"worker": {{ProjectID: "api", ProjectName: "api", EnvName: "API_URL"}, {ProjectID: "queue", ProjectName: "queue", EnvName: "QUEUE_URL"}},
+		"queue":  {{ProjectID: "worker", ProjectName: "worker", EnvName: "WORKER_URL"}},
+		"admin":  {{ProjectID: "auth", ProjectName: "auth", EnvName: "AUTH_URL"}},
+	}
+	graph := buildLinkGraph(projects, links)
+
+	t.Run("nodes are sorted by name and links to removed projects are dangling", func(t *testing.T) {
+		assert.Equal(t, "admin", graph.Nodes[0].Name)
+		assert.Len(t, graph.Edges, 5)
+		assert.Equal(t, []LinkGraphEdge{{From: "admin", To: "auth", TargetName: "auth", EnvName: "AUTH_URL"}}, graph.Dangling)
+	})
+
+	t.Run("cycles are found", func(t *testing.T) {
+		assert.Equal(t, [][]string{{"queue", "worker"}}, graph.Cycles)
+	})
+
+	tests := map[string]struct {
+		projectID string
+		want      []string
+	}{
+		"dependents of a project at the bottom are ordered after their dependencies": {
+			projectID: "db",
+			want:      []string{"api", "web", "queue", "worker"},
+		},
+		"dependents in a cycle are ordered by name": {
+			projectID: "api",
+			want:      []string{"web", "queue", "worker"},
+		},
+		"a project with no dependents": {
+			projectID: "web",
+			want:      nil,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := graph.DependentsInOrder(test.projectID)
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	t.Run("fail case - a project that isn't in the graph", func(t *testing.T) {
+		_, err := graph.DependentsInOrder("auth")
+		assert.Equal(t, errOpNotFound, err.Op)
+	})
+
+	t.Run("a self link is a cycle", func(t *testing.T) {
+		selfGraph := buildLinkGraph([]Project{{ProjectID: "a", Name: "a"}}, map[string][]Link{"a": {{ProjectID: "a", ProjectName: "a", EnvName: "SELF"}}})
+		assert.Equal(t, [][]string{{"a"}}, selfGraph.Cycles)
+	})
+
+	t.Run("DOT output", func(t *testing.T) {
+		small := buildLinkGraph(projects[:3], map[string][]Link{"web": links["web"], "api": {{ProjectID: "gone", ProjectName: "gone", EnvName: "GONE_URL"}}})
+		assert.Equal(t, `digraph links {
+  "api" [label="api"];
+  "db" [label="db"];
+  "web" [label="web"];
+  "web" -> "api" [label="API_URL"];
+  "gone" [label="gone (removed)", style=dashed];
+  "api" -> "gone" [label="GONE_URL", style=dashed];
+}
+`, small.DOT())
+	})
+}
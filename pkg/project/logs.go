@@ -0,0 +1,306 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/docker"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// LogFile : A log PFE keeps for a project
+	LogFile struct {
+		LogName          string `json:"logName"`
+		WorkspaceLogPath string `json:"workspaceLogPath,omitempty"`
+	}
+
+	// ProjectLogs : The logs PFE keeps for a project, by type
+	ProjectLogs struct {
+		Build []LogFile `json:"build"`
+		App   []LogFile `json:"app"`
+	}
+
+	// LogOptions : How much of a log to show, and whether to keep showing it as it grows
+	LogOptions struct {
+		Type       string
+		Follow     bool
+		Timestamps bool
+		Tail       int // the number of lines to show from the end of the log, or all lines if 0
+	}
+)
+
+// The types of project log
+const (
+	LogTypeBuild = "build"
+	LogTypeApp   = "app"
+)
+
+// codewindProjectContainerPrefix : The prefix of the names PFE gives the containers of local projects
+const codewindProjectContainerPrefix = "cw-"
+
+// nonContainerNameChars : Characters PFE drops from a project's name when naming its container
+var nonContainerNameChars = regexp.MustCompile("[^a-z0-9]")
+
+// logPollInterval : How often PFE is asked for more of a followed log
+var logPollInterval = time.Second
+
+// StreamProjectLogs : Write a project's logs of the given type to out. The application logs of local projects
+// are read from the project's container, and other logs from PFE
+func StreamProjectLogs(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, options LogOptions, out io.Writer) *ProjectError {
+	if options.Type != LogTypeBuild && options.Type != LogTypeApp {
+		err := fmt.Errorf("log type must be %s or %s", LogTypeBuild, LogTypeApp)
+		return &ProjectError{errOpInvalidOptions, err, err.Error()}
+	}
+	if options.Type == LogTypeApp && conInfo.ID == "local" {
+		dockerClient, dockerErr := docker.NewDockerClient()
+		if dockerErr != nil {
+			return &ProjectError{errOpLogs, dockerErr.Err, dockerErr.Desc}
+		}
+		project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+		if projErr != nil {
+			return projErr
+		}
+		return streamContainerLogs(dockerClient, project, options, out)
+	}
+	return streamPFELogs(httpClient, conInfo, conURL, projectID, options, out)
+}
+
+// projectContainerName : The name PFE gives the container of a local project
+func projectContainerName(project *Project) string {
+	name := nonContainerNameChars.ReplaceAllString(strings.ToLower(project.Name), "")
+	return codewindProjectContainerPrefix + name + "-" + project.ProjectID
+}
+
+// projectContainerID : The container of a local project, as reported by PFE. Older PFEs don't report it,
+// so the container is then found by the name PFE gives it
+func projectContainerID(dockerClient docker.DockerClient, project *Project) (string, *ProjectError) {
+	if project.ContainerID != "" {
+		return project.ContainerID, nil
+	}
+	containerName := projectContainerName(project)
+	containers, dockerErr := docker.GetContainerListWithOptions(dockerClient, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("name", "^/"+containerName+"$")),
+	})
+	if dockerErr != nil {
+		return "", &ProjectError{errOpLogs, dockerErr.Err, dockerErr.Desc}
+	}
+	for _, container := range containers {
+		for _, name := range container.Names {
+			if strings.TrimPrefix(name, "/") == containerName {
+				return container.ID, nil
+			}
+		}
+	}
+	err := errors.New(textNoProjectContainer)
+	return "", &ProjectError{errOpNotFound, err, textNoProjectContainer}
+}
+
+// streamContainerLogs : Write the logs of a local project's container to out, with the times docker
+// recorded for each line if asked for
+func streamContainerLogs(dockerClient docker.DockerClient, project *Project, options LogOptions, out io.Writer) *ProjectError {
+	containerID, projErr := projectContainerID(dockerClient, project)
+	if projErr != nil {
+		return projErr
+	}
+
+	logOptions := types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Follow: options.Follow, Timestamps: options.Timestamps}
+	if options.Tail > 0 {
+		logOptions.Tail = strconv.Itoa(options.Tail)
+	}
+	logs, dockerErr := docker.GetContainerLogsWithOptions(dockerClient, containerID, logOptions)
+	if dockerErr != nil {
+		return &ProjectError{errOpLogs, dockerErr.Err, dockerErr.Desc}
+	}
+	defer logs.Close()
+
+	// the output of containers without a terminal has stdout and stderr multiplexed together
+	var err error
+	info, dockerErr := docker.InspectContainer(dockerClient, containerID)
+	if dockerErr == nil && info.Config != nil && info.Config.Tty {
+		_, err = io.Copy(out, logs)
+	} else {
+		_, err = stdcopy.StdCopy(out, out, logs)
+	}
+	if err != nil {
+		return &ProjectError{errOpLogs, err, err.Error()}
+	}
+	return nil
+}
+
+// GetProjectLogs : Get the logs PFE keeps for a project
+func GetProjectLogs(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string) (*ProjectLogs, *ProjectError) {
+	req, err := http.NewRequest("GET", conURL+"/api/v1/projects/"+projectID+"/logs", nil)
+	if err != nil {
+		return nil, &ProjectError{errOpRequest, err, err.Error()}
+	}
+	body, projErr := dispatchLogRequest(httpClient, conInfo, req)
+	if projErr != nil {
+		return nil, projErr
+	}
+	var logs ProjectLogs
+	err = json.Unmarshal(body, &logs)
+	if err != nil {
+		return nil, &ProjectError{errOpResponse, err, err.Error()}
+	}
+	return &logs, nil
+}
+
+// streamPFELogs : Write the logs PFE keeps for a project to out, with a heading for each if there are several.
+// When following, PFE is polled for the part of each log after the part already written. PFE doesn't record
+// when each line was written, so these logs are never timestamped
+func streamPFELogs(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, options LogOptions, out io.Writer) *ProjectError {
+	logs, projErr := GetProjectLogs(httpClient, conInfo, conURL, projectID)
+	if projErr != nil {
+		return projErr
+	}
+	logFiles := logs.App
+	if options.Type == LogTypeBuild {
+		logFiles = logs.Build
+	}
+	if len(logFiles) == 0 && !options.Follow {
+		err := fmt.Errorf("project has no %s logs", options.Type)
+		return &ProjectError{errOpNotFound, err, err.Error()}
+	}
+
+	offsets := map[string]int{}
+	lastHeading := ""
+	for {
+		for _, logFile := range logFiles {
+			offset := offsets[logFile.LogName]
+			content, projErr := getPFELogContent(httpClient, conInfo, conURL, projectID, options.Type, logFile.LogName, offset)
+			if projErr != nil {
+				return projErr
+			}
+			if len(content) == 0 {
+				continue
+			}
+			offsets[logFile.LogName] = offset + len(content)
+			if offset == 0 {
+				content = lastLines(content, options.Tail)
+			}
+			if len(logFiles) > 1 && lastHeading != logFile.LogName {
+				fmt.Fprintf(out, "==> %s <==\n", logFile.LogName)
+				lastHeading = logFile.LogName
+			}
+			out.Write(content)
+		}
+		if !options.Follow {
+			return nil
+		}
+		time.Sleep(logPollInterval)
+
+		// logs are created as the project is built and started, so look for new ones
+		if logs, projErr = GetProjectLogs(httpClient, conInfo, conURL, projectID); projErr == nil {
+			logFiles = logs.App
+			if options.Type == LogTypeBuild {
+				logFiles = logs.Build
+			}
+		}
+	}
+}
+
+// getPFELogContent : Get the content of a log after the given offset
+func getPFELogContent(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, logType string, logName string, offset int) ([]byte, *ProjectError) {
+	logURL := conURL + "/api/v1/projects/" + projectID + "/logs/" + logType + "/" + url.PathEscape(logName)
+	req, err := http.NewRequest("GET", logURL, nil)
+	if err != nil {
+		return nil, &ProjectError{errOpRequest, err, err.Error()}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, conInfo)
+	if httpSecError != nil {
+		return nil, &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// nothing has been added since the last request
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		err := fmt.Errorf("%s: %s", textUnknownResponseCode, resp.Status)
+		return nil, &ProjectError{errOpResponse, err, err.Error()}
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProjectError{errOpResponse, err, err.Error()}
+	}
+	// a server that ignores the range sends the whole log
+	if resp.StatusCode == http.StatusOK && offset > 0 {
+		if len(body) <= offset {
+			return nil, nil
+		}
+		body = body[offset:]
+	}
+	return body, nil
+}
+
+func dispatchLogRequest(httpClient utils.HTTPClient, conInfo *connections.Connection, req *http.Request) ([]byte, *ProjectError) {
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, conInfo)
+	if httpSecError != nil {
+		return nil, &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		err := errors.New(textAPINotFound)
+		return nil, &ProjectError{errOpNotFound, err, textAPINotFound}
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("%s: %s", textUnknownResponseCode, resp.Status)
+		return nil, &ProjectError{errOpResponse, err, err.Error()}
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProjectError{errOpResponse, err, err.Error()}
+	}
+	return body, nil
+}
+
+// lastLines : Get the last n lines of some text, or all of it if n is 0
+func lastLines(content []byte, n int) []byte {
+	if n <= 0 {
+		return content
+	}
+	end := len(content)
+	if end > 0 && content[end-1] == '\n' {
+		end--
+	}
+	for i := end - 1; i >= 0; i-- {
+		if content[i] == '\n' {
+			n--
+			if n == 0 {
+				return content[i+1:]
+			}
+		}
+	}
+	return content
+}
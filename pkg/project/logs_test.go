@@ -0,0 +1,190 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+// clientMockLogs serves the log list and log contents of a project, honouring range requests
+type clientMockLogs struct {
+	list     string
+	contents map[string]string
+}
+
+func (c *clientMockLogs) Do(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/logs") {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(c.list))}, nil
+	}
+	parts := strings.Split(req.URL.Path, "/")
+	content, ok := c.contents[parts[len(parts)-1]]
+	if !ok {
+		return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	}
+	status := http.StatusOK
+	if rangeHeader := req.Header.Get("Range"); rangeHeader != "" {
+		offset, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+		if offset >= len(content) {
+			return &http.Response{StatusCode: http.StatusRequestedRangeNotSatisfiable, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+		}
+		content = content[offset:]
+		status = http.StatusPartialContent
+	}
+	return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(content))}, nil
+}
+
+func TestStreamPFELogs(t *testing.T) {
+	oneLog := `{"build": [{"logName": "maven.build"}], "app": [{"logName": "app.log"}]}`
+	twoLogs := `{"build": [{"logName": "docker.build"}, {"logName": "maven.build"}], "app": []}`
+	contents := map[string]string{
+		"maven.build":  "compiling\ntesting\npackaging\n",
+		"docker.build": "step 1\nstep 2\n",
+		"app.log":      "listening\n",
+	}
+
+	tests := map[string]struct {
+		list      string
+		options   LogOptions
+		wantOut   string
+		wantErrOp string
+	}{
+		"success case - prints a whole log": {
+			list:    oneLog,
+			options: LogOptions{Type: LogTypeBuild},
+			wantOut: "compiling\ntesting\npackaging\n",
+		},
+		"success case - prints the end of a log": {
+			list:    oneLog,
+			options: LogOptions{Type: LogTypeBuild, Tail: 2},
+			wantOut: "testing\npackaging\n",
+		},
+		"success case - prints a heading for each of several logs": {
+			list:    twoLogs,
+			options: LogOptions{Type: LogTypeBuild, Tail: 1},
+			wantOut: "==> docker.build <==\nstep 2\n==> maven.build <==\npackaging\n",
+		},
+		"success case - prints application logs of a remote project": {
+			list:    oneLog,
+			options: LogOptions{Type: LogTypeApp},
+			wantOut: "listening\n",
+		},
+		"fail case - project has no logs of the type": {
+			list:      twoLogs,
+			options:   LogOptions{Type: LogTypeApp},
+			wantErrOp: errOpNotFound,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockClient := &clientMockLogs{list: test.list, contents: contents}
+			var out bytes.Buffer
+			err := streamPFELogs(mockClient, &mockConnection, "http://mockURL", "mockID", test.options, &out)
+			if test.wantErrOp != "" {
+				assert.Equal(t, test.wantErrOp, err.Op)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.wantOut, out.String())
+		})
+	}
+}
+
+func TestGetPFELogContent(t *testing.T) {
+	mockClient := &clientMockLogs{contents: map[string]string{"app.log": "first\nsecond\n"}}
+	tests := map[string]struct {
+		offset int
+		want   string
+	}{
+		"success case - gets the whole log": {
+			offset: 0,
+			want:   "first\nsecond\n",
+		},
+		"success case - gets the part of the log after the offset": {
+			offset: 6,
+			want:   "second\n",
+		},
+		"success case - gets nothing when the log hasn't grown": {
+			offset: 13,
+			want:   "",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			content, err := getPFELogContent(mockClient, &mockConnection, "http://mockURL", "mockID", LogTypeApp, "app.log", test.offset)
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, string(content))
+		})
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		n       int
+		want    string
+	}{
+		"all lines when n is 0":          {"a\nb\nc\n", 0, "a\nb\nc\n"},
+		"last lines":                     {"a\nb\nc\n", 2, "b\nc\n"},
+		"last lines without end newline": {"a\nb\nc", 2, "b\nc"},
+		"all lines when there are fewer": {"a\nb\n", 5, "a\nb\n"},
+		"nothing when the log is empty":  {"", 3, ""},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, string(lastLines([]byte(test.content), test.n)))
+		})
+	}
+}
+
+func TestProjectContainerName(t *testing.T) {
+	tests := map[string]struct {
+		project Project
+		want    string
+	}{
+		"success case - name is used as it is": {
+			project: Project{ProjectID: "a1b2", Name: "nodeproject"},
+			want:    "cw-nodeproject-a1b2",
+		},
+		"success case - name is lowercased and stripped of other characters": {
+			project: Project{ProjectID: "a1b2", Name: "My_Node-Project"},
+			want:    "cw-mynodeproject-a1b2",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, projectContainerName(&test.project))
+		})
+	}
+}
+
+func TestProjectContainerID(t *testing.T) {
+	t.Run("success case - the container PFE reports is used without listing containers", func(t *testing.T) {
+		project := Project{ProjectID: "a1b2", Name: "nodeproject", ContainerID: "abc123"}
+		got, err := projectContainerID(&docker.MockDockerErrorClient{}, &project)
+		assert.Nil(t, err)
+		assert.Equal(t, "abc123", got)
+	})
+
+	t.Run("fail case - without a container from PFE, no container has the project's name", func(t *testing.T) {
+		project := Project{ProjectID: "a1b2", Name: "nodeproject"}
+		_, err := projectContainerID(&docker.MockDockerClientWithCw{}, &project)
+		assert.Equal(t, errOpNotFound, err.Op)
+	})
+}
@@ -0,0 +1,188 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// MoveResult : Where a project was moved, its ID on the connection it was moved to, and what happened to its links
+	MoveResult struct {
+		OldProjectID string          `json:"oldProjectID"`
+		ProjectID    string          `json:"projectID"`
+		From         string          `json:"from"`
+		To           string          `json:"to"`
+		Links        []RecreatedLink `json:"links"`
+	}
+
+	// RecreatedLink : A link of a project bound to another connection, which is recreated if its target
+	// project is on that connection too
+	RecreatedLink struct {
+		EnvName    string `json:"envName"`
+		TargetName string `json:"targetName"`
+		Status     string `json:"status"`
+	}
+
+	// moveEndpoint : A connection a project is moved from or to, or imported to
+	moveEndpoint struct {
+		conInfo *connections.Connection
+		url     string
+	}
+)
+
+// The statuses of recreated links
+const (
+	LinkRecreated = "created"
+	LinkSkipped   = "skipped" // the target project isn't on the connection
+)
+
+// bindProjectTo : Binds a project on the connection it is moved or imported to, and fully syncs it
+var bindProjectTo = Bind
+
+// MoveProject : Move a project to another connection, keeping its name, language and type and recreating
+// its links to projects with the same names there. The project is bound on the new connection before it
+// is unbound from the old one, so that if any step fails the project is unbound from the new connection
+// and left as it was
+func MoveProject(projectID string, toConID string) (*MoveResult, *ProjectError) {
+	fromConID, projErr := GetConnectionID(projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	if fromConID == toConID {
+		err := fmt.Errorf("project is already on connection %s", toConID)
+		return nil, &ProjectError{errOpInvalidOptions, err, err.Error()}
+	}
+	source, projErr := getMoveEndpoint(fromConID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	target, projErr := getMoveEndpoint(toConID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	return moveProject(http.DefaultClient, source, target, projectID)
+}
+
+func getMoveEndpoint(conID string) (*moveEndpoint, *ProjectError) {
+	conInfo, conInfoErr := connections.GetConnectionByID(conID)
+	if conInfoErr != nil {
+		return nil, &ProjectError{errOpConNotFound, conInfoErr.Err, conInfoErr.Desc}
+	}
+	conURL, conURLErr := config.PFEOriginFromConnection(conInfo)
+	if conURLErr != nil {
+		return nil, &ProjectError{errOpConNotFound, conURLErr.Err, conURLErr.Desc}
+	}
+	return &moveEndpoint{conInfo, conURL}, nil
+}
+
+func moveProject(httpClient utils.HTTPClient, source *moveEndpoint, target *moveEndpoint, projectID string) (*MoveResult, *ProjectError) {
+	project, projErr := GetProjectFromID(httpClient, source.conInfo, source.url, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	links, projErr := GetProjectLinks(httpClient, source.conInfo, source.url, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	targetProjects, projErr := GetAll(httpClient, target.conInfo, target.url)
+	if projErr != nil {
+		return nil, projErr
+	}
+	for _, targetProject := range targetProjects {
+		if targetProject.Name == project.Name {
+			err := fmt.Errorf("a project named %s is already bound to connection %s", project.Name, target.conInfo.ID)
+			return nil, &ProjectError{errOpConflict, err, err.Error()}
+		}
+	}
+
+	newProjectID, projErr := bindToEndpoint(httpClient, target, project.LocationOnDisk, project.Name, project.Language, project.ProjectType, errOpMove)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	result := &MoveResult{OldProjectID: projectID, ProjectID: newProjectID, From: source.conInfo.ID, To: target.conInfo.ID}
+	result.Links, projErr = recreateLinks(httpClient, target, newProjectID, links, targetProjects)
+	if projErr != nil {
+		return nil, rollbackBind(httpClient, target, newProjectID, errOpMove, projErr)
+	}
+
+	projErr = Unbind(httpClient, source.conInfo, source.url, projectID)
+	if projErr != nil {
+		return nil, rollbackBind(httpClient, target, newProjectID, errOpMove, projErr)
+	}
+	// ignore errors, as the files may not exist
+	RemoveConnectionFile(projectID)
+	RemoveSyncManifest(projectID)
+	return result, nil
+}
+
+// bindToEndpoint : Bind and fully sync a project on the connection it is moved or imported to, returning its
+// ID there. If the bind doesn't complete, the project is unbound again
+func bindToEndpoint(httpClient utils.HTTPClient, target *moveEndpoint, projectPath string, name string, language string, projectType string, op string) (string, *ProjectError) {
+	response, projErr := bindProjectTo(projectPath, name, language, projectType, target.conInfo.ID)
+	if response == nil || response.ProjectID == "" {
+		if projErr == nil {
+			err := errors.New("bind did not return a project ID")
+			projErr = &ProjectError{errOpBind, err, err.Error()}
+		}
+		return "", projErr
+	}
+	if projErr == nil && response.StatusCode != http.StatusOK && response.StatusCode != http.StatusAccepted {
+		err := fmt.Errorf("bind failed to complete: %s", response.Status)
+		projErr = &ProjectError{errOpBind, err, err.Error()}
+	}
+	if projErr != nil {
+		return "", rollbackBind(httpClient, target, response.ProjectID, op, projErr)
+	}
+	return response.ProjectID, nil
+}
+
+// recreateLinks : Create the links of a project that was bound to another connection, to the projects on that
+// connection with the same names as their targets. Links whose target isn't on the connection are skipped
+func recreateLinks(httpClient utils.HTTPClient, target *moveEndpoint, projectID string, links []Link, targetProjects []Project) ([]RecreatedLink, *ProjectError) {
+	recreated := []RecreatedLink{}
+	for _, link := range links {
+		recreatedLink := RecreatedLink{EnvName: link.EnvName, TargetName: link.ProjectName, Status: LinkSkipped}
+		for _, targetProject := range targetProjects {
+			if targetProject.Name != link.ProjectName {
+				continue
+			}
+			projErr := CreateProjectLink(httpClient, target.conInfo, target.url, projectID, targetProject.ProjectID, link.EnvName)
+			if projErr != nil {
+				return nil, projErr
+			}
+			recreatedLink.Status = LinkRecreated
+			break
+		}
+		recreated = append(recreated, recreatedLink)
+	}
+	return recreated, nil
+}
+
+// rollbackBind : Unbind a project from the connection a move or import bound it to, returning the error
+// that stopped the move or import, and any error unbinding the project
+func rollbackBind(httpClient utils.HTTPClient, target *moveEndpoint, newProjectID string, op string, cause *ProjectError) *ProjectError {
+	RemoveSyncManifest(newProjectID)
+	if projErr := Unbind(httpClient, target.conInfo, target.url, newProjectID); projErr != nil {
+		err := fmt.Errorf("%s, and unbinding the project from %s failed: %s", cause.Desc, target.conInfo.ID, projErr.Desc)
+		return &ProjectError{op, err, err.Error()}
+	}
+	err := fmt.Errorf("%s, so the project was unbound from %s", cause.Desc, target.conInfo.ID)
+	return &ProjectError{op, err, err.Error()}
+}
@@ -0,0 +1,115 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+// clientMockMove serves a project with links on the source connection and the projects on the target,
+// recording the requests that change anything
+type clientMockMove struct {
+	targetProjects string
+	linkStatus     int
+	unbindStatus   int
+	calls          []string
+}
+
+func (c *clientMockMove) Do(req *http.Request) (*http.Response, error) {
+	call := req.Method + " " + req.URL.Host + req.URL.Path
+	status, body := http.StatusOK, ""
+	switch {
+	case call == "GET source/api/v1/projects/oldID/":
+		body = `{"projectID": "oldID", "name": "api", "language": "java", "projectType": "liberty", "locOnDisk": "/projects/api"}`
+	case call == "GET source/api/v1/projects/oldID/links":
+		body = `[{"projectID": "dbID", "projectName": "db", "envName": "DB_URL"}, {"projectID": "cacheID", "projectName": "cache", "envName": "CACHE_URL"}]`
+	case call == "GET target/api/v1/projects/":
+		body = c.targetProjects
+	case strings.HasSuffix(call, "/links"):
+		c.calls = append(c.calls, call)
+		status = c.linkStatus
+	case strings.HasSuffix(call, "/unbind"):
+		c.calls = append(c.calls, call)
+		status = c.unbindStatus
+	}
+	return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+}
+
+func TestMoveProject(t *testing.T) {
+	// both connections are local so requests to them don't need credentials, and are told apart by URL
+	source := &moveEndpoint{&connections.Connection{ID: "local"}, "http://source"}
+	target := &moveEndpoint{&connections.Connection{ID: "local"}, "http://target"}
+	originalBind := bindProjectTo
+	defer func() { bindProjectTo = originalBind }()
+
+	tests := map[string]struct {
+		targetProjects string
+		bindErr        *ProjectError
+		linkStatus     int
+		wantLinks      []RecreatedLink
+		wantCalls      []string
+		wantErrOp      string
+	}{
+		"success case - links are recreated where their targets are on the new connection": {
+			targetProjects: `[{"projectID": "newDbID", "name": "db"}]`,
+			linkStatus:     http.StatusAccepted,
+			wantLinks: []RecreatedLink{
+				{EnvName: "DB_URL", TargetName: "db", Status: LinkRecreated},
+				{EnvName: "CACHE_URL", TargetName: "cache", Status: LinkSkipped},
+			},
+			wantCalls: []string{"POST target/api/v1/projects/newID/links", "POST source/api/v1/projects/oldID/unbind"},
+		},
+		"fail case - a project with the same name on the new connection stops the move": {
+			targetProjects: `[{"projectID": "otherID", "name": "api"}]`,
+			wantErrOp:      errOpConflict,
+		},
+		"fail case - a failed sync unbinds the project from the new connection": {
+			targetProjects: `[]`,
+			bindErr:        &ProjectError{errOpSync, nil, "1 files failed to upload"},
+			wantCalls:      []string{"POST target/api/v1/projects/newID/unbind"},
+			wantErrOp:      errOpMove,
+		},
+		"fail case - a failed link unbinds the project from the new connection": {
+			targetProjects: `[{"projectID": "newDbID", "name": "db"}]`,
+			linkStatus:     http.StatusConflict,
+			wantCalls:      []string{"POST target/api/v1/projects/newID/links", "POST target/api/v1/projects/newID/unbind"},
+			wantErrOp:      errOpMove,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var bound []string
+			bindProjectTo = func(projectPath string, name string, language string, projectType string, conID string) (*BindResponse, *ProjectError) {
+				bound = []string{projectPath, name, language, projectType, conID}
+				return &BindResponse{ProjectID: "newID", Status: "202 Accepted", StatusCode: http.StatusAccepted}, test.bindErr
+			}
+			mockClient := &clientMockMove{targetProjects: test.targetProjects, linkStatus: test.linkStatus, unbindStatus: http.StatusAccepted}
+			result, err := moveProject(mockClient, source, target, "oldID")
+			if test.wantErrOp != "" {
+				assert.Equal(t, test.wantErrOp, err.Op)
+				assert.Nil(t, result)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, "newID", result.ProjectID)
+				assert.Equal(t, test.wantLinks, result.Links)
+				assert.Equal(t, []string{"/projects/api", "api", "java", "liberty", "local"}, bound)
+			}
+			assert.Equal(t, test.wantCalls, mockClient.calls)
+		})
+	}
+}
@@ -45,6 +45,11 @@ const (
 	errOpInvalidOptions     = "proj_options_invalid"
 	errOpSync               = "proj_sync"
 	errOpSyncRef            = "proj_sync_ref"
+	errOpWatch              = "proj_watch"
+	errOpWait               = "proj_wait"
+	errOpLogs               = "proj_logs"
+	errOpMove               = "proj_move"
+	errOpImport             = "proj_import"
 	errOpWriteCwSettings    = "proj_write_cw_settings"
 	errOpInvalidCredentials = "invalid_git_credentials"
 )
@@ -66,6 +71,11 @@ const (
 	textProjectLinkUnknownNotFound = "unknown 404 returned from Codewind server"
 	textProjectLinkConflict        = "project link env is already in use"
 	textInvalidRequest             = "request parameters are invalid"
+	textBuildFailed                = "project build failed"
+	textWaitTimeout                = "timed out waiting for project to start"
+	textAppStopped                 = "project application stopped while starting"
+	textNoProjectContainer         = "unable to find the container of the project, it may not have been built yet"
+	textRestartDependentsNoWait    = "restarting a project with its dependents requires waiting for each project to start"
 )
 
 // ProjectError : Error formatted in JSON containing an errorOp and a description from
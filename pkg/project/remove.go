@@ -62,6 +62,9 @@ func RemoveProject(c *cli.Context) *ProjectError {
 	// We can ignore errors as we are no longer creating this file
 	RemoveConnectionFile(projectID)
 
+	// Delete the record of synced files, ignoring errors as the project may never have been synced
+	RemoveSyncManifest(projectID)
+
 	// Delete the source if the flag is set
 	if deleteFiles {
 		var err = os.RemoveAll(projectPath)
@@ -14,9 +14,11 @@ package project
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/sechttp"
@@ -28,8 +30,28 @@ type (
 	RestartParameters struct {
 		StartMode string `json:"startMode"`
 	}
+
+	// RestartResult : The outcome of restarting one of a group of projects
+	RestartResult struct {
+		ProjectID string `json:"projectID"`
+		Name      string `json:"name"`
+		StartMode string `json:"startMode"`
+		Status    string `json:"status"`
+		Error     string `json:"error,omitempty"`
+	}
 )
 
+// The statuses of a project in a group restart
+const (
+	RestartStatusRequested = "requested"
+	RestartStatusStarted   = "started"
+	RestartStatusFailed    = "failed"
+	RestartStatusSkipped   = "skipped"
+)
+
+// defaultStartMode : The mode a dependent is restarted in if PFE doesn't report the mode it is in
+const defaultStartMode = "run"
+
 // RestartProject calls the restart API on the connected PFE, for the given projectID and startMode
 func RestartProject(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, startMode string) error {
 	requestURL := conURL + "/api/v1/projects/" + projectID + "/restart"
@@ -63,3 +85,66 @@ func handleRestartResponse(req *http.Request, conInfo *connections.Connection, h
 
 	return nil
 }
+
+// RestartWithDependents : Restart a project, then the projects that depend on it through links, each after
+// the projects it depends on. Dependents are restarted in the mode they are already in. Each project must
+// start within waitTimeout before the next is restarted, as a dependent restarted before the projects it
+// depends on are up would fail to connect to them. If a restart fails, the projects after it are skipped
+func RestartWithDependents(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, startMode string, waitTimeout time.Duration, onChange func(Project)) ([]RestartResult, *ProjectError) {
+	if waitTimeout <= 0 {
+		err := errors.New(textRestartDependentsNoWait)
+		return nil, &ProjectError{errOpInvalidOptions, err, textRestartDependentsNoWait}
+	}
+	graph, projErr := GetLinkGraph(httpClient, conInfo, conURL)
+	if projErr != nil {
+		return nil, projErr
+	}
+	dependents, projErr := graph.DependentsInOrder(projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	results := []RestartResult{{ProjectID: projectID, Name: graph.name(projectID), StartMode: startMode}}
+	for _, dependent := range dependents {
+		results = append(results, RestartResult{ProjectID: dependent, Name: graph.name(dependent)})
+	}
+	failed := false
+	for i := range results {
+		result := &results[i]
+		if failed {
+			result.Status = RestartStatusSkipped
+			continue
+		}
+		// the project is read before restarting it, so that waiting can tell when the restart has happened
+		before, projErr := GetProjectFromID(httpClient, conInfo, conURL, result.ProjectID)
+		if projErr != nil {
+			result.Status = RestartStatusFailed
+			result.Error = projErr.Desc
+			failed = true
+			continue
+		}
+		if result.StartMode == "" {
+			result.StartMode = before.StartMode
+		}
+		if result.StartMode == "" {
+			result.StartMode = defaultStartMode
+		}
+		err := RestartProject(httpClient, conInfo, conURL, result.ProjectID, result.StartMode)
+		if err != nil {
+			result.Status = RestartStatusFailed
+			result.Error = err.Error()
+			failed = true
+			continue
+		}
+		result.Status = RestartStatusRequested
+		_, projErr = WaitForProject(httpClient, conInfo, conURL, result.ProjectID, waitTimeout, before, onChange)
+		if projErr != nil {
+			result.Status = RestartStatusFailed
+			result.Error = projErr.Desc
+			failed = true
+			continue
+		}
+		result.Status = RestartStatusStarted
+	}
+	return results, nil
+}
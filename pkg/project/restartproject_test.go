@@ -13,9 +13,12 @@ package project
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/security"
@@ -38,3 +41,80 @@ func Test_RestartProject(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+// clientMockLinks serves a fixed set of projects and links, and records the projects restarted.
+// A restarted project is reported as starting, then started
+type clientMockLinks struct {
+	responses  map[string]string
+	modes      map[string]string
+	failID     string
+	restarted  []string
+	startModes []string
+	starting   map[string]bool
+}
+
+func (c *clientMockLinks) Do(req *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(req.URL.Path, "/restart") {
+		projectID := strings.Split(req.URL.Path, "/")[4]
+		body, _ := ioutil.ReadAll(req.Body)
+		c.restarted = append(c.restarted, projectID)
+		c.startModes = append(c.startModes, string(body))
+		if c.starting == nil {
+			c.starting = map[string]bool{}
+		}
+		c.starting[projectID] = true
+		if projectID == c.failID {
+			return &http.Response{StatusCode: http.StatusInternalServerError, Body: emptyResponseBody}, nil
+		}
+		return &http.Response{StatusCode: http.StatusAccepted, Body: ioutil.NopCloser(bytes.NewReader([]byte{}))}, nil
+	}
+	body, ok := c.responses[req.URL.Path]
+	if parts := strings.Split(req.URL.Path, "/"); !ok && len(parts) == 6 && parts[5] == "" {
+		projectID := parts[4]
+		appStatus := "started"
+		if c.starting[projectID] {
+			appStatus = "starting"
+			delete(c.starting, projectID)
+		}
+		body = fmt.Sprintf(`{"projectID": %q, "startMode": %q, "appStatus": %q, "buildStatus": "success"}`, projectID, c.modes[projectID], appStatus)
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+}
+
+func Test_RestartWithDependents(t *testing.T) {
+	originalInterval := waitPollInterval
+	waitPollInterval = time.Millisecond
+	defer func() { waitPollInterval = originalInterval }()
+
+	responses := map[string]string{
+		"/api/v1/projects/":          `[{"projectID": "api", "name": "api"}, {"projectID": "db", "name": "db"}, {"projectID": "web", "name": "web"}]`,
+		"/api/v1/projects/api/links": `[{"projectID": "db", "projectName": "db", "envName": "DB_URL"}]`,
+		"/api/v1/projects/db/links":  `[]`,
+		"/api/v1/projects/web/links": `[{"projectID": "api", "projectName": "api", "envName": "API_URL"}]`,
+	}
+	modes := map[string]string{"api": "debugNoInit", "db": "run", "web": ""}
+
+	t.Run("success case - dependents are restarted in their current modes once the project has started", func(t *testing.T) {
+		mockClient := &clientMockLinks{responses: responses, modes: modes}
+		results, err := RestartWithDependents(mockClient, &mockConnection, "http://mockURL", "db", "debug", time.Second, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"db", "api", "web"}, mockClient.restarted)
+		assert.Equal(t, []string{`{"startMode":"debug"}`, `{"startMode":"debugNoInit"}`, `{"startMode":"run"}`}, mockClient.startModes)
+		assert.Equal(t, []string{RestartStatusStarted, RestartStatusStarted, RestartStatusStarted}, []string{results[0].Status, results[1].Status, results[2].Status})
+	})
+
+	t.Run("fail case - projects after a failed restart are skipped", func(t *testing.T) {
+		mockClient := &clientMockLinks{responses: responses, modes: modes, failID: "api"}
+		results, err := RestartWithDependents(mockClient, &mockConnection, "http://mockURL", "db", "run", time.Second, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"db", "api"}, mockClient.restarted)
+		assert.Equal(t, []string{RestartStatusStarted, RestartStatusFailed, RestartStatusSkipped}, []string{results[0].Status, results[1].Status, results[2].Status})
+	})
+
+	t.Run("fail case - dependents can't be restarted without waiting", func(t *testing.T) {
+		mockClient := &clientMockLinks{responses: responses, modes: modes}
+		_, err := RestartWithDependents(mockClient, &mockConnection, "http://mockURL", "db", "run", 0, nil)
+		assert.Equal(t, errOpInvalidOptions, err.Op)
+		assert.Empty(t, mockClient.restarted)
+	})
+}
@@ -0,0 +1,176 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// ScanCandidate is a directory found by a workspace scan that looks like a project
+type ScanCandidate struct {
+	Name       string  `json:"name"`
+	Path       string  `json:"path"`
+	Language   string  `json:"language"`
+	BuildType  string  `json:"projectType"`
+	Rule       string  `json:"detectionRule"`
+	Confidence float64 `json:"confidence"`
+}
+
+// scanMaxDepth is how many directories below the scanned directory projects are looked for
+const scanMaxDepth = 5
+
+// scanSkipDirs are directories holding dependencies or build output, which are never projects themselves
+var scanSkipDirs = []string{"node_modules", "vendor", "target", "build", "dist", "bin", "obj"}
+
+// invalidNameChars are the characters replaced when a directory name is made into a project name
+var invalidNameChars = regexp.MustCompile("[^a-z0-9._-]+")
+
+// ScanProjects : Find the subdirectories of a directory that match a detection rule. The
+// subdirectories of a matching directory are not scanned, as they belong to that project
+func ScanProjects(root string) ([]ScanCandidate, *ProjectError) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, &ProjectError{errBadPath, err, err.Error()}
+	}
+	info, err := os.Stat(root)
+	if err != nil || !info.IsDir() {
+		text := fmt.Sprintf("%v: %v", textProjectPathDoesNotExist, root)
+		return nil, &ProjectError{errBadPath, errors.New(text), text}
+	}
+
+	rules, projErr := loadAllDetectionRules()
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	candidates := []ScanCandidate{}
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() || path == root {
+			return nil
+		}
+		relativePath, _ := filepath.Rel(root, path)
+		if strings.HasPrefix(info.Name(), ".") || stringInSlice(info.Name(), scanSkipDirs) {
+			return filepath.SkipDir
+		}
+		detected := matchDetectionRules(path, rules)
+		if detected.Rule != "" {
+			candidates = append(candidates, ScanCandidate{
+				Path:       path,
+				Language:   detected.Language,
+				BuildType:  detected.BuildType,
+				Rule:       detected.Rule,
+				Confidence: detected.Confidence,
+			})
+			return filepath.SkipDir
+		}
+		if len(strings.Split(relativePath, string(filepath.Separator))) >= scanMaxDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	nameScanCandidates(root, candidates)
+	return candidates, nil
+}
+
+// nameScanCandidates : Name each candidate after its directory, using as many parent directories
+// as needed to make the names unique
+func nameScanCandidates(root string, candidates []ScanCandidate) {
+	segments := make([][]string, len(candidates))
+	depths := make([]int, len(candidates))
+	for i, candidate := range candidates {
+		relativePath, _ := filepath.Rel(root, candidate.Path)
+		segments[i] = strings.Split(relativePath, string(filepath.Separator))
+		depths[i] = 1
+	}
+	for {
+		names := map[string][]int{}
+		for i := range candidates {
+			parts := segments[i][len(segments[i])-depths[i]:]
+			candidates[i].Name = projectNameFromPath(strings.Join(parts, "-"))
+			names[candidates[i].Name] = append(names[candidates[i].Name], i)
+		}
+		clash := false
+		for _, indexes := range names {
+			if len(indexes) < 2 {
+				continue
+			}
+			for _, i := range indexes {
+				if depths[i] < len(segments[i]) {
+					depths[i]++
+					clash = true
+				}
+			}
+		}
+		if !clash {
+			return
+		}
+	}
+}
+
+// projectNameFromPath : Make a directory name into a valid project name
+func projectNameFromPath(name string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-._")
+}
+
+// BindProjects : Validate and bind each of a list of scanned projects to a connection, reporting the names of
+// the projects that were bound and the error for each that wasn't, in the same shape as an upgrade
+func BindProjects(candidates []ScanCandidate, conID string) *map[string]interface{} {
+	bindStatus := make(map[string]interface{})
+	bindStatus["migrated"] = make([]string, 0)
+	bindStatus["failed"] = make([]interface{}, 0)
+
+	for _, candidate := range candidates {
+		bindErr := validateScanCandidate(&candidate, conID)
+		if bindErr == nil {
+			_, bindErr = Bind(candidate.Path, candidate.Name, candidate.Language, candidate.BuildType, conID)
+		}
+		if bindErr != nil {
+			errResponse := make(map[string]string)
+			errResponse["projectName"] = candidate.Name
+			errResponse["error"] = bindErr.Desc
+			bindStatus["failed"] = append(bindStatus["failed"].([]interface{}), &errResponse)
+		} else {
+			bindStatus["migrated"] = append(bindStatus["migrated"].([]string), candidate.Name)
+		}
+	}
+	return &bindStatus
+}
+
+// validateScanCandidate : Validate a scanned project as project validate does, which detects extension
+// projects and writes a .cw-settings file if the project has none, updating its type to the one found
+func validateScanCandidate(candidate *ScanCandidate, conID string) *ProjectError {
+	set := flag.NewFlagSet("validate", 0)
+	set.String("path", candidate.Path, "doc")
+	set.String("conid", conID, "doc")
+	response, projErr := ValidateProject(cli.NewContext(nil, set, nil))
+	if projErr != nil {
+		return projErr
+	}
+	if result, ok := response.Result.(ProjectType); ok {
+		candidate.Language = result.Language
+		candidate.BuildType = result.BuildType
+		candidate.Rule = result.Rule
+		candidate.Confidence = result.Confidence
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanProjects(t *testing.T) {
+	testFolder := "scan_test_folder_delete_me"
+	os.Mkdir(testFolder, 0777)
+	defer cleanupTestFolder(t, testFolder)
+
+	originalHome := os.Getenv("HOME")
+	absTestDir, _ := os.Getwd()
+	os.Setenv("HOME", filepath.Join(absTestDir, testFolder))
+	defer os.Setenv("HOME", originalHome)
+
+	root := filepath.Join(absTestDir, testFolder, "monorepo")
+	files := map[string]string{
+		"package.json":                          "{}",
+		"services/api/pom.xml":                  "<groupId>org.springframework.boot</groupId>",
+		"services/api/src/main/pom.xml":         "<project/>",
+		"services/Web UI/package.json":          "{}",
+		"services/Web UI/node_modules/x/go.mod": "module x",
+		"tools/api/Cargo.toml":                  "[package]",
+		"docs/README.md":                        "# docs",
+		".github/workflows/setup.py":            "",
+	}
+	for name, contents := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755)
+		ioutil.WriteFile(filepath.Join(root, name), []byte(contents), 0644)
+	}
+
+	t.Run("success case - the projects below a directory are found and named uniquely", func(t *testing.T) {
+		candidates, err := ScanProjects(root)
+		assert.Nil(t, err)
+		assert.Equal(t, []ScanCandidate{
+			{"web-ui", filepath.Join(root, "services", "Web UI"), "javascript", "nodejs", "nodejs", 0.9},
+			{"services-api", filepath.Join(root, "services", "api"), "java", "spring", "spring-maven", 0.9},
+			{"tools-api", filepath.Join(root, "tools", "api"), "rust", "docker", "rust-cargo", 0.9},
+		}, candidates)
+	})
+
+	t.Run("fail case - a missing directory can't be scanned", func(t *testing.T) {
+		_, err := ScanProjects(filepath.Join(root, "missing"))
+		assert.Equal(t, errBadPath, err.Op)
+	})
+}
+
+func TestBindProjects(t *testing.T) {
+	candidates := []ScanCandidate{{Name: "missing", Path: "scan_test_missing_project", Language: "go", BuildType: "docker"}}
+	got := BindProjects(candidates, "local")
+	assert.Equal(t, []string{}, (*got)["migrated"])
+	failed := (*got)["failed"].([]interface{})
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "missing", (*failed[0].(*map[string]string))["projectName"])
+	// candidates are validated before they are bound
+	assert.Equal(t, textProjectPathDoesNotExist, (*failed[0].(*map[string]string))["error"])
+}
@@ -0,0 +1,533 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// settingsField : A field of .cw-settings and the constraints on its value
+	settingsField struct {
+		Key         string
+		Type        string // the JSON type of the value: string, boolean or array of strings
+		Description string
+		Pattern     *regexp.Regexp // matched by a string value, or each item of an array
+		PatternDesc string
+		Enum        []string
+		BuildTypes  []string // the project types that use the field, or all if empty
+	}
+
+	// SettingsIssue : A problem with a project's .cw-settings
+	SettingsIssue struct {
+		Key        string `json:"key"`
+		Severity   string `json:"severity"`
+		Message    string `json:"message"`
+		Suggestion string `json:"suggestion,omitempty"`
+	}
+
+	// SettingsValidation : The problems with a project's .cw-settings
+	SettingsValidation struct {
+		Path      string          `json:"path"`
+		BuildType string          `json:"projectType"`
+		Valid     bool            `json:"valid"`
+		Issues    []SettingsIssue `json:"issues"`
+	}
+
+	// jsonMember : Where a member of a JSON object is in the document
+	jsonMember struct {
+		key        string
+		keyStart   int
+		valueStart int
+		valueEnd   int
+	}
+)
+
+// The severities of settings issues. Only errors make the settings invalid
+const (
+	SettingsError   = "error"
+	SettingsWarning = "warning"
+)
+
+// The JSON types of settings values
+const (
+	settingsTypeString  = "string"
+	settingsTypeBoolean = "boolean"
+	settingsTypeArray   = "array"
+)
+
+// settingsFileName : The name of the settings file in a project
+const settingsFileName = ".cw-settings"
+
+// portPattern : Matches an empty string or a port number from 1 to 65535
+var portPattern = regexp.MustCompile(`^([1-9][0-9]{0,3}|[1-5][0-9]{4}|6[0-4][0-9]{3}|65[0-4][0-9]{2}|655[0-2][0-9]|6553[0-5])?$`)
+
+// settingsConstraints : The descriptions of the fields of CWSettings and the constraints on their values,
+// by JSON key. The keys and types come from CWSettings itself
+var settingsConstraints = map[string]settingsField{
+	"contextRoot":       {Description: "The path the application is served from"},
+	"internalPort":      {Description: "The port the application listens on in its container", Pattern: portPattern, PatternDesc: "a port number from 1 to 65535"},
+	"healthCheck":       {Description: "The path pinged to find whether the application has started"},
+	"internalDebugPort": {Description: "The port the debugger listens on in the container", Pattern: portPattern, PatternDesc: "a port number from 1 to 65535", BuildTypes: projectTypesWithInternalDebugPort},
+	"isHttps":           {Description: "Whether the application is served over HTTPS"},
+	"ignoredPaths":      {Description: "Patterns, in .gitignore syntax, of the paths that are not synced"},
+	"mavenProfiles":     {Description: "The Maven profiles used to build the project", BuildTypes: projectTypesWithMavenSettings},
+	"mavenProperties":   {Description: "The Maven properties used to build the project", Pattern: regexp.MustCompile(`^([^=]+=.*)?$`), PatternDesc: "key=value", BuildTypes: projectTypesWithMavenSettings},
+	"statusPingTimeout": {Description: "How many seconds to wait for the application to start", Pattern: regexp.MustCompile(`^[0-9]*$`), PatternDesc: "a whole number of seconds"},
+	"useGitignore":      {Description: "Whether the patterns in .gitignore are ignored too"},
+	"useDockerignore":   {Description: "Whether the patterns in .dockerignore are ignored too"},
+	"symlinks":          {Description: "How symlinks in the project are synced", Enum: []string{symlinksFollow, symlinksLink, symlinksSkip}},
+	"syncConcurrency":   {Description: "The maximum number of files uploaded at once when the project is bound or synced", Pattern: regexp.MustCompile(`^([1-9][0-9]*)?$`), PatternDesc: "a whole number greater than 0"},
+}
+
+// settingsFields : The fields of .cw-settings, derived from the json tags and types of CWSettings
+func settingsFields() []settingsField {
+	settingsType := reflect.TypeOf(CWSettings{})
+	fields := []settingsField{}
+	for i := 0; i < settingsType.NumField(); i++ {
+		structField := settingsType.Field(i)
+		key := strings.Split(structField.Tag.Get("json"), ",")[0]
+		field := settingsConstraints[key]
+		field.Key = key
+		switch structField.Type.Kind() {
+		case reflect.Bool:
+			field.Type = settingsTypeBoolean
+		case reflect.Slice:
+			field.Type = settingsTypeArray
+		default:
+			field.Type = settingsTypeString
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// getSettingsField : Find a field of .cw-settings by key
+func getSettingsField(key string) (settingsField, bool) {
+	for _, field := range settingsFields() {
+		if field.Key == key {
+			return field, true
+		}
+	}
+	return settingsField{}, false
+}
+
+// SettingsSchema : Get the JSON schema of .cw-settings
+func SettingsSchema() map[string]interface{} {
+	properties := map[string]interface{}{}
+	for _, field := range settingsFields() {
+		description := field.Description
+		if len(field.BuildTypes) > 0 {
+			description += ". Only used by " + strings.Join(field.BuildTypes, " and ") + " projects"
+		}
+		property := map[string]interface{}{"type": field.Type, "description": description}
+		constraints := property
+		if field.Type == settingsTypeArray {
+			constraints = map[string]interface{}{"type": settingsTypeString}
+			property["items"] = constraints
+		}
+		if field.Pattern != nil {
+			constraints["pattern"] = field.Pattern.String()
+		}
+		if len(field.Enum) > 0 {
+			constraints["enum"] = field.Enum
+		}
+		properties[field.Key] = property
+	}
+	return map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                settingsFileName,
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// ValidateProjectSettings : Validate the .cw-settings of a project against the schema. Fields that the project's
+// type doesn't use are warned about, detecting the type if it isn't given
+func ValidateProjectSettings(projectPath string, buildType string) (*SettingsValidation, *ProjectError) {
+	content, err := ioutil.ReadFile(filepath.Join(projectPath, settingsFileName))
+	if err != nil {
+		return nil, &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	if buildType == "" {
+		// only trust a detection rule that matched, as the fallback type is a guess
+		if detected, projErr := detectProjectType(projectPath); projErr == nil && detected.Rule != "" {
+			buildType = detected.BuildType
+		}
+	}
+	issues := validateSettings(content, buildType)
+	validation := &SettingsValidation{Path: projectPath, BuildType: buildType, Valid: true, Issues: issues}
+	for _, issue := range issues {
+		if issue.Severity == SettingsError {
+			validation.Valid = false
+		}
+	}
+	return validation, nil
+}
+
+// validateSettings : Find the problems with the content of a .cw-settings file
+func validateSettings(content []byte, buildType string) []SettingsIssue {
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return []SettingsIssue{{Severity: SettingsError, Message: "settings are not a valid JSON object: " + err.Error()}}
+	}
+	keys := []string{}
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	issues := []SettingsIssue{}
+	for _, key := range keys {
+		field, known := getSettingsField(key)
+		if !known {
+			issue := SettingsIssue{Key: key, Severity: SettingsError, Message: "unknown key, which Codewind ignores"}
+			if suggestion := suggestSettingsKey(key); suggestion != "" {
+				issue.Suggestion = suggestion
+				issue.Message += fmt.Sprintf(", did you mean %s?", suggestion)
+			}
+			issues = append(issues, issue)
+			continue
+		}
+		var value interface{}
+		json.Unmarshal(settings[key], &value)
+		if message := field.check(value); message != "" {
+			issues = append(issues, SettingsIssue{Key: key, Severity: SettingsError, Message: message})
+			continue
+		}
+		if buildType != "" && len(field.BuildTypes) > 0 && !stringInSlice(buildType, field.BuildTypes) {
+			message := fmt.Sprintf("only used by %s projects, so ignored for %s projects", strings.Join(field.BuildTypes, " and "), buildType)
+			issues = append(issues, SettingsIssue{Key: key, Severity: SettingsWarning, Message: message})
+		}
+	}
+	return issues
+}
+
+// check : Check a value decoded from JSON against the field, returning what is wrong with it if anything
+func (field settingsField) check(value interface{}) string {
+	switch field.Type {
+	case settingsTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return "must be true or false"
+		}
+	case settingsTypeArray:
+		items, ok := value.([]interface{})
+		if !ok {
+			return "must be an array of strings"
+		}
+		for _, item := range items {
+			itemString, ok := item.(string)
+			if !ok {
+				return "must be an array of strings"
+			}
+			if message := field.checkString(itemString); message != "" {
+				return fmt.Sprintf("item %q %s", itemString, message)
+			}
+		}
+	default:
+		valueString, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		return field.checkString(valueString)
+	}
+	return ""
+}
+
+func (field settingsField) checkString(value string) string {
+	if field.Pattern != nil && !field.Pattern.MatchString(value) {
+		return "must be " + field.PatternDesc
+	}
+	if len(field.Enum) > 0 && !stringInSlice(value, field.Enum) {
+		return "must be one of " + strings.Join(field.Enum, ", ")
+	}
+	return ""
+}
+
+// suggestSettingsKey : Find the known key closest to an unknown one, if any is close enough to be a typo
+func suggestSettingsKey(key string) string {
+	suggestion, bestDistance := "", len(key)/3+1
+	for _, field := range settingsFields() {
+		if strings.EqualFold(field.Key, key) {
+			return field.Key
+		}
+		if distance := editDistance(strings.ToLower(key), strings.ToLower(field.Key)); distance <= bestDistance {
+			suggestion, bestDistance = field.Key, distance-1
+		}
+	}
+	return suggestion
+}
+
+// editDistance : The Levenshtein distance between two strings
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func minInt(first int, rest ...int) int {
+	for _, value := range rest {
+		if value < first {
+			first = value
+		}
+	}
+	return first
+}
+
+// GetProjectSetting : Get the value of a key of a project's .cw-settings, or all of them if no key is given
+func GetProjectSetting(projectPath string, key string) (json.RawMessage, *ProjectError) {
+	content, err := ioutil.ReadFile(filepath.Join(projectPath, settingsFileName))
+	if err != nil {
+		return nil, &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	if key == "" {
+		return content, nil
+	}
+	if _, projErr := lookupSettingsField(key); projErr != nil {
+		return nil, projErr
+	}
+	value, ok := settings[key]
+	if !ok {
+		err := fmt.Errorf("%s is not set", key)
+		return nil, &ProjectError{errOpNotFound, err, err.Error()}
+	}
+	return value, nil
+}
+
+// SetProjectSetting : Set a key of a project's .cw-settings, creating the file if it doesn't exist. The value is
+// checked against the schema, then written in place of the old value so the rest of the file is unchanged.
+// Boolean values are given as true or false, and arrays as a JSON array or a comma separated list
+func SetProjectSetting(projectPath string, key string, value string) *ProjectError {
+	field, projErr := lookupSettingsField(key)
+	if projErr != nil {
+		return projErr
+	}
+	parsed, err := field.parse(value)
+	if err != nil {
+		return &ProjectError{errOpInvalidOptions, err, err.Error()}
+	}
+	if message := field.check(parsed); message != "" {
+		err := fmt.Errorf("%s %s", key, message)
+		return &ProjectError{errOpInvalidOptions, err, err.Error()}
+	}
+
+	settingsPath := filepath.Join(projectPath, settingsFileName)
+	content, err := ioutil.ReadFile(settingsPath)
+	if os.IsNotExist(err) {
+		content = []byte("{\n}\n")
+	} else if err != nil {
+		return &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	updated, err := setJSONMember(content, key, parsed)
+	if err != nil {
+		return &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	if err := ioutil.WriteFile(settingsPath, updated, 0644); err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	return nil
+}
+
+func lookupSettingsField(key string) (settingsField, *ProjectError) {
+	field, known := getSettingsField(key)
+	if !known {
+		err := fmt.Errorf("%s is not a setting", key)
+		if suggestion := suggestSettingsKey(key); suggestion != "" {
+			err = fmt.Errorf("%s is not a setting, did you mean %s?", key, suggestion)
+		}
+		return field, &ProjectError{errOpInvalidOptions, err, err.Error()}
+	}
+	return field, nil
+}
+
+// parse : Convert a value given on the command line to the field's JSON type
+func (field settingsField) parse(value string) (interface{}, error) {
+	switch field.Type {
+	case settingsTypeBoolean:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", field.Key)
+		}
+		return parsed, nil
+	case settingsTypeArray:
+		items := []interface{}{}
+		trimmed := strings.TrimSpace(value)
+		if strings.HasPrefix(trimmed, "[") {
+			if err := json.Unmarshal([]byte(trimmed), &items); err != nil {
+				return nil, fmt.Errorf("%s must be a JSON array of strings: %s", field.Key, err.Error())
+			}
+			return items, nil
+		}
+		if trimmed != "" {
+			for _, item := range strings.Split(trimmed, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		}
+		return items, nil
+	}
+	return value, nil
+}
+
+// setJSONMember : Set a member of the top level object of a JSON document, replacing only the old value if
+// there is one, or adding it after the last member with the same indentation
+func setJSONMember(content []byte, key string, value interface{}) ([]byte, error) {
+	members, closing, err := scanJSONObject(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// follow the layout of the existing members, or use the layout json.MarshalIndent would
+	indent, separator := "  ", "\n"
+	if len(members) > 0 {
+		lineStart := bytes.LastIndexByte(content[:members[0].keyStart], '\n')
+		if lineStart < 0 {
+			indent, separator = "", " "
+		} else {
+			indent = string(content[lineStart+1 : members[0].keyStart])
+		}
+	}
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if separator == "\n" {
+		encoder.SetIndent(indent, indent)
+	}
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	encodedValue := bytes.TrimRight(encoded.Bytes(), "\n")
+
+	var updated bytes.Buffer
+	for _, member := range members {
+		if member.key == key {
+			updated.Write(content[:member.valueStart])
+			updated.Write(encodedValue)
+			updated.Write(content[member.valueEnd:])
+			return updated.Bytes(), nil
+		}
+	}
+
+	encodedKey, _ := json.Marshal(key)
+	newMember := string(encodedKey) + ": " + string(encodedValue)
+	if len(members) > 0 {
+		insertAt := members[len(members)-1].valueEnd
+		updated.Write(content[:insertAt])
+		updated.WriteString("," + separator + indent + newMember)
+		updated.Write(content[insertAt:])
+	} else {
+		updated.Write(bytes.TrimRight(content[:closing], " \t\r\n"))
+		updated.WriteString(separator + indent + newMember + separator)
+		updated.Write(content[closing:])
+	}
+	return updated.Bytes(), nil
+}
+
+// scanJSONObject : Find where the members of the top level object of a JSON document are, and where it closes
+func scanJSONObject(content []byte) ([]jsonMember, int, error) {
+	if !json.Valid(content) {
+		return nil, 0, errors.New("settings are not valid JSON")
+	}
+	i := skipJSONSpace(content, 0)
+	if content[i] != '{' {
+		return nil, 0, errors.New("settings are not a JSON object")
+	}
+	members := []jsonMember{}
+	i = skipJSONSpace(content, i+1)
+	if content[i] == '}' {
+		return members, i, nil
+	}
+	for {
+		// the document is valid, so a key, colon and value follow
+		member := jsonMember{keyStart: i}
+		keyEnd := skipJSONValue(content, i)
+		json.Unmarshal(content[i:keyEnd], &member.key)
+		i = skipJSONSpace(content, keyEnd)
+		member.valueStart = skipJSONSpace(content, i+1)
+		member.valueEnd = skipJSONValue(content, member.valueStart)
+		members = append(members, member)
+		i = skipJSONSpace(content, member.valueEnd)
+		if content[i] == '}' {
+			return members, i, nil
+		}
+		i = skipJSONSpace(content, i+1)
+	}
+}
+
+func skipJSONSpace(content []byte, i int) int {
+	for i < len(content) && strings.IndexByte(" \t\r\n", content[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// skipJSONValue : Find the end of the valid JSON value starting at i
+func skipJSONValue(content []byte, i int) int {
+	depth := 0
+	for i < len(content) {
+		switch content[i] {
+		case '"':
+			i++
+			for content[i] != '"' {
+				if content[i] == '\\' {
+					i++
+				}
+				i++
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		case ',', ' ', '\t', '\r', '\n':
+			if depth == 0 {
+				return i
+			}
+		}
+		if depth < 0 {
+			return i
+		}
+		i++
+		if depth == 0 && (content[i-1] == '"' || content[i-1] == '}' || content[i-1] == ']') {
+			return i
+		}
+	}
+	return i
+}
@@ -0,0 +1,197 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettingsSchema(t *testing.T) {
+	schema := SettingsSchema()
+	properties := schema["properties"].(map[string]interface{})
+	assert.Len(t, properties, len(settingsConstraints))
+	assert.Equal(t, "boolean", properties["isHttps"].(map[string]interface{})["type"])
+	mavenProperties := properties["mavenProperties"].(map[string]interface{})
+	assert.Equal(t, "array", mavenProperties["type"])
+	assert.Equal(t, "^([^=]+=.*)?$", mavenProperties["items"].(map[string]interface{})["pattern"])
+	assert.Equal(t, false, schema["additionalProperties"])
+}
+
+func TestValidateSettings(t *testing.T) {
+	tests := map[string]struct {
+		content    string
+		buildType  string
+		wantIssues []SettingsIssue
+	}{
+		"success case - default settings are valid": {
+			content:    `{"contextRoot": "", "internalPort": "", "healthCheck": "", "isHttps": false, "ignoredPaths": ["/node_modules"], "statusPingTimeout": "", "internalDebugPort": ""}`,
+			buildType:  "nodejs",
+			wantIssues: []SettingsIssue{},
+		},
+		"fail case - a key with the wrong case is suggested": {
+			content:    `{"internalport": "3000"}`,
+			wantIssues: []SettingsIssue{{Key: "internalport", Severity: SettingsError, Message: "unknown key, which Codewind ignores, did you mean internalPort?", Suggestion: "internalPort"}},
+		},
+		"fail case - a misspelt key is suggested": {
+			content:    `{"helthCheck": "/health"}`,
+			wantIssues: []SettingsIssue{{Key: "helthCheck", Severity: SettingsError, Message: "unknown key, which Codewind ignores, did you mean healthCheck?", Suggestion: "healthCheck"}},
+		},
+		"fail case - an unrelated key has no suggestion": {
+			content:    `{"replicas": 3}`,
+			wantIssues: []SettingsIssue{{Key: "replicas", Severity: SettingsError, Message: "unknown key, which Codewind ignores"}},
+		},
+		"fail case - a non-numeric timeout": {
+			content:    `{"statusPingTimeout": "30s"}`,
+			wantIssues: []SettingsIssue{{Key: "statusPingTimeout", Severity: SettingsError, Message: "must be a whole number of seconds"}},
+		},
+		"fail case - wrong types and values": {
+			content: `{"isHttps": "true", "internalPort": "70000", "symlinks": "copy", "mavenProperties": ["debug"]}`,
+			wantIssues: []SettingsIssue{
+				{Key: "internalPort", Severity: SettingsError, Message: "must be a port number from 1 to 65535"},
+				{Key: "isHttps", Severity: SettingsError, Message: "must be true or false"},
+				{Key: "mavenProperties", Severity: SettingsError, Message: `item "debug" must be key=value`},
+				{Key: "symlinks", Severity: SettingsError, Message: "must be one of follow, link, skip"},
+			},
+		},
+		"success case - fields the project type doesn't use are warned about": {
+			content:    `{"mavenProfiles": ["dev"], "internalDebugPort": "9229"}`,
+			buildType:  "nodejs",
+			wantIssues: []SettingsIssue{{Key: "mavenProfiles", Severity: SettingsWarning, Message: "only used by liberty and spring projects, so ignored for nodejs projects"}},
+		},
+		"fail case - not valid JSON": {
+			content:    `{"contextRoot": }`,
+			wantIssues: []SettingsIssue{{Severity: SettingsError, Message: "settings are not a valid JSON object: invalid character '}' looking for beginning of value"}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.wantIssues, validateSettings([]byte(test.content), test.buildType))
+		})
+	}
+}
+
+func TestSetJSONMember(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		key     string
+		value   interface{}
+		want    string
+	}{
+		"success case - replaces only the value": {
+			content: "{\n    \"contextRoot\":   \"/api\",\n    \"isHttps\": false\n}\n",
+			key:     "contextRoot",
+			value:   "/v2",
+			want:    "{\n    \"contextRoot\":   \"/v2\",\n    \"isHttps\": false\n}\n",
+		},
+		"success case - adds a key with the same indentation": {
+			content: "{\n\t\"contextRoot\": \"/api\"\n}",
+			key:     "mavenProfiles",
+			value:   []interface{}{"dev", "a<b"},
+			want:    "{\n\t\"contextRoot\": \"/api\",\n\t\"mavenProfiles\": [\n\t\t\"dev\",\n\t\t\"a<b\"\n\t]\n}",
+		},
+		"success case - adds a key to a single line object": {
+			content: `{"contextRoot": "/api", "ignoredPaths": ["a, b", "{c}"]}`,
+			key:     "isHttps",
+			value:   true,
+			want:    `{"contextRoot": "/api", "ignoredPaths": ["a, b", "{c}"], "isHttps": true}`,
+		},
+		"success case - replaces a nested value": {
+			content: `{"ignoredPaths": ["a", "b\"]"], "isHttps": false}`,
+			key:     "ignoredPaths",
+			value:   []interface{}{},
+			want:    `{"ignoredPaths": [], "isHttps": false}`,
+		},
+		"success case - adds a key to an empty object": {
+			content: "{}\n",
+			key:     "internalPort",
+			value:   "8080",
+			want:    "{\n  \"internalPort\": \"8080\"\n}\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := setJSONMember([]byte(test.content), test.key, test.value)
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, string(got))
+		})
+	}
+}
+
+func TestSetProjectSetting(t *testing.T) {
+	projectPath, _ := ioutil.TempDir("", "settings")
+	defer os.RemoveAll(projectPath)
+	settingsPath := filepath.Join(projectPath, ".cw-settings")
+	ioutil.WriteFile(settingsPath, []byte("{\n  \"contextRoot\": \"\",\n  \"isHttps\": false\n}\n"), 0644)
+
+	// the cases run in order, each starting from the file the one before left
+	tests := []struct {
+		name      string
+		key       string
+		value     string
+		wantErrOp string
+		want      string
+	}{
+		{
+			name:  "success case - sets a boolean",
+			key:   "isHttps",
+			value: "true",
+			want:  "{\n  \"contextRoot\": \"\",\n  \"isHttps\": true\n}\n",
+		},
+		{
+			name:  "success case - sets an array from a comma separated list",
+			key:   "ignoredPaths",
+			value: "/target, *.log",
+			want:  "{\n  \"contextRoot\": \"\",\n  \"isHttps\": true,\n  \"ignoredPaths\": [\n    \"/target\",\n    \"*.log\"\n  ]\n}\n",
+		},
+		{
+			name:      "fail case - an unknown key is suggested",
+			key:       "contextroot",
+			value:     "/api",
+			wantErrOp: errOpInvalidOptions,
+		},
+		{
+			name:      "fail case - an invalid value is not written",
+			key:       "internalPort",
+			value:     "http",
+			wantErrOp: errOpInvalidOptions,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before, _ := ioutil.ReadFile(settingsPath)
+			err := SetProjectSetting(projectPath, test.key, test.value)
+			after, _ := ioutil.ReadFile(settingsPath)
+			if test.wantErrOp != "" {
+				assert.Equal(t, test.wantErrOp, err.Op)
+				assert.Equal(t, string(before), string(after))
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.want, string(after))
+			}
+		})
+	}
+
+	t.Run("success case - gets a value", func(t *testing.T) {
+		value, err := GetProjectSetting(projectPath, "isHttps")
+		assert.Nil(t, err)
+		assert.Equal(t, "true", string(value))
+	})
+	t.Run("fail case - gets a key that isn't set", func(t *testing.T) {
+		_, err := GetProjectSetting(projectPath, "healthCheck")
+		assert.Equal(t, errOpNotFound, err.Op)
+	})
+}
@@ -0,0 +1,203 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// ProjectStatus : A project with the connection it is bound to and when it was last synced
+	ProjectStatus struct {
+		Project
+		ConnectionID    string `json:"connectionID"`
+		ConnectionLabel string `json:"connectionLabel"`
+		LastSync        int64  `json:"lastSync,omitempty"` // milliseconds since the epoch
+	}
+
+	// StatusReport : The status of the projects on every connection, and the connections that couldn't be reached
+	StatusReport struct {
+		Projects []ProjectStatus    `json:"projects"`
+		Errors   []ConnectionStatus `json:"errors"`
+	}
+
+	// ConnectionStatus : Why the projects on a connection couldn't be listed
+	ConnectionStatus struct {
+		ConnectionID    string `json:"connectionID"`
+		ConnectionLabel string `json:"connectionLabel"`
+		Error           string `json:"error"`
+	}
+
+	// StatusFilter : Which projects to include in a status report. Empty fields match every project
+	StatusFilter struct {
+		Status     string // matches the build or application status
+		Language   string
+		Connection string
+	}
+)
+
+// The columns a status report can be sorted by
+const (
+	StatusSortName       = "name"
+	StatusSortStatus     = "status"
+	StatusSortLanguage   = "language"
+	StatusSortConnection = "connection"
+	StatusSortSync       = "sync"
+)
+
+// StatusRequestTimeout : How long a connection has to list its projects, so a connection that can't be
+// reached doesn't hold up the report
+const StatusRequestTimeout = 10 * time.Second
+
+// GetStatusReport : Get the status of the projects on every connection, or only the connection with the given
+// ID or label. The connections are queried at once, and a connection that can't be reached is reported rather
+// than failing the whole report
+func GetStatusReport(httpClient utils.HTTPClient, connection string) (*StatusReport, *ProjectError) {
+	conList, conErr := connections.GetAllConnections()
+	if conErr != nil {
+		return nil, &ProjectError{errOpConNotFound, conErr, conErr.Desc}
+	}
+	selected := []connections.Connection{}
+	for _, conInfo := range conList {
+		if connectionMatches(connection, conInfo.ID, conInfo.Label) {
+			selected = append(selected, conInfo)
+		}
+	}
+	return buildStatusReport(selected, func(conInfo *connections.Connection) ([]Project, *ProjectError) {
+		return getConnectionProjects(httpClient, conInfo)
+	}), nil
+}
+
+// buildStatusReport : Get the projects on each connection at once, reporting them in the order of the connections
+func buildStatusReport(conList []connections.Connection, getProjects func(*connections.Connection) ([]Project, *ProjectError)) *StatusReport {
+	projects := make([][]Project, len(conList))
+	projErrs := make([]*ProjectError, len(conList))
+	var wg sync.WaitGroup
+	for i := range conList {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			projects[i], projErrs[i] = getProjects(&conList[i])
+		}(i)
+	}
+	wg.Wait()
+
+	report := &StatusReport{Projects: []ProjectStatus{}, Errors: []ConnectionStatus{}}
+	for i, conInfo := range conList {
+		if projErrs[i] != nil {
+			report.Errors = append(report.Errors, ConnectionStatus{conInfo.ID, conInfo.Label, projErrs[i].Desc})
+			continue
+		}
+		for _, project := range projects[i] {
+			status := ProjectStatus{Project: project, ConnectionID: conInfo.ID, ConnectionLabel: conInfo.Label}
+			status.LastSync = lastSyncTime(project.ProjectID)
+			report.Projects = append(report.Projects, status)
+		}
+	}
+	return report
+}
+
+// getConnectionProjects : Get the projects bound to a connection
+func getConnectionProjects(httpClient utils.HTTPClient, conInfo *connections.Connection) ([]Project, *ProjectError) {
+	conURL, conURLErr := config.PFEOriginFromConnection(conInfo)
+	if conURLErr != nil {
+		return nil, &ProjectError{errOpConNotFound, conURLErr.Err, conURLErr.Desc}
+	}
+	return GetAll(httpClient, conInfo, conURL)
+}
+
+// lastSyncTime : Get when a project was last synced, from when its sync manifest was last saved,
+// or 0 if it hasn't been synced by this CLI
+func lastSyncTime(projectID string) int64 {
+	info, err := os.Stat(getSyncManifestFilename(projectID))
+	if err != nil {
+		return 0
+	}
+	return info.ModTime().UnixNano() / 1000000
+}
+
+// Filter : Remove the projects that don't match the filter from the report
+func (report *StatusReport) Filter(filter StatusFilter) {
+	projects := []ProjectStatus{}
+	for _, project := range report.Projects {
+		if filter.matches(project) {
+			projects = append(projects, project)
+		}
+	}
+	report.Projects = projects
+}
+
+func (filter StatusFilter) matches(project ProjectStatus) bool {
+	if filter.Status != "" && !strings.EqualFold(filter.Status, project.AppStatus) && !strings.EqualFold(filter.Status, project.BuildStatus) {
+		return false
+	}
+	if filter.Language != "" && !strings.EqualFold(filter.Language, project.Language) {
+		return false
+	}
+	return connectionMatches(filter.Connection, project.ConnectionID, project.ConnectionLabel)
+}
+
+// connectionMatches : Check whether a connection filter, which may be empty, matches a connection's ID or label
+func connectionMatches(filter string, conID string, conLabel string) bool {
+	return filter == "" || strings.EqualFold(filter, conID) || strings.EqualFold(filter, conLabel)
+}
+
+// Sort : Sort the projects in the report by a column, then by name
+func (report *StatusReport) Sort(column string) *ProjectError {
+	var compare func(a, b ProjectStatus) int
+	switch column {
+	case StatusSortName, "":
+		compare = func(a, b ProjectStatus) int { return 0 }
+	case StatusSortStatus:
+		compare = func(a, b ProjectStatus) int { return compareFold(a.AppStatus, b.AppStatus) }
+	case StatusSortLanguage:
+		compare = func(a, b ProjectStatus) int { return compareFold(a.Language, b.Language) }
+	case StatusSortConnection:
+		compare = func(a, b ProjectStatus) int { return compareFold(a.ConnectionLabel, b.ConnectionLabel) }
+	case StatusSortSync:
+		// most recently synced first
+		compare = func(a, b ProjectStatus) int {
+			switch {
+			case a.LastSync > b.LastSync:
+				return -1
+			case a.LastSync < b.LastSync:
+				return 1
+			}
+			return 0
+		}
+	default:
+		err := fmt.Errorf("cannot sort by %s, must be one of %s, %s, %s, %s or %s", column, StatusSortName, StatusSortStatus, StatusSortLanguage, StatusSortConnection, StatusSortSync)
+		return &ProjectError{errOpInvalidOptions, err, err.Error()}
+	}
+	sort.SliceStable(report.Projects, func(i, j int) bool {
+		a, b := report.Projects[i], report.Projects[j]
+		if order := compare(a, b); order != 0 {
+			return order < 0
+		}
+		return compareFold(a.Name, b.Name) < 0
+	})
+	return nil
+}
+
+// compareFold : Compare two strings ignoring case
+func compareFold(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
@@ -0,0 +1,155 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockStatusReport() *StatusReport {
+	return &StatusReport{Projects: []ProjectStatus{
+		{Project: Project{Name: "web", Language: "nodejs", BuildStatus: "failed", AppStatus: "stopped"}, ConnectionID: "local", ConnectionLabel: "Local", LastSync: 100},
+		{Project: Project{Name: "Api", Language: "java", BuildStatus: "success", AppStatus: "started"}, ConnectionID: "remote1", ConnectionLabel: "Cloud", LastSync: 300},
+		{Project: Project{Name: "batch", Language: "java", BuildStatus: "failed", AppStatus: "stopped"}, ConnectionID: "local", ConnectionLabel: "Local"},
+	}}
+}
+
+func statusNames(report *StatusReport) []string {
+	names := []string{}
+	for _, project := range report.Projects {
+		names = append(names, project.Name)
+	}
+	return names
+}
+
+func TestStatusReportFilter(t *testing.T) {
+	tests := map[string]struct {
+		filter    StatusFilter
+		wantNames []string
+	}{
+		"success case - empty filter matches every project": {
+			filter:    StatusFilter{},
+			wantNames: []string{"web", "Api", "batch"},
+		},
+		"success case - status matches the build status": {
+			filter:    StatusFilter{Status: "FAILED"},
+			wantNames: []string{"web", "batch"},
+		},
+		"success case - status matches the application status": {
+			filter:    StatusFilter{Status: "started"},
+			wantNames: []string{"Api"},
+		},
+		"success case - filters combine": {
+			filter:    StatusFilter{Status: "failed", Language: "java"},
+			wantNames: []string{"batch"},
+		},
+		"success case - connection matches the label": {
+			filter:    StatusFilter{Connection: "cloud"},
+			wantNames: []string{"Api"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			report := mockStatusReport()
+			report.Filter(test.filter)
+			assert.Equal(t, test.wantNames, statusNames(report))
+		})
+	}
+}
+
+func TestStatusReportSort(t *testing.T) {
+	tests := map[string]struct {
+		column    string
+		wantNames []string
+		wantErrOp string
+	}{
+		"success case - sorts by name ignoring case": {
+			column:    StatusSortName,
+			wantNames: []string{"Api", "batch", "web"},
+		},
+		"success case - sorts by language then name": {
+			column:    StatusSortLanguage,
+			wantNames: []string{"Api", "batch", "web"},
+		},
+		"success case - sorts by status then name": {
+			column:    StatusSortStatus,
+			wantNames: []string{"Api", "batch", "web"},
+		},
+		"success case - sorts by connection label then name": {
+			column:    StatusSortConnection,
+			wantNames: []string{"Api", "batch", "web"},
+		},
+		"success case - sorts most recently synced first": {
+			column:    StatusSortSync,
+			wantNames: []string{"Api", "web", "batch"},
+		},
+		"fail case - unknown column": {
+			column:    "port",
+			wantNames: []string{"web", "Api", "batch"},
+			wantErrOp: errOpInvalidOptions,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			report := mockStatusReport()
+			err := report.Sort(test.column)
+			if test.wantErrOp != "" {
+				assert.Equal(t, test.wantErrOp, err.Op)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.wantNames, statusNames(report))
+		})
+	}
+}
+
+func TestBuildStatusReport(t *testing.T) {
+	conList := []connections.Connection{{ID: "slow", Label: "Slow"}, {ID: "down", Label: "Down"}, {ID: "fast", Label: "Fast"}}
+	started := make(chan string, len(conList))
+	getProjects := func(conInfo *connections.Connection) ([]Project, *ProjectError) {
+		started <- conInfo.ID
+		switch conInfo.ID {
+		case "slow":
+			// the other connections are queried while this one is
+			deadline := time.Now().Add(5 * time.Second)
+			for len(started) < len(conList) && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			if len(started) < len(conList) {
+				err := errors.New("connections were queried one at a time")
+				return nil, &ProjectError{errOpRequest, err, err.Error()}
+			}
+			return []Project{{Name: "api"}}, nil
+		case "down":
+			err := errors.New("connection refused")
+			return nil, &ProjectError{errOpRequest, err, err.Error()}
+		}
+		return []Project{{Name: "web"}}, nil
+	}
+
+	report := buildStatusReport(conList, getProjects)
+	assert.Equal(t, []string{"api", "web"}, statusNames(report))
+	assert.Equal(t, "slow", report.Projects[0].ConnectionID)
+	assert.Equal(t, []ConnectionStatus{{"down", "Down", "connection refused"}}, report.Errors)
+}
+
+func TestConnectionMatches(t *testing.T) {
+	assert.True(t, connectionMatches("", "remote1", "Cloud"))
+	assert.True(t, connectionMatches("REMOTE1", "remote1", "Cloud"))
+	assert.True(t, connectionMatches("cloud", "remote1", "Cloud"))
+	assert.False(t, connectionMatches("local", "remote1", "Cloud"))
+}
@@ -23,13 +23,18 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
 
@@ -44,10 +49,11 @@ type (
 
 	// FileUploadMsg is the message sent on uploading a file
 	FileUploadMsg struct {
-		IsDirectory  bool   `json:"isDirectory"`
-		Mode         uint   `json:"mode"`
-		RelativePath string `json:"path"`
-		Message      string `json:"msg"`
+		IsDirectory   bool   `json:"isDirectory"`
+		Mode          uint   `json:"mode"`
+		RelativePath  string `json:"path"`
+		Message       string `json:"msg"`
+		SymlinkTarget string `json:"symlinkTarget,omitempty"` // set when the file is a symlink to be recreated on PFE
 	}
 
 	// UploadedFile is the file to sync
@@ -59,17 +65,27 @@ type (
 
 	// SyncResponse is the status of the file syncing
 	SyncResponse struct {
-		Status        string         `json:"status"`
-		StatusCode    int            `json:"statusCode"`
-		UploadedFiles []UploadedFile `json:"uploadedFiles"`
+		Status             string         `json:"status"`
+		StatusCode         int            `json:"statusCode"`
+		UploadedFiles      []UploadedFile `json:"uploadedFiles"`
+		DeletedFiles       []string       `json:"deletedFiles"`
+		DeletedDirectories []string       `json:"deletedDirectories"`
+	}
+
+	// SyncPlan is what a sync would send to PFE, without sending it
+	SyncPlan struct {
+		Added              []string `json:"added"`
+		Modified           []string `json:"modified"`
+		Deleted            []string `json:"deleted"`
+		DeletedDirectories []string `json:"deletedDirectories"`
 	}
 
 	// walkerInfo is the input struct to the walker function
 	walkerInfo struct {
-		Path         string   // the path of the current file
-		os.FileInfo           // the FileInfo of the current file
-		IgnoredPaths []string // paths to ignore
-		LastSync     int64    // last sync time
+		Path         string         // the path the current file is read from
+		os.FileInfo                 // the FileInfo of the current file
+		IgnoredPaths *ignoreMatcher // paths to ignore
+		LastSync     int64          // last sync time, only used if there is no sync manifest
 	}
 
 	// SyncInfo contains the information from a project sync
@@ -78,6 +94,34 @@ type (
 		directoryList    []string
 		modifiedList     []string
 		UploadedFileList []UploadedFile
+		manifest         *syncManifest
+		failedUploads    int // readable files that could not be uploaded
+	}
+
+	// syncOptions controls which files syncFiles uploads and how
+	syncOptions struct {
+		lastSync    int64             // last sync time, only used if there is no sync manifest
+		manifest    *syncManifest     // the files PFE held after the last sync
+		concurrency int               // the maximum number of files to upload at once
+		pfeSupports func(string) bool // whether PFE has a capability, only checked when it would be used
+		journal     *syncJournal      // the files already uploaded by an unfinished sync
+	}
+
+	// syncChanges is what needs syncing, found by walking a project
+	syncChanges struct {
+		fileList      []string
+		directoryList []string
+		uploads       []syncUpload
+		manifest      *syncManifest // the unchanged files, to be added to as files are uploaded
+	}
+
+	// syncUpload is a file found by the walker that needs uploading
+	syncUpload struct {
+		path         string            // the path of the file on disk
+		relativePath string            // the path of the file in the project
+		entry        syncManifestEntry // the content of the file, recorded once uploaded
+		hashErr      error             // set if the content of the file could not be read
+		err          error             // set if the file could not be walked
 	}
 
 	// refPath is a referenced file path to sync
@@ -92,29 +136,94 @@ type (
 	}
 )
 
+// DefaultSyncConcurrency is the number of files uploaded at once when syncing a project
+const DefaultSyncConcurrency = 4
+
 // SyncProject syncs a project with its remote connection
 func SyncProject(c *cli.Context) (*SyncResponse, *ProjectError) {
-	var currentSyncTime = time.Now().UnixNano() / 1000000
 	projectPath := strings.TrimSpace(c.String("path"))
 	projectID := strings.TrimSpace(c.String("id"))
 	synctime := int64(c.Int("time"))
 
-	conID, projErr := GetConnectionID(projectID)
+	connection, conURL, projErr := getProjectConnection(projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
 
+	projErr = checkProjectDirExists(projectPath, projectID, connection, conURL)
 	if projErr != nil {
 		return nil, projErr
 	}
 
+	discardSyncManifestForFullSync(c, projectID)
+	concurrency := getSyncConcurrency(c, projectPath)
+	return syncProject(projectPath, projectID, connection, conURL, synctime, concurrency)
+}
+
+// getSyncConcurrency : The number of files to upload at once, from the concurrency flag if it is given,
+// otherwise from the project's .cw-settings
+func getSyncConcurrency(c *cli.Context, projectPath string) int {
+	if c.IsSet("concurrency") {
+		return c.Int("concurrency")
+	}
+	return projectSyncConcurrency(projectPath)
+}
+
+// projectSyncConcurrency : The number of files to upload at once set by a project's .cw-settings,
+// or DefaultSyncConcurrency if it isn't set to a number greater than 0
+func projectSyncConcurrency(projectPath string) int {
+	cwSettings, _ := readCWSettings(projectPath)
+	concurrency, err := strconv.Atoi(cwSettings.SyncConcurrency)
+	if err != nil || concurrency < 1 {
+		return DefaultSyncConcurrency
+	}
+	return concurrency
+}
+
+// isFullSync : Check whether a full sync was asked for with a last sync time of 0. Any other last sync time
+// is only used when there is no sync manifest
+func isFullSync(c *cli.Context) bool {
+	return c.IsSet("time") && c.Int("time") == 0
+}
+
+// discardSyncManifestForFullSync : A full sync uploads every file, so the sync manifest and any
+// unfinished sync are discarded
+func discardSyncManifestForFullSync(c *cli.Context, projectID string) {
+	if isFullSync(c) {
+		RemoveSyncManifest(projectID)
+	}
+}
+
+// pfeCapabilityCheck : Check whether the PFE of a connection has a capability. The capabilities are only
+// fetched from PFE the first time they are needed
+func pfeCapabilityCheck(client utils.HTTPClient, connection *connections.Connection, conURL string) func(string) bool {
+	return func(capability string) bool {
+		return apiroutes.PFESupports(client, connection, conURL, capability)
+	}
+}
+
+// getProjectConnection : Get the connection a project is bound to, and its PFE URL
+func getProjectConnection(projectID string) (*connections.Connection, string, *ProjectError) {
+	conID, projErr := GetConnectionID(projectID)
+
+	if projErr != nil {
+		return nil, "", projErr
+	}
+
 	connection, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
-		return nil, &ProjectError{errOpConNotFound, conInfoErr, conInfoErr.Desc}
+		return nil, "", &ProjectError{errOpConNotFound, conInfoErr, conInfoErr.Desc}
 	}
 
 	conURL, conURLErr := config.PFEOriginFromConnection(connection)
 	if conURLErr != nil {
-		return nil, &ProjectError{errOpConNotFound, conURLErr.Err, conURLErr.Desc}
+		return nil, "", &ProjectError{errOpConNotFound, conURLErr.Err, conURLErr.Desc}
 	}
+	return connection, conURL, nil
+}
 
+// checkProjectDirExists : Return an error if the local project directory does not exist
+func checkProjectDirExists(projectPath string, projectID string, connection *connections.Connection, conURL string) *ProjectError {
 	// if local path doesn't exist but is equal to the locOnDisk, the directory has likely been deleted
 	// emit this message to the UI socket by calling the PFE /missingLocalDir API
 	pathExists := utils.PathExists(projectPath)
@@ -122,36 +231,75 @@ func SyncProject(c *cli.Context) (*SyncResponse, *ProjectError) {
 	if !pathExists {
 		projectInfo, err := GetProjectFromID(&http.Client{}, connection, conURL, projectID)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		newErr := fmt.Errorf(textProjectPathDoesNotExist)
 
 		if projectPath != projectInfo.LocationOnDisk {
-			return nil, &ProjectError{errBadPath, newErr, newErr.Error()}
+			return &ProjectError{errBadPath, newErr, newErr.Error()}
 		}
 
 		err = handleMissingProjectDir(&http.Client{}, connection, conURL, projectID)
 		if err != nil {
-			return nil, &ProjectError{errBadPath, err, err.Error()}
+			return &ProjectError{errBadPath, err, err.Error()}
 		}
 
-		return nil, &ProjectError{errBadPath, newErr, newErr.Error()}
+		return &ProjectError{errBadPath, newErr, newErr.Error()}
 	}
+	return nil
+}
+
+// syncProject : Upload the files that have changed in a project and tell PFE the sync is complete
+func syncProject(projectPath string, projectID string, connection *connections.Connection, conURL string, synctime int64, concurrency int) (*SyncResponse, *ProjectError) {
+	var currentSyncTime = time.Now().UnixNano() / 1000000
+
+	// resume the last sync if it didn't complete, skipping the files it uploaded
+	journal := loadSyncJournal(projectID)
+	defer journal.close()
 
-	// Sync all the necessary project files
-	syncInfo, syncErr := syncFiles(&http.Client{}, projectPath, projectID, conURL, synctime, connection)
+	// Sync all the project files whose content differs from the last sync
+	options := syncOptions{
+		lastSync:    synctime,
+		manifest:    loadSyncManifest(projectID),
+		concurrency: concurrency,
+		pfeSupports: pfeCapabilityCheck(&http.Client{}, connection, conURL),
+		journal:     journal,
+	}
+	syncInfo, syncErr := syncFiles(&http.Client{}, projectPath, projectID, conURL, connection, options)
+	if syncInfo == nil {
+		return nil, syncErr
+	}
 
 	// Add a check here for files that have been imported into the project, compare lists of files
 	BeforeFileList, err := GetProjectFileList(&http.Client{}, connection, conURL, projectID)
+	deletedFiles, deletedDirectories := findDeletedPaths(options.manifest, BeforeFileList, syncInfo.fileList, syncInfo.directoryList)
 	if err == nil {
-		added := findNewFiles(&http.Client{}, projectID, BeforeFileList, syncInfo.fileList, projectPath, connection, conURL)
+		added, results := findNewFiles(&http.Client{}, projectID, BeforeFileList, syncInfo.fileList, projectPath, connection, conURL, concurrency)
 		// Add any new files to the modifiedList
 		for _, file := range added {
 			syncInfo.modifiedList = append(syncInfo.modifiedList, file)
 		}
+		for _, result := range results {
+			if result.StatusCode != http.StatusOK {
+				syncInfo.failedUploads++
+			}
+		}
 	}
 
-	// Complete the upload
+	// Completing the upload with files missing would leave PFE building a partial project,
+	// so stop here and let the next sync resume from the journal
+	if syncInfo.failedUploads > 0 {
+		text := fmt.Sprintf("%d files failed to upload, sync the project again to resume", syncInfo.failedUploads)
+		response := SyncResponse{
+			UploadedFiles:      syncInfo.UploadedFileList,
+			DeletedFiles:       deletedFiles,
+			DeletedDirectories: deletedDirectories,
+			Status:             "Incomplete",
+		}
+		return &response, &ProjectError{errOpSync, errors.New(text), text}
+	}
+
+	// Complete the upload. PFE removes the files and directories that aren't in the lists
 	completeRequest := CompleteRequest{
 		FileList:      syncInfo.fileList,
 		DirectoryList: syncInfo.directoryList,
@@ -159,54 +307,240 @@ func SyncProject(c *cli.Context) (*SyncResponse, *ProjectError) {
 		TimeStamp:     currentSyncTime,
 	}
 	completeStatus, completeStatusCode := completeUpload(&http.Client{}, projectID, completeRequest, connection, conURL)
+
+	// Only record what was uploaded once PFE has accepted the sync, otherwise the
+	// next sync must send the same changes again
+	if completeStatusCode == http.StatusOK || completeStatusCode == http.StatusAccepted {
+		saveSyncManifest(projectID, syncInfo.manifest)
+		journal.close()
+		removeSyncJournal(projectID)
+	}
+
 	response := SyncResponse{
-		UploadedFiles: syncInfo.UploadedFileList,
-		Status:        completeStatus,
-		StatusCode:    completeStatusCode,
+		UploadedFiles:      syncInfo.UploadedFileList,
+		DeletedFiles:       deletedFiles,
+		DeletedDirectories: deletedDirectories,
+		Status:             completeStatus,
+		StatusCode:         completeStatusCode,
 	}
 
 	return &response, syncErr
 }
 
-func syncFiles(client utils.HTTPClient, projectPath string, projectID string, conURL string, synctime int64, connection *connections.Connection) (*SyncInfo, *ProjectError) {
-	var fileList []string
-	var directoryList []string
+// PlanSync : Find the files a sync would add, modify and delete, without uploading anything
+func PlanSync(c *cli.Context) (*SyncPlan, *ProjectError) {
+	projectPath := strings.TrimSpace(c.String("path"))
+	projectID := strings.TrimSpace(c.String("id"))
+	synctime := int64(c.Int("time"))
+
+	connection, conURL, projErr := getProjectConnection(projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	projErr = checkProjectDirExists(projectPath, projectID, connection, conURL)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	options := syncOptions{lastSync: synctime, manifest: loadSyncManifest(projectID), pfeSupports: pfeCapabilityCheck(&http.Client{}, connection, conURL)}
+	if isFullSync(c) {
+		options.manifest = newSyncManifest()
+	}
+	changes, changesErr := findChangedFiles(projectPath, options)
+	if changes == nil {
+		return nil, changesErr
+	}
+
+	// without a manifest, the files PFE holds are the only record of what was synced
+	serverFiles, err := GetProjectFileList(&http.Client{}, connection, conURL, projectID)
+	if err != nil && !options.manifest.exists {
+		return nil, err
+	}
+	return planSync(options.manifest, changes, serverFiles), changesErr
+}
+
+// planSync : Sort the changes found in a project into the files that are new to PFE,
+// the files that have changed, and the files and directories that have been removed
+func planSync(previous *syncManifest, changes *syncChanges, serverFiles []string) *SyncPlan {
+	plan := SyncPlan{Added: []string{}, Modified: []string{}}
+	known := previousFiles(previous, serverFiles)
+	serverSet := toSet(serverFiles)
+	planned := map[string]bool{}
+
+	for _, upload := range changes.uploads {
+		if upload.err != nil {
+			continue
+		}
+		if known[upload.relativePath] {
+			plan.Modified = append(plan.Modified, upload.relativePath)
+		} else {
+			plan.Added = append(plan.Added, upload.relativePath)
+		}
+		planned[upload.relativePath] = true
+	}
+
+	// unchanged files that PFE doesn't have are uploaded too, as in findNewFiles
+	if serverFiles != nil {
+		for _, file := range changes.fileList {
+			if !planned[file] && !serverSet[file] {
+				plan.Added = append(plan.Added, file)
+			}
+		}
+	}
+
+	plan.Deleted, plan.DeletedDirectories = findDeletedPaths(previous, serverFiles, changes.fileList, changes.directoryList)
+	return &plan
+}
+
+// findDeletedPaths : Find the files and directories that were synced before but no longer
+// exist in the project. The manifest is used when there is one, otherwise the files held by PFE
+func findDeletedPaths(previous *syncManifest, serverFiles []string, fileList []string, directoryList []string) ([]string, []string) {
+	deletedFiles := []string{}
+	deletedDirectories := []string{}
+	files := toSet(fileList)
+	directories := toSet(directoryList)
+
+	for file := range previousFiles(previous, serverFiles) {
+		if !files[file] && !directories[file] {
+			deletedFiles = append(deletedFiles, file)
+		}
+	}
+	if previous.exists {
+		for _, directory := range previous.Directories {
+			if !directories[directory] {
+				deletedDirectories = append(deletedDirectories, directory)
+			}
+		}
+	}
+
+	sort.Strings(deletedFiles)
+	sort.Strings(deletedDirectories)
+	return deletedFiles, deletedDirectories
+}
+
+// previousFiles : Get the set of files held by PFE after the last sync
+func previousFiles(previous *syncManifest, serverFiles []string) map[string]bool {
+	if !previous.exists {
+		return toSet(serverFiles)
+	}
+	known := map[string]bool{}
+	for file := range previous.Files {
+		known[file] = true
+	}
+	return known
+}
+
+func toSet(slice []string) map[string]bool {
+	set := make(map[string]bool, len(slice))
+	for _, item := range slice {
+		set[item] = true
+	}
+	return set
+}
+
+func syncFiles(client utils.HTTPClient, projectPath string, projectID string, conURL string, connection *connections.Connection, options syncOptions) (*SyncInfo, *ProjectError) {
 	var modifiedList []string
 	var uploadedFiles []UploadedFile
+	failedUploads := 0
+
+	changes, projErr := findChangedFiles(projectPath, options)
+	if changes == nil {
+		return nil, projErr
+	}
+
+	// files uploaded by an unfinished sync are still modified, but don't need uploading again
+	var uploads []syncUpload
+	for _, upload := range changes.uploads {
+		if upload.err == nil && upload.hashErr == nil && options.journal.uploaded(upload.relativePath, upload.entry) {
+			modifiedList = append(modifiedList, upload.relativePath)
+			changes.manifest.Files[upload.relativePath] = upload.entry
+			continue
+		}
+		uploads = append(uploads, upload)
+	}
+
+	// upload the modified files, keeping the results in the order the files were found
+	results := uploadChangedFiles(client, projectID, uploads, connection, conURL, options)
+	for i, upload := range uploads {
+		uploadedFiles = append(uploadedFiles, results[i])
+		if upload.err != nil {
+			continue
+		}
+		// Create list of all modfied files
+		modifiedList = append(modifiedList, upload.relativePath)
+
+		// keep failed uploads in the manifest, marked unsynced, so they are retried next sync
+		// and reported as deleted if they are removed before then
+		entry := upload.entry
+		if upload.hashErr != nil || results[i].StatusCode != http.StatusOK {
+			entry.Unsynced = true
+		}
+		changes.manifest.Files[upload.relativePath] = entry
+		if upload.hashErr == nil && results[i].StatusCode != http.StatusOK {
+			failedUploads++
+		}
+	}
+
+	return &SyncInfo{changes.fileList, changes.directoryList, modifiedList, uploadedFiles, changes.manifest, failedUploads}, projErr
+}
+
+// findChangedFiles : Walk a project to find the files and directories to sync, and which of the files need uploading
+func findChangedFiles(projectPath string, options syncOptions) (*syncChanges, *ProjectError) {
+	var fileList []string
+	var directoryList []string
+
+	// files to upload once the walk is complete, in the order they were found
+	var uploads []syncUpload
+
+	// the manifest to save after this sync, containing only files that still exist
+	nextManifest := newSyncManifest()
 
 	refPathsChanged := false
 
 	// define a walker function
 	walker := func(path string, info walkerInfo, err error) error {
-		if err != nil {
-			return err
-			// TODO - How to handle *some* files being unreadable
-		}
-
 		// If it is the top level directory ignore it
 		if path == projectPath {
-			return nil
+			return err
 		}
 
 		// use ToSlash to try and get both Windows and *NIX paths to be *NIX for pfe
 		relativePath := filepath.ToSlash(path[(len(projectPath) + 1):])
 
+		// record files that can't be read as failed rather than abandoning the sync
+		if err != nil {
+			uploads = append(uploads, syncUpload{path: info.Path, relativePath: relativePath, err: err})
+			if info.FileInfo != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		if !info.IsDir() {
-			shouldIgnore := ignoreFileOrDirectory(relativePath, false, info.IgnoredPaths)
-			if shouldIgnore {
+			if info.IgnoredPaths.match(relativePath, false) != nil {
 				return nil
 			}
 			// Create list of all files for a project
 			fileList = append(fileList, relativePath)
 
-			// get time file was modified in milliseconds since epoch
-			modifiedmillis := info.ModTime().UnixNano() / 1000000
-			// Has this file been modified since last sync
-			if modifiedmillis > info.LastSync {
-				uploadResponse := syncFile(&http.Client{}, projectID, projectPath, info.Path, connection, conURL)
-				uploadedFiles = append(uploadedFiles, uploadResponse)
-				// Create list of all modfied files
-				modifiedList = append(modifiedList, relativePath)
+			// Has the content of this file changed since last sync
+			entry, hashErr := hashWalkedFile(info.Path, info.FileInfo)
+			modified, known := options.manifest.hasChanged(relativePath, entry)
+			if !known {
+				// no manifest saved for this project yet, so fall back to
+				// comparing the time file was modified in milliseconds since epoch
+				modifiedmillis := info.ModTime().UnixNano() / 1000000
+				modified = modifiedmillis > info.LastSync
+			}
+			if hashErr != nil {
+				modified = true
+			}
+
+			if !modified {
+				nextManifest.Files[relativePath] = entry
+			} else {
+				uploads = append(uploads, syncUpload{path: info.Path, relativePath: relativePath, entry: entry, hashErr: hashErr})
 
 				// if this file changed, it should force referenced files to re-sync
 				if relativePath == ".cw-refpaths.json" {
@@ -214,8 +548,7 @@ func syncFiles(client utils.HTTPClient, projectPath string, projectID string, co
 				}
 			}
 		} else {
-			shouldIgnore := ignoreFileOrDirectory(relativePath, true, info.IgnoredPaths)
-			if shouldIgnore {
+			if info.IgnoredPaths.match(relativePath, true) != nil {
 				return filepath.SkipDir
 			}
 			directoryList = append(directoryList, relativePath)
@@ -223,26 +556,29 @@ func syncFiles(client utils.HTTPClient, projectPath string, projectID string, co
 		return nil
 	}
 
-	// read the ignored and referenced paths into lists
-	cwSettingsIgnoredPathsList := retrieveIgnoredPathsList(projectPath)
+	// read the ignore rules and referenced paths
+	cwSettingsIgnoredPaths := loadIgnoreMatcher(projectPath)
 	cwRefPathsList := retrieveRefPathsList(projectPath)
 
-	// initialize a combined list, prime it with ignored paths from .cw-settings
-	// then append with referenced "To" paths
-	cwCombinedIgnoredPathsList := append([]string{}, cwSettingsIgnoredPathsList...)
-	for _, refPath := range cwRefPathsList {
-		cwCombinedIgnoredPathsList = append(cwCombinedIgnoredPathsList, refPath.To)
+	// combine the ignore rules with the referenced "To" paths
+	cwCombinedIgnoredPaths := cwSettingsIgnoredPaths.withRefPaths(cwRefPathsList)
+
+	symlinks := symlinkPolicy(projectPath)
+	if symlinks == symlinksLink && (options.pfeSupports == nil || !options.pfeSupports(apiroutes.CapabilityUploadSymlink)) {
+		// a PFE that can't recreate links would write their targets' paths as the files' content
+		logr.Traceln("PFE does not support uploading symlinks, so they are followed")
+		symlinks = symlinksFollow
 	}
 
 	// first sync files that are physically in the project
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := walkPath(projectPath, projectPath, symlinks, func(path string, realPath string, info os.FileInfo, err error) error {
 		// use combined ignored paths here, files in the project that
 		// are also the target of a reference should not be synced
 		wInfo := walkerInfo{
-			path,
+			realPath,
 			info,
-			cwCombinedIgnoredPathsList,
-			synctime,
+			cwCombinedIgnoredPaths,
+			options.lastSync,
 		}
 		return walker(path, wInfo, err)
 	})
@@ -262,71 +598,110 @@ func syncFiles(client utils.HTTPClient, projectPath string, projectID string, co
 			from = filepath.Join(projectPath, from)
 		}
 
-		// get info on the referenced file; skip invalid paths
-		info, err := os.Stat(from)
-		if err != nil || info.IsDir() {
+		// check the referenced file or directory exists; skip invalid paths
+		_, err := os.Stat(from)
+		if err != nil {
 			text := fmt.Sprintf("invalid file reference %q: %v\n", from, err)
 			errText += text
 			continue
 		}
 
-		lastSync := synctime
+		lastSync := options.lastSync
 		// force re-sync if .cw-refpaths.json itself was changed
 		if refPathsChanged {
 			lastSync = 0
 		}
 
-		// now pass it to the walker function
-		wInfo := walkerInfo{
-			from,
-			info,
-			cwSettingsIgnoredPathsList,
-			lastSync,
-		}
-		// "To" path is relative to the project
-		walker(filepath.Join(projectPath, refPath.To), wInfo, nil)
+		// now walk it, "To" path is relative to the project
+		walkPath(filepath.Join(projectPath, refPath.To), from, symlinks, func(path string, realPath string, info os.FileInfo, err error) error {
+			wInfo := walkerInfo{
+				realPath,
+				info,
+				cwSettingsIgnoredPaths,
+				lastSync,
+			}
+			return walker(path, wInfo, err)
+		})
 	}
 
+	nextManifest.Directories = directoryList
+	changes := &syncChanges{fileList, directoryList, uploads, nextManifest}
+
 	if errText != "" {
-		return &SyncInfo{fileList, directoryList, modifiedList, uploadedFiles}, &ProjectError{errOpSyncRef, errors.New(errText), errText}
+		return changes, &ProjectError{errOpSyncRef, errors.New(errText), errText}
 	}
 
-	return &SyncInfo{fileList, directoryList, modifiedList, uploadedFiles}, nil
+	return changes, nil
+}
+
+// uploadFiles : Upload files to PFE using up to concurrency uploads at once,
+// returning a result for every file in the same order as uploads
+func uploadFiles(client utils.HTTPClient, projectID string, uploads []syncUpload, connection *connections.Connection, conURL string, concurrency int, journal *syncJournal) []UploadedFile {
+	results := make([]UploadedFile, len(uploads))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				upload := uploads[i]
+				if upload.err != nil {
+					results[i] = UploadedFile{FilePath: upload.relativePath, Status: "Failed", StatusCode: 0}
+					continue
+				}
+				results[i] = syncFile(client, projectID, upload.path, upload.relativePath, connection, conURL)
+				if results[i].StatusCode == http.StatusOK && upload.hashErr == nil {
+					journal.record(upload.relativePath, upload.entry)
+				}
+			}
+		}()
+	}
+
+	for i := range uploads {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
 }
 
 func completeUpload(client utils.HTTPClient, projectID string, completeRequest CompleteRequest, conInfo *connections.Connection, conURL string) (string, int) {
 	uploadEndURL := conURL + "/api/v1/projects/" + projectID + "/upload/end"
 	jsonPayload, _ := json.Marshal(&completeRequest)
-	req, err := http.NewRequest("POST", uploadEndURL, bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		fmt.Printf("error creating request %v\n", err)
-		return err.Error(), 0
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	resp, httpSecError := sechttp.DispatchHTTPRequest(client, req, conInfo)
-	if httpSecError != nil {
-		fmt.Printf("error making request  %v\n", httpSecError)
-		return httpSecError.Desc, 0
+	resp, projErr := dispatchWithRetry(client, conInfo, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", uploadEndURL, bytes.NewBuffer(jsonPayload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if projErr != nil {
+		fmt.Printf("error making request  %v\n", projErr.Desc)
+		return projErr.Desc, 0
 	}
 	defer resp.Body.Close()
 
 	return resp.Status, resp.StatusCode
 }
 
-// Retrieve the ignoredPaths list from a .cw-settings file
-func retrieveIgnoredPathsList(projectPath string) []string {
+// Read the .cw-settings file of a project, returning false if it is missing or invalid
+func readCWSettings(projectPath string) (CWSettings, bool) {
 	cwSettingsPath := filepath.Join(projectPath, ".cw-settings")
-	var cwSettingsIgnoredPathsList []string
+	var cwSettingsJSON CWSettings
 	if _, err := os.Stat(cwSettingsPath); !os.IsNotExist(err) {
 		plan, _ := ioutil.ReadFile(cwSettingsPath)
-		var cwSettingsJSON CWSettings
 		err = json.Unmarshal(plan, &cwSettingsJSON)
 		if err == nil {
-			cwSettingsIgnoredPathsList = cwSettingsJSON.IgnoredPaths
+			return cwSettingsJSON, true
 		}
 	}
-	return cwSettingsIgnoredPathsList
+	return CWSettings{}, false
 }
 
 // Retrieve the refPaths list from a .cw-refpaths.json file
@@ -344,26 +719,6 @@ func retrieveRefPathsList(projectPath string) []refPath {
 	return cwRefPathsList
 }
 
-func ignoreFileOrDirectory(name string, isDir bool, cwSettingsIgnoredPathsList []string) bool {
-	isFileInIgnoredList := false
-	for _, fileName := range cwSettingsIgnoredPathsList {
-		fileName = filepath.Clean(fileName)
-		// remove preceding slash from older versions of cw-settings
-		if strings.HasPrefix(fileName, "/") {
-			fileName = string([]rune(fileName)[1:])
-		}
-		matched, err := filepath.Match(fileName, name)
-		if err != nil {
-			return false
-		}
-		if matched {
-			isFileInIgnoredList = true
-			break
-		}
-	}
-	return isFileInIgnoredList
-}
-
 // handleMissingProjectDir : Respond to a local project dir not existing
 func handleMissingProjectDir(httpClient utils.HTTPClient, connection *connections.Connection, url, projectID string) *ProjectError {
 	req, requestErr := http.NewRequest("POST", url+"/api/v1/projects/"+projectID+"/missingLocalDir", nil)
@@ -385,16 +740,18 @@ func handleMissingProjectDir(httpClient utils.HTTPClient, connection *connection
 	return nil
 }
 
-func findNewFiles(client utils.HTTPClient, projectID string, beforefiles []string, afterfiles []string, projectPath string, connection *connections.Connection, conURL string) []string {
+func findNewFiles(client utils.HTTPClient, projectID string, beforefiles []string, afterfiles []string, projectPath string, connection *connections.Connection, conURL string, concurrency int) ([]string, []UploadedFile) {
 	var newfiles []string
+	var uploads []syncUpload
 	for _, filename := range afterfiles {
 		if !existsIn(filename, beforefiles) {
 			fullPath := filepath.Join(projectPath, filename)
-			syncFile(&http.Client{}, projectID, projectPath, fullPath, connection, conURL)
+			uploads = append(uploads, syncUpload{path: fullPath, relativePath: filename})
 			newfiles = append(newfiles, filename)
 		}
 	}
-	return newfiles
+	results := uploadFiles(client, projectID, uploads, connection, conURL, concurrency, nil)
+	return newfiles, results
 }
 
 func existsIn(value string, slice []string) bool {
@@ -406,37 +763,36 @@ func existsIn(value string, slice []string) bool {
 	return false
 }
 
-func syncFile(client utils.HTTPClient, projectID string, projectPath string, path string, connection *connections.Connection, conURL string) UploadedFile {
-	// use ToSlash to try and get both Windows and *NIX paths to be *NIX for pfe
-	relativePath := filepath.ToSlash(path[(len(projectPath) + 1):])
+func syncFile(client utils.HTTPClient, projectID string, path string, relativePath string, connection *connections.Connection, conURL string) UploadedFile {
 	uploadResponse := UploadedFile{
 		FilePath:   relativePath,
 		Status:     "Failed",
 		StatusCode: 0,
 	}
-	// Retrieve file info
-	fileStat, err := os.Stat(path)
+	// Retrieve file info, without following symlinks that are to be synced as links
+	fileStat, err := os.Lstat(path)
 	if err != nil {
 		return uploadResponse
 	}
 
-	fileContent, err := ioutil.ReadFile(path)
-	// Return here if there is an error reading the file
-	if err != nil {
-		return uploadResponse
-	}
-
-	mode := uint(fileStat.Mode().Perm())
-	if runtime.GOOS == "windows" {
-		mode = 0775
-	}
 	fileUploadBody := FileUploadMsg{
 		IsDirectory:  fileStat.IsDir(),
-		Mode:         mode,
+		Mode:         uint(uploadMode(fileStat)),
 		RelativePath: relativePath,
 		Message:      "",
 	}
 
+	var fileContent []byte
+	if fileStat.Mode()&os.ModeSymlink != 0 {
+		fileUploadBody.SymlinkTarget, err = os.Readlink(path)
+	} else {
+		fileContent, err = ioutil.ReadFile(path)
+	}
+	// Return here if there is an error reading the file
+	if err != nil {
+		return uploadResponse
+	}
+
 	var buffer bytes.Buffer
 	zWriter := zlib.NewWriter(&buffer)
 	zWriter.Write([]byte(fileContent))
@@ -449,12 +805,15 @@ func syncFile(client utils.HTTPClient, projectID string, projectPath string, pat
 	json.NewEncoder(buf).Encode(fileUploadBody)
 
 	projectUploadURL := conURL + "/api/v1/projects/" + projectID + "/upload"
-	// TODO - How do we handle partial success?
-	request, err := http.NewRequest("PUT", projectUploadURL, bytes.NewReader(buf.Bytes()))
-	request.Header.Set("Content-Type", "application/json")
-	resp, httpSecError := sechttp.DispatchHTTPRequest(client, request, connection)
-
-	if httpSecError != nil {
+	resp, projErr := dispatchWithRetry(client, connection, func() (*http.Request, error) {
+		request, err := http.NewRequest("PUT", projectUploadURL, bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Content-Type", "application/json")
+		return request, nil
+	})
+	if projErr != nil {
 		return uploadResponse
 	}
 	defer resp.Body.Close()
@@ -464,3 +823,11 @@ func syncFile(client utils.HTTPClient, projectID string, projectPath string, pat
 		StatusCode: resp.StatusCode,
 	}
 }
+
+// uploadMode : Get the permissions to give a file on PFE
+func uploadMode(fileStat os.FileInfo) os.FileMode {
+	if runtime.GOOS == "windows" {
+		return 0775
+	}
+	return fileStat.Mode().Perm()
+}
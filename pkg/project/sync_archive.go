@@ -0,0 +1,115 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
+)
+
+// bulkUploadThreshold is the number of changed files at which they are sent to PFE
+// as one archive rather than individually, when PFE supports it
+const bulkUploadThreshold = 10
+
+// uploadChangedFiles : Upload files to PFE as a single archive if PFE supports it and
+// there are enough of them, otherwise upload them individually
+func uploadChangedFiles(client utils.HTTPClient, projectID string, uploads []syncUpload, connection *connections.Connection, conURL string, options syncOptions) []UploadedFile {
+	if len(uploads) >= bulkUploadThreshold && options.pfeSupports != nil && options.pfeSupports(apiroutes.CapabilityUploadArchive) {
+		results, err := uploadArchive(client, projectID, uploads, connection, conURL)
+		if err == nil {
+			for _, upload := range uploads {
+				if upload.err == nil && upload.hashErr == nil {
+					options.journal.record(upload.relativePath, upload.entry)
+				}
+			}
+			return results
+		}
+		logr.Tracef("Archive upload failed, uploading files individually: %v\n", err)
+	}
+	return uploadFiles(client, projectID, uploads, connection, conURL, options.concurrency, options.journal)
+}
+
+// uploadArchive : Stream files to PFE as a single tar.gz, returning a result for
+// every file in the same order as uploads
+func uploadArchive(client utils.HTTPClient, projectID string, uploads []syncUpload, connection *connections.Connection, conURL string) ([]UploadedFile, error) {
+	var tarFiles []utils.TarFile
+	for _, upload := range uploads {
+		if upload.err != nil {
+			continue
+		}
+		fileStat, err := os.Lstat(upload.path)
+		if err != nil {
+			return nil, err
+		}
+		tarFile := utils.TarFile{Path: upload.path, Name: upload.relativePath, Mode: uploadMode(fileStat)}
+		if fileStat.Mode()&os.ModeSymlink != 0 {
+			tarFile.Linkname, err = os.Readlink(upload.path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		tarFiles = append(tarFiles, tarFile)
+	}
+
+	resp, err := sendArchive(client, projectID, tarFiles, connection, conURL)
+	if httpSecError, ok := err.(*sechttp.HTTPSecError); ok && httpSecError.IsAuthRetryError() {
+		// the archive was streamed with a rejected access token, so it is written again for the new one
+		logr.Traceln("Archive upload was rejected, sending it again with a new access token")
+		resp, err = sendArchive(client, projectID, tarFiles, connection, conURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("PFE responded with status code %d", resp.StatusCode)
+	}
+
+	results := make([]UploadedFile, len(uploads))
+	for i, upload := range uploads {
+		if upload.err != nil {
+			results[i] = UploadedFile{FilePath: upload.relativePath, Status: "Failed", StatusCode: 0}
+			continue
+		}
+		results[i] = UploadedFile{FilePath: upload.relativePath, Status: resp.Status, StatusCode: resp.StatusCode}
+	}
+	return results, nil
+}
+
+// sendArchive : Write files to PFE as a tar.gz as it is sent, rather than holding it all in memory
+func sendArchive(client utils.HTTPClient, projectID string, tarFiles []utils.TarFile, connection *connections.Connection, conURL string) (*http.Response, error) {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.CloseWithError(utils.WriteTarGz(pipeWriter, tarFiles))
+	}()
+	defer pipeReader.Close()
+
+	projectUploadURL := conURL + "/api/v1/projects/" + projectID + "/upload/archive"
+	request, err := http.NewRequest("PUT", projectUploadURL, pipeReader)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/gzip")
+	resp, httpSecError := sechttp.DispatchHTTPRequest(client, request, connection)
+	if httpSecError != nil {
+		return nil, httpSecError
+	}
+	return resp, nil
+}
@@ -0,0 +1,129 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+// clientMockArchive records the files in an uploaded archive, responding to
+// archive uploads with archiveStatus and to everything else with 200
+type clientMockArchive struct {
+	mutex         sync.Mutex
+	archiveStatus int
+	archiveNames  []string
+	fileUploads   int
+}
+
+func (c *clientMockArchive) Do(req *http.Request) (*http.Response, error) {
+	statusCode := http.StatusOK
+	if strings.HasSuffix(req.URL.Path, "/upload/archive") {
+		statusCode = c.archiveStatus
+		var names []string
+		gzipReader, _ := gzip.NewReader(req.Body)
+		tarReader := tar.NewReader(gzipReader)
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF || err != nil {
+				break
+			}
+			names = append(names, header.Name)
+		}
+		c.mutex.Lock()
+		c.archiveNames = append(c.archiveNames, names...)
+		c.mutex.Unlock()
+	} else {
+		c.mutex.Lock()
+		c.fileUploads++
+		c.mutex.Unlock()
+	}
+	return &http.Response{StatusCode: statusCode, Status: http.StatusText(statusCode), Body: ioutil.NopCloser(bytes.NewReader([]byte{}))}, nil
+}
+
+// supportsAll and supportsNone report every PFE capability as supported, or as not supported
+func supportsAll(string) bool  { return true }
+func supportsNone(string) bool { return false }
+
+func TestUploadChangedFiles(t *testing.T) {
+	testDir := "archive_test_folder_delete_me"
+	os.Mkdir(testDir, 0777)
+	defer cleanupTestFolder(t, testDir)
+	mockConnection := connections.Connection{ID: "local"}
+
+	var uploads []syncUpload
+	var names []string
+	for i := 0; i < bulkUploadThreshold; i++ {
+		name := string(rune('a' + i))
+		filePath := path.Join(testDir, name)
+		ioutil.WriteFile(filePath, []byte(name), 0644)
+		uploads = append(uploads, syncUpload{path: filePath, relativePath: "dir/" + name})
+		names = append(names, "dir/"+name)
+	}
+
+	t.Run("success case - files are sent as one archive when PFE supports it", func(t *testing.T) {
+		mockClient := &clientMockArchive{archiveStatus: http.StatusOK}
+		options := syncOptions{concurrency: DefaultSyncConcurrency, pfeSupports: supportsAll}
+		results := uploadChangedFiles(mockClient, "mockID", uploads, &mockConnection, "http://dummyurl", options)
+
+		assert.Equal(t, names, mockClient.archiveNames)
+		assert.Equal(t, 0, mockClient.fileUploads)
+		assert.Len(t, results, len(uploads))
+		assert.Equal(t, "dir/a", results[0].FilePath)
+		assert.Equal(t, http.StatusOK, results[0].StatusCode)
+	})
+
+	t.Run("success case - files are uploaded individually when PFE does not support archives", func(t *testing.T) {
+		mockClient := &clientMockArchive{archiveStatus: http.StatusOK}
+		options := syncOptions{concurrency: DefaultSyncConcurrency, pfeSupports: supportsNone}
+		results := uploadChangedFiles(mockClient, "mockID", uploads, &mockConnection, "http://dummyurl", options)
+
+		assert.Empty(t, mockClient.archiveNames)
+		assert.Equal(t, len(uploads), mockClient.fileUploads)
+		assert.Len(t, results, len(uploads))
+	})
+
+	t.Run("success case - files are uploaded individually when there are only a few", func(t *testing.T) {
+		mockClient := &clientMockArchive{archiveStatus: http.StatusOK}
+		// PFE isn't asked whether it supports archives
+		supports := func(string) bool {
+			t.Error("PFE capabilities checked for only a few files")
+			return true
+		}
+		options := syncOptions{concurrency: DefaultSyncConcurrency, pfeSupports: supports}
+		uploadChangedFiles(mockClient, "mockID", uploads[:2], &mockConnection, "http://dummyurl", options)
+
+		assert.Empty(t, mockClient.archiveNames)
+		assert.Equal(t, 2, mockClient.fileUploads)
+	})
+
+	t.Run("fail case - files are uploaded individually when the archive upload fails", func(t *testing.T) {
+		mockClient := &clientMockArchive{archiveStatus: http.StatusNotFound}
+		options := syncOptions{concurrency: DefaultSyncConcurrency, pfeSupports: supportsAll}
+		results := uploadChangedFiles(mockClient, "mockID", uploads, &mockConnection, "http://dummyurl", options)
+
+		assert.Equal(t, len(uploads), mockClient.fileUploads)
+		assert.Equal(t, http.StatusOK, results[0].StatusCode)
+	})
+}
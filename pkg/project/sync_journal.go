@@ -0,0 +1,128 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"sync"
+)
+
+type (
+	// syncJournalEntry is a line of the journal, recording a file uploaded by an unfinished sync
+	syncJournalEntry struct {
+		Path string `json:"path"`
+		syncManifestEntry
+	}
+
+	// syncJournal records the files uploaded by a sync that has not yet completed, so that a
+	// sync that fails part way through can be resumed without uploading them again
+	syncJournal struct {
+		filename string
+		entries  map[string]syncManifestEntry
+		file     *os.File
+		mutex    sync.Mutex
+	}
+)
+
+// loadSyncJournal : Load the journal of the last unfinished sync of a project, if there is one
+func loadSyncJournal(projectID string) *syncJournal {
+	journal := &syncJournal{
+		filename: getSyncJournalFilename(projectID),
+		entries:  map[string]syncManifestEntry{},
+	}
+	file, err := os.Open(journal.filename)
+	if err != nil {
+		return journal
+	}
+	defer file.Close()
+
+	// the last line may be incomplete if the sync was interrupted, so skip lines that can't be parsed
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry syncJournalEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+			journal.entries[entry.Path] = entry.syncManifestEntry
+		}
+	}
+	return journal
+}
+
+// uploaded : Check whether a file was uploaded by the unfinished sync and has not changed since
+func (journal *syncJournal) uploaded(relativePath string, entry syncManifestEntry) bool {
+	if journal == nil {
+		return false
+	}
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+	previous, ok := journal.entries[relativePath]
+	return ok && previous == entry
+}
+
+// record : Add an uploaded file to the journal, writing it to disk straight away
+func (journal *syncJournal) record(relativePath string, entry syncManifestEntry) error {
+	if journal == nil {
+		return nil
+	}
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	if journal.file == nil {
+		err := os.MkdirAll(path.Dir(journal.filename), 0755)
+		if err != nil {
+			return err
+		}
+		journal.file, err = os.OpenFile(journal.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		// start on a new line in case the last sync was interrupted part way through a line
+		if info, err := journal.file.Stat(); err == nil && info.Size() > 0 {
+			journal.file.Write([]byte{'\n'})
+		}
+	}
+	line, err := json.Marshal(syncJournalEntry{relativePath, entry})
+	if err != nil {
+		return err
+	}
+	_, err = journal.file.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+	journal.entries[relativePath] = entry
+	return nil
+}
+
+// close : Close the journal file if it was written to
+func (journal *syncJournal) close() {
+	if journal == nil {
+		return
+	}
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+	if journal.file != nil {
+		journal.file.Close()
+		journal.file = nil
+	}
+}
+
+// removeSyncJournal : Remove the journal of a project once its sync has completed
+func removeSyncJournal(projectID string) {
+	os.Remove(getSyncJournalFilename(projectID))
+}
+
+// getSyncJournalFilename : Get full file path of the sync journal for a project
+func getSyncJournalFilename(projectID string) string {
+	return path.Join(getSyncManifestDir(), projectID+".journal")
+}
@@ -0,0 +1,94 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncJournal(t *testing.T) {
+	testDir := "sync_journal_test_folder_delete_me"
+	os.Mkdir(testDir, 0777)
+	defer cleanupTestFolder(t, testDir)
+
+	originalHome := os.Getenv("HOME")
+	absTestDir, _ := os.Getwd()
+	os.Setenv("HOME", path.Join(absTestDir, testDir))
+	defer os.Setenv("HOME", originalHome)
+
+	entry := syncManifestEntry{Size: 5, Hash: "abc"}
+
+	t.Run("success case - recorded uploads are loaded by the next sync", func(t *testing.T) {
+		journal := loadSyncJournal("mockID")
+		assert.False(t, journal.uploaded("file", entry))
+
+		err := journal.record("file", entry)
+		assert.Nil(t, err)
+		journal.close()
+
+		loaded := loadSyncJournal("mockID")
+		assert.True(t, loaded.uploaded("file", entry))
+		assert.False(t, loaded.uploaded("file", syncManifestEntry{Size: 5, Hash: "changed"}))
+	})
+
+	t.Run("success case - an interrupted write is skipped", func(t *testing.T) {
+		file, _ := os.OpenFile(getSyncJournalFilename("mockID"), os.O_APPEND|os.O_WRONLY, 0644)
+		file.WriteString(`{"path":"partial","si`)
+		file.Close()
+
+		loaded := loadSyncJournal("mockID")
+		assert.True(t, loaded.uploaded("file", entry))
+		assert.False(t, loaded.uploaded("partial", entry))
+
+		loaded.record("after", entry)
+		loaded.close()
+		assert.True(t, loadSyncJournal("mockID").uploaded("after", entry))
+	})
+
+	t.Run("success case - the journal is removed with the manifest", func(t *testing.T) {
+		RemoveSyncManifest("mockID")
+		assert.False(t, loadSyncJournal("mockID").uploaded("file", entry))
+	})
+
+	t.Run("success case - files uploaded by an unfinished sync are not uploaded again", func(t *testing.T) {
+		mockProjectPath := path.Join(testDir, "resume")
+		os.Mkdir(mockProjectPath, 0777)
+		ioutil.WriteFile(path.Join(mockProjectPath, "uploaded"), []byte("uploaded before"), 0644)
+		ioutil.WriteFile(path.Join(mockProjectPath, "edited"), []byte("edited since"), 0644)
+
+		journal := loadSyncJournal("resumeID")
+		uploadedEntry, _ := hashFile(path.Join(mockProjectPath, "uploaded"))
+		journal.record("uploaded", uploadedEntry)
+		journal.record("edited", syncManifestEntry{Size: 1, Hash: "old"})
+
+		mockClient := &clientMockFlaky{}
+		mockConnection := connections.Connection{ID: "local"}
+		options := syncOptions{lastSync: 0, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency, journal: journal}
+		got, _ := syncFiles(mockClient, mockProjectPath, "resumeID", "dummyURL", &mockConnection, options)
+		journal.close()
+
+		assert.Equal(t, 1, mockClient.calls)
+		assert.ElementsMatch(t, []string{"uploaded", "edited"}, got.modifiedList)
+		assert.Contains(t, got.manifest.Files, "uploaded")
+		assert.Contains(t, got.manifest.Files, "edited")
+		assert.Equal(t, 0, got.failedUploads)
+
+		editedEntry, _ := hashFile(path.Join(mockProjectPath, "edited"))
+		assert.True(t, loadSyncJournal("resumeID").uploaded("edited", editedEntry))
+	})
+}
@@ -0,0 +1,148 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+)
+
+type (
+	// syncManifestEntry records the content of a file as it was last uploaded, or as it was when
+	// its upload failed, in which case it is unsynced
+	syncManifestEntry struct {
+		Size     int64  `json:"size"`
+		Hash     string `json:"sha256"`
+		Unsynced bool   `json:"unsynced,omitempty"`
+	}
+
+	// syncManifest is the local record of the files PFE holds for a project,
+	// keyed by path relative to the project root
+	syncManifest struct {
+		Files       map[string]syncManifestEntry `json:"files"`
+		Directories []string                     `json:"directories"`
+
+		// exists is false when no manifest has been saved for the project yet
+		exists bool
+	}
+)
+
+// newSyncManifest : Create an empty sync manifest
+func newSyncManifest() *syncManifest {
+	return &syncManifest{Files: map[string]syncManifestEntry{}}
+}
+
+// loadSyncManifest : Load the sync manifest for a project, returning an empty
+// manifest if none has been saved or the saved one cannot be read
+func loadSyncManifest(projectID string) *syncManifest {
+	manifest := newSyncManifest()
+	file, err := ioutil.ReadFile(getSyncManifestFilename(projectID))
+	if err != nil {
+		return manifest
+	}
+	err = json.Unmarshal(file, manifest)
+	if err != nil || manifest.Files == nil {
+		return newSyncManifest()
+	}
+	manifest.exists = true
+	return manifest
+}
+
+// saveSyncManifest : Write the sync manifest for a project to the config directory
+func saveSyncManifest(projectID string, manifest *syncManifest) *ProjectError {
+	err := os.MkdirAll(getSyncManifestDir(), 0755)
+	if err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	err = ioutil.WriteFile(getSyncManifestFilename(projectID), body, 0644)
+	if err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	return nil
+}
+
+// RemoveSyncManifest : Remove the sync manifest for a project, and the journal of any unfinished sync
+func RemoveSyncManifest(projectID string) *ProjectError {
+	removeSyncJournal(projectID)
+	err := os.Remove(getSyncManifestFilename(projectID))
+	if err != nil {
+		return &ProjectError{errOpFileDelete, err, err.Error()}
+	}
+	return nil
+}
+
+// hasChanged : Reports whether the file at relativePath differs from its manifest entry.
+// Files without an entry are new unless no manifest existed, in which case the
+// caller must fall back to another check
+func (manifest *syncManifest) hasChanged(relativePath string, entry syncManifestEntry) (changed bool, known bool) {
+	previous, ok := manifest.Files[relativePath]
+	if !ok {
+		return true, manifest.exists
+	}
+	return previous.Unsynced || previous != entry, true
+}
+
+// hashWalkedFile : Compute the size and SHA-256 of a file found by walkPath. A symlink the walk reports
+// as a link, rather than following it, is recorded by the path it points to, not the content there
+func hashWalkedFile(filePath string, info os.FileInfo) (syncManifestEntry, error) {
+	if info.Mode()&os.ModeSymlink != 0 {
+		return hashSymlink(filePath)
+	}
+	return hashFile(filePath)
+}
+
+// hashSymlink : Compute the size and SHA-256 of the path a symlink points to
+func hashSymlink(linkPath string) (syncManifestEntry, error) {
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return syncManifestEntry{}, err
+	}
+	hash := sha256.Sum256([]byte("symlink:" + target))
+	return syncManifestEntry{Size: int64(len(target)), Hash: hex.EncodeToString(hash[:])}, nil
+}
+
+// hashFile : Compute the size and SHA-256 of the content of a file
+func hashFile(filePath string) (syncManifestEntry, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return syncManifestEntry{}, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return syncManifestEntry{}, err
+	}
+	return syncManifestEntry{Size: size, Hash: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// getSyncManifestDir : Get directory path to the sync manifests
+func getSyncManifestDir() string {
+	return path.Join(connections.GetConnectionConfigDir(), "sync")
+}
+
+// getSyncManifestFilename : Get full file path of the sync manifest for a project
+func getSyncManifestFilename(projectID string) string {
+	return path.Join(getSyncManifestDir(), projectID+".json")
+}
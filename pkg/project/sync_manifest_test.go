@@ -0,0 +1,136 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func TestSyncManifest(t *testing.T) {
+	testDir := "sync_manifest_test_folder_delete_me"
+	os.Mkdir(testDir, 0777)
+	defer cleanupTestFolder(t, testDir)
+
+	originalHome := os.Getenv("HOME")
+	absTestDir, _ := os.Getwd()
+	os.Setenv("HOME", path.Join(absTestDir, testDir))
+	defer os.Setenv("HOME", originalHome)
+
+	t.Run("success case - a manifest that has not been saved is empty and does not exist", func(t *testing.T) {
+		manifest := loadSyncManifest("no-such-project")
+		assert.False(t, manifest.exists)
+		assert.Empty(t, manifest.Files)
+	})
+
+	t.Run("success case - a saved manifest can be loaded and removed", func(t *testing.T) {
+		manifest := newSyncManifest()
+		manifest.Files["src/index.js"] = syncManifestEntry{Size: 5, Hash: "abc"}
+		err := saveSyncManifest("mockID", manifest)
+		assert.Nil(t, err)
+
+		loaded := loadSyncManifest("mockID")
+		assert.True(t, loaded.exists)
+		assert.Equal(t, manifest.Files, loaded.Files)
+
+		err = RemoveSyncManifest("mockID")
+		assert.Nil(t, err)
+		assert.False(t, loadSyncManifest("mockID").exists)
+	})
+
+	t.Run("success case - only a last sync time of 0 discards the manifest", func(t *testing.T) {
+		saveSyncManifest("mockID", newSyncManifest())
+		sync := func(args ...string) {
+			set := flag.NewFlagSet("sync", 0)
+			set.String("time", "", "doc")
+			set.Parse(args)
+			discardSyncManifestForFullSync(cli.NewContext(nil, set, nil), "mockID")
+		}
+		sync()
+		assert.True(t, loadSyncManifest("mockID").exists)
+		sync("--time", "1590000000000")
+		assert.True(t, loadSyncManifest("mockID").exists)
+		sync("--time", "0")
+		assert.False(t, loadSyncManifest("mockID").exists)
+	})
+
+	t.Run("success case - a sync with a non-zero last sync time uses the manifest", func(t *testing.T) {
+		projectPath := path.Join(testDir, "timed-project")
+		os.Mkdir(projectPath, 0777)
+		filePath := path.Join(projectPath, "unchanged")
+		ioutil.WriteFile(filePath, []byte("content"), 0644)
+
+		body := ioutil.NopCloser(bytes.NewReader([]byte{}))
+		mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusOK, Body: body}
+		mockConnection := connections.Connection{ID: "local"}
+		first, _ := syncFiles(mockClient, projectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: 0, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency})
+		saveSyncManifest("mockID", first.manifest)
+
+		// the file is touched after the given last sync time, but its content is the same as last sync
+		lastSync := time.Now().UnixNano() / 1000000
+		future := time.Now().Add(1 * time.Hour)
+		os.Chtimes(filePath, future, future)
+
+		set := flag.NewFlagSet("sync", 0)
+		set.String("time", "", "doc")
+		set.Parse([]string{"--time", strconv.FormatInt(lastSync, 10)})
+		discardSyncManifestForFullSync(cli.NewContext(nil, set, nil), "mockID")
+
+		manifest := loadSyncManifest("mockID")
+		assert.True(t, manifest.exists)
+		got, _ := syncFiles(mockClient, projectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: lastSync, manifest: manifest, concurrency: DefaultSyncConcurrency})
+		assert.Equal(t, []string{"unchanged"}, got.fileList)
+		assert.Empty(t, got.modifiedList)
+	})
+
+	t.Run("success case - hasChanged compares size and hash", func(t *testing.T) {
+		manifest := newSyncManifest()
+		entry := syncManifestEntry{Size: 5, Hash: "abc"}
+		manifest.Files["file"] = entry
+
+		changed, known := manifest.hasChanged("file", entry)
+		assert.False(t, changed)
+		assert.True(t, known)
+
+		changed, _ = manifest.hasChanged("file", syncManifestEntry{Size: 5, Hash: "def"})
+		assert.True(t, changed)
+
+		// new files are unknown until a manifest has been saved
+		_, known = manifest.hasChanged("new-file", entry)
+		assert.False(t, known)
+		manifest.exists = true
+		changed, known = manifest.hasChanged("new-file", entry)
+		assert.True(t, changed)
+		assert.True(t, known)
+	})
+
+	t.Run("success case - hashFile returns the size and sha256 of a file", func(t *testing.T) {
+		filePath := path.Join(testDir, "hashme")
+		ioutil.WriteFile(filePath, []byte("hello"), 0644)
+		entry, err := hashFile(filePath)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(5), entry.Size)
+		assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", entry.Hash)
+	})
+}
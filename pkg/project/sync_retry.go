@@ -0,0 +1,68 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
+)
+
+// syncRetryAttempts is the number of times a sync request is sent to PFE before giving up
+const syncRetryAttempts = 4
+
+// syncRetryDelay is how long to wait before retrying a sync request, doubling after each retry
+var syncRetryDelay = 500 * time.Millisecond
+
+// dispatchWithRetry : Send a request to PFE, retrying with exponential backoff if PFE can't be
+// reached or is temporarily unavailable. newRequest is called for each attempt, so that the
+// request body can be sent again
+func dispatchWithRetry(client utils.HTTPClient, connection *connections.Connection, newRequest func() (*http.Request, error)) (*http.Response, *ProjectError) {
+	delay := syncRetryDelay
+	for attempt := 1; ; attempt++ {
+		request, err := newRequest()
+		if err != nil {
+			return nil, &ProjectError{errOpRequest, err, err.Error()}
+		}
+
+		resp, httpSecError := sechttp.DispatchHTTPRequest(client, request, connection)
+		lastAttempt := attempt >= syncRetryAttempts
+		if httpSecError != nil {
+			if lastAttempt || !httpSecError.IsConnectionError() {
+				return nil, &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+			}
+			logr.Tracef("Unable to reach PFE, retrying in %v: %v\n", delay, httpSecError.Desc)
+		} else {
+			if lastAttempt || !isRetryableStatus(resp.StatusCode) {
+				return resp, nil
+			}
+			resp.Body.Close()
+			logr.Tracef("PFE responded with %v, retrying in %v\n", resp.Status, delay)
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// isRetryableStatus : Check whether a status code means PFE may accept the same request later
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
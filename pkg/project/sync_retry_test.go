@@ -0,0 +1,104 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+// clientMockFlaky fails with err, or responds with failStatus, until it has been called failures times
+type clientMockFlaky struct {
+	failures   int
+	failStatus int
+	err        error
+	calls      int
+	bodies     []string
+}
+
+func (c *clientMockFlaky) Do(req *http.Request) (*http.Response, error) {
+	c.calls++
+	body, _ := ioutil.ReadAll(req.Body)
+	c.bodies = append(c.bodies, string(body))
+	if c.calls <= c.failures {
+		if c.err != nil {
+			return nil, c.err
+		}
+		return &http.Response{StatusCode: c.failStatus, Body: ioutil.NopCloser(bytes.NewReader([]byte{}))}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte{}))}, nil
+}
+
+func TestDispatchWithRetry(t *testing.T) {
+	originalDelay := syncRetryDelay
+	syncRetryDelay = 0
+	defer func() { syncRetryDelay = originalDelay }()
+
+	mockConnection := connections.Connection{ID: "local"}
+	newRequest := func() (*http.Request, error) {
+		return http.NewRequest("PUT", "http://dummyurl/upload", bytes.NewReader([]byte("body")))
+	}
+
+	tests := map[string]struct {
+		client         *clientMockFlaky
+		wantStatusCode int
+		wantErr        bool
+		wantCalls      int
+	}{
+		"success case - unavailable server is retried": {
+			client:         &clientMockFlaky{failures: 2, failStatus: http.StatusServiceUnavailable},
+			wantStatusCode: http.StatusOK,
+			wantCalls:      3,
+		},
+		"success case - connection error is retried": {
+			client:         &clientMockFlaky{failures: 1, err: errors.New("connection reset")},
+			wantStatusCode: http.StatusOK,
+			wantCalls:      2,
+		},
+		"fail case - other status codes are not retried": {
+			client:         &clientMockFlaky{failures: 1, failStatus: http.StatusBadRequest},
+			wantStatusCode: http.StatusBadRequest,
+			wantCalls:      1,
+		},
+		"fail case - gives up after the last attempt": {
+			client:         &clientMockFlaky{failures: 10, failStatus: http.StatusBadGateway},
+			wantStatusCode: http.StatusBadGateway,
+			wantCalls:      syncRetryAttempts,
+		},
+		"fail case - connection error on the last attempt is returned": {
+			client:    &clientMockFlaky{failures: 10, err: errors.New("connection reset")},
+			wantErr:   true,
+			wantCalls: syncRetryAttempts,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp, err := dispatchWithRetry(test.client, &mockConnection, newRequest)
+			assert.Equal(t, test.wantCalls, test.client.calls)
+			if test.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.wantStatusCode, resp.StatusCode)
+			for _, body := range test.client.bodies {
+				assert.Equal(t, "body", body)
+			}
+		})
+	}
+}
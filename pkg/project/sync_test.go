@@ -14,11 +14,13 @@ package project
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path"
+	"sync"
 	"testing"
 	"time"
 
@@ -28,17 +30,37 @@ import (
 )
 
 type (
-	noIgnoredPaths struct {
-		Field1 []string `json:"field1"`
-		Field2 string   `json:"field2"`
+	// clientMockUpload fails uploads of files named "fail" and records how many
+	// uploads were in flight at once
+	clientMockUpload struct {
+		mutex       sync.Mutex
+		inFlight    int
+		maxInFlight int
 	}
+)
 
-	testDirPaths struct {
-		cwSettingsPopulated      string
-		cwSettingsEmpty          string
-		cwSettingsNoIgnoredPaths string
+func (c *clientMockUpload) Do(req *http.Request) (*http.Response, error) {
+	c.mutex.Lock()
+	c.inFlight++
+	if c.inFlight > c.maxInFlight {
+		c.maxInFlight = c.inFlight
 	}
-)
+	c.mutex.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+	var msg FileUploadMsg
+	json.NewDecoder(req.Body).Decode(&msg)
+
+	c.mutex.Lock()
+	c.inFlight--
+	c.mutex.Unlock()
+
+	statusCode := http.StatusOK
+	if path.Base(msg.RelativePath) == "fail" {
+		statusCode = http.StatusInternalServerError
+	}
+	return &http.Response{StatusCode: statusCode, Body: ioutil.NopCloser(bytes.NewReader([]byte{}))}, nil
+}
 
 func TestCompleteUpload(t *testing.T) {
 	tests := map[string]struct {
@@ -69,106 +91,6 @@ func TestCompleteUpload(t *testing.T) {
 	}
 }
 
-func TestIgnoreFileOrDirectory(t *testing.T) {
-	tests := map[string]struct {
-		name             string
-		isDir            bool
-		shouldBeIgnored  bool
-		ignoredPathsList []string
-	}{
-		"success case: directory called node_modules should be ignored as it is in .cw-settings": {
-			name:             "node_modules",
-			isDir:            true,
-			shouldBeIgnored:  true,
-			ignoredPathsList: []string{"node_modules*"},
-		},
-		"success case: directory called load-test-23498729 should be ignored as it is in .cw-settings": {
-			name:             "load-test-23498729",
-			isDir:            true,
-			shouldBeIgnored:  true,
-			ignoredPathsList: []string{"load-test*"},
-		},
-		"success case: directory called not-a-load-test-23498729 should not be ignored": {
-			name:             "not-a-load-test-23498729",
-			isDir:            true,
-			shouldBeIgnored:  false,
-			ignoredPathsList: []string{},
-		},
-		"success case: directory called noddy_modules should not be ignored": {
-			name:             "noddy_modules",
-			isDir:            true,
-			shouldBeIgnored:  false,
-			ignoredPathsList: []string{},
-		},
-		"success case: file called .DS_Store should be ignored as it is in .cw-settings": {
-			name:             ".DS_Store",
-			isDir:            false,
-			shouldBeIgnored:  true,
-			ignoredPathsList: []string{".DS_Store"},
-		},
-		"success case: file called something.swp should be ignored as it is in .cw-settings": {
-			name:             "something.swp",
-			isDir:            false,
-			shouldBeIgnored:  true,
-			ignoredPathsList: []string{"*.swp"},
-		},
-		"success case: file called something.swpnot should not be ignored": {
-			name:             "something.swpnot",
-			isDir:            false,
-			shouldBeIgnored:  false,
-			ignoredPathsList: []string{},
-		},
-		"success case: file called node_modules should not be ignored": {
-			name:             "node_modules",
-			isDir:            false,
-			shouldBeIgnored:  false,
-			ignoredPathsList: []string{},
-		},
-		"success case: directory called .DS_Store should not be ignored": {
-			name:             ".DS_Store",
-			isDir:            true,
-			shouldBeIgnored:  false,
-			ignoredPathsList: []string{},
-		},
-		"success case: path containing noddy_modules should be ignored as it is in .cw-settings": {
-			name:             "noddy_modules",
-			isDir:            true,
-			shouldBeIgnored:  true,
-			ignoredPathsList: []string{"noddy_modules"},
-		},
-		"success case: file called file.iml should be ignored (IntelliJ metadata file, *.iml) as it is in .cw-settings": {
-			name:             "file.iml",
-			isDir:            false,
-			shouldBeIgnored:  true,
-			ignoredPathsList: []string{"*.iml"},
-		},
-		"success case: path containing .idea should be ignored (IntelliJ metadata directory, .idea)": {
-			name:             ".idea",
-			isDir:            true,
-			shouldBeIgnored:  true,
-			ignoredPathsList: []string{".idea"},
-		},
-	}
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			fileIsIgnored := ignoreFileOrDirectory(test.name, test.isDir, test.ignoredPathsList)
-
-			assert.IsType(t, test.shouldBeIgnored, fileIsIgnored, "Got: %s", fileIsIgnored)
-
-			assert.Equal(t, test.shouldBeIgnored, fileIsIgnored, "fileIsIgnored was %b but should have been %b", fileIsIgnored, test.shouldBeIgnored)
-		})
-	}
-}
-
-func createTestPathsForIgnoredPathsTests(t *testing.T, testFolder string) testDirPaths {
-	t.Helper()
-	return testDirPaths{
-		cwSettingsPopulated:      path.Join(testFolder, "cwSettingsPopulated"),
-		cwSettingsEmpty:          path.Join(testFolder, "cwSettingsEmpty"),
-		cwSettingsNoIgnoredPaths: path.Join(testFolder, "cwSettingsNoIgnoredPathsField"),
-	}
-}
-
 func TestSyncFiles(t *testing.T) {
 	testDir := "sync_test_folder_delete_me"
 	os.Mkdir(testDir, 0777)
@@ -192,7 +114,7 @@ func TestSyncFiles(t *testing.T) {
 		ioutil.WriteFile(path.Join(mockProjectPath, "test"), []byte{}, 0644)
 		ioutil.WriteFile(path.Join(mockProjectPath, ".cw-settings"), cwSettingsFile, 0644)
 
-		got, err := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", 0, &mockConnection)
+		got, err := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: 0, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency})
 		if err != nil {
 			t.Errorf("syncFiles() failed with error: %s", err)
 		}
@@ -210,7 +132,7 @@ func TestSyncFiles(t *testing.T) {
 		ioutil.WriteFile(path.Join(mockProjectPath, "testfile"), []byte{}, 0644)
 		ioutil.WriteFile(path.Join(mockProjectPath, ".cw-settings"), cwSettingsFile, 0644)
 
-		got, err := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", 0, &mockConnection)
+		got, err := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: 0, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency})
 		if err != nil {
 			t.Errorf("syncFiles() failed with error: %s", err)
 		}
@@ -229,7 +151,7 @@ func TestSyncFiles(t *testing.T) {
 		ioutil.WriteFile(path.Join(newDirPath, "test"), []byte{}, 0644)
 		ioutil.WriteFile(path.Join(mockProjectPath, ".cw-settings"), cwSettingsFile, 0644)
 
-		got, err := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", 0, &mockConnection)
+		got, err := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: 0, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency})
 		if err != nil {
 			t.Errorf("syncFiles() failed with error: %s", err)
 		}
@@ -262,7 +184,7 @@ func TestSyncFiles(t *testing.T) {
 		time.Sleep(1 * time.Second)
 		ioutil.WriteFile(modTestPath, newContent, 0644)
 
-		got, _ := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", modifiedTime, &mockConnection)
+		got, _ := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: modifiedTime, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency})
 
 		expectedFileList := []string{".cw-settings", "nested-dir/testmod", "nested-dir/testnomod"}
 		expectedDirList := []string{"nested-dir"}
@@ -272,54 +194,214 @@ func TestSyncFiles(t *testing.T) {
 		assert.Equal(t, got.modifiedList, expectedModList)
 	})
 
+	t.Run("success case - touched file with unchanged content is not added to modified list", func(t *testing.T) {
+		mockProjectPath := path.Join(testDir, "touched-file")
+		os.Mkdir(mockProjectPath, 0777)
+
+		touchedPath := path.Join(mockProjectPath, "touched")
+		ioutil.WriteFile(touchedPath, []byte("same content"), 0644)
+		ioutil.WriteFile(path.Join(mockProjectPath, ".cw-settings"), cwSettingsFile, 0644)
+
+		first, _ := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: 0, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency})
+		first.manifest.exists = true
+
+		future := time.Now().Add(1 * time.Hour)
+		os.Chtimes(touchedPath, future, future)
+
+		got, _ := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: 0, manifest: first.manifest, concurrency: DefaultSyncConcurrency})
+		assert.Equal(t, []string{".cw-settings", "touched"}, got.fileList)
+		assert.Empty(t, got.modifiedList)
+	})
+
+	t.Run("success case - changed content with an old modification time is added to modified list", func(t *testing.T) {
+		mockProjectPath := path.Join(testDir, "restored-file")
+		os.Mkdir(mockProjectPath, 0777)
+
+		restoredPath := path.Join(mockProjectPath, "restored")
+		ioutil.WriteFile(restoredPath, []byte("original"), 0644)
+		ioutil.WriteFile(path.Join(mockProjectPath, ".cw-settings"), cwSettingsFile, 0644)
+
+		first, _ := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: 0, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency})
+		first.manifest.exists = true
+
+		past := time.Now().Add(-24 * time.Hour)
+		ioutil.WriteFile(restoredPath, []byte("restored from backup"), 0644)
+		os.Chtimes(restoredPath, past, past)
+
+		got, _ := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: time.Now().UnixNano() / 1000000, manifest: first.manifest, concurrency: DefaultSyncConcurrency})
+		assert.Equal(t, []string{"restored"}, got.modifiedList)
+	})
+
+	t.Run("success case - files that fail to upload are kept in the manifest as unsynced", func(t *testing.T) {
+		mockProjectPath := path.Join(testDir, "failed-upload")
+		os.Mkdir(mockProjectPath, 0777)
+		ioutil.WriteFile(path.Join(mockProjectPath, "test"), []byte("content"), 0644)
+
+		failingClient := &security.ClientMockAuthenticate{StatusCode: http.StatusInternalServerError, Body: body}
+		got, _ := syncFiles(failingClient, mockProjectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: 0, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency})
+		assert.Equal(t, []string{"test"}, got.modifiedList)
+		assert.True(t, got.manifest.Files["test"].Unsynced)
+
+		// the file is uploaded again next sync, even though its content hasn't changed
+		got.manifest.exists = true
+		next, _ := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: 0, manifest: got.manifest, concurrency: DefaultSyncConcurrency})
+		assert.Equal(t, []string{"test"}, next.modifiedList)
+		assert.False(t, next.manifest.Files["test"].Unsynced)
+
+		// and is reported as deleted if it is removed before then
+		deleted, _ := findDeletedPaths(got.manifest, nil, []string{}, []string{})
+		assert.Equal(t, []string{"test"}, deleted)
+	})
+
 	cleanupTestFolder(t, testDir)
 }
-func TestRetrieveIgnoredPathsList(t *testing.T) {
-	testFolder := "sync_test_folder_delete_me"
-	createTestDirPaths := createTestPathsForIgnoredPathsTests(t, testFolder)
-	setupIgnoredPathsTests(t, testFolder, createTestDirPaths)
 
+func TestUploadFiles(t *testing.T) {
+	testDir := "upload_test_folder_delete_me"
+	os.Mkdir(testDir, 0777)
+	defer cleanupTestFolder(t, testDir)
+	mockConnection := connections.Connection{ID: "local"}
+
+	var uploads []syncUpload
+	for _, name := range []string{"a", "b", "fail", "c", "d", "e", "f", "g"} {
+		filePath := path.Join(testDir, name)
+		ioutil.WriteFile(filePath, []byte(name), 0644)
+		uploads = append(uploads, syncUpload{path: filePath, relativePath: name})
+	}
+	uploads = append(uploads, syncUpload{path: path.Join(testDir, "unreadable"), relativePath: "unreadable", err: os.ErrPermission})
+
+	t.Run("success case - results are returned in upload order with failures collected", func(t *testing.T) {
+		mockClient := &clientMockUpload{}
+		results := uploadFiles(mockClient, "mockID", uploads, &mockConnection, "dummyURL", 3, nil)
+
+		assert.Len(t, results, len(uploads))
+		for i, upload := range uploads {
+			assert.Equal(t, upload.relativePath, results[i].FilePath)
+		}
+		assert.Equal(t, http.StatusOK, results[0].StatusCode)
+		assert.Equal(t, http.StatusInternalServerError, results[2].StatusCode)
+		assert.Equal(t, "Failed", results[8].Status)
+	})
+
+	t.Run("success case - no more than the concurrency limit are uploaded at once", func(t *testing.T) {
+		mockClient := &clientMockUpload{}
+		uploadFiles(mockClient, "mockID", uploads, &mockConnection, "dummyURL", 2, nil)
+		assert.Equal(t, 2, mockClient.maxInFlight)
+	})
+
+	t.Run("success case - a concurrency below 1 uploads one file at a time", func(t *testing.T) {
+		mockClient := &clientMockUpload{}
+		results := uploadFiles(mockClient, "mockID", uploads, &mockConnection, "dummyURL", 0, nil)
+		assert.Len(t, results, len(uploads))
+		assert.Equal(t, 1, mockClient.maxInFlight)
+	})
+}
+
+func TestFindDeletedPaths(t *testing.T) {
+	manifest := &syncManifest{
+		Files: map[string]syncManifestEntry{
+			"kept":            {},
+			"removed":         {},
+			"old-dir/file":    {},
+			"replaced-by-dir": {},
+		},
+		Directories: []string{"kept-dir", "old-dir"},
+		exists:      true,
+	}
 	tests := map[string]struct {
-		projectPath           string
-		shouldBeIgnored       []string
-		shouldBeIgnoredLength int
+		previous        *syncManifest
+		serverFiles     []string
+		wantFiles       []string
+		wantDirectories []string
 	}{
-		"success case: the returned ignoredPaths list should contain testfile and anothertestfile": {
-			projectPath:           createTestDirPaths.cwSettingsPopulated,
-			shouldBeIgnored:       []string{"testfile", "anothertestfile"},
-			shouldBeIgnoredLength: 2,
-		},
-		"success case: the returned ignoredPaths list should be empty": {
-			projectPath:           createTestDirPaths.cwSettingsEmpty,
-			shouldBeIgnored:       []string{},
-			shouldBeIgnoredLength: 0,
-		},
-		"success case: calling on a path that doesn't exist should return nil with a length 0": {
-			projectPath:           "pathdoesntexist",
-			shouldBeIgnored:       nil,
-			shouldBeIgnoredLength: 0,
+		"manifest lists files and directories that no longer exist": {
+			previous:        manifest,
+			serverFiles:     []string{"ignored-when-there-is-a-manifest"},
+			wantFiles:       []string{"old-dir/file", "removed"},
+			wantDirectories: []string{"old-dir"},
 		},
-		"success case: calling on a path that does exist but isn't valid JSON": {
-			projectPath:           "sync.go",
-			shouldBeIgnored:       nil,
-			shouldBeIgnoredLength: 0,
+		"without a manifest, files held by PFE are used": {
+			previous:        newSyncManifest(),
+			serverFiles:     []string{"kept", "removed"},
+			wantFiles:       []string{"removed"},
+			wantDirectories: []string{},
 		},
-		"success case: calling on a path that does exist and is valid JSON but doesn't contain ignoredPaths": {
-			projectPath:           createTestDirPaths.cwSettingsNoIgnoredPaths,
-			shouldBeIgnored:       nil,
-			shouldBeIgnoredLength: 0,
+		"nothing is deleted without a manifest or file list": {
+			previous:        newSyncManifest(),
+			serverFiles:     nil,
+			wantFiles:       []string{},
+			wantDirectories: []string{},
 		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			ignoredPathsList := retrieveIgnoredPathsList(test.projectPath)
+			files, directories := findDeletedPaths(test.previous, test.serverFiles, []string{"kept", "new"}, []string{"kept-dir", "replaced-by-dir"})
+			assert.Equal(t, test.wantFiles, files)
+			assert.Equal(t, test.wantDirectories, directories)
+		})
+	}
+}
 
-			assert.Equal(t, test.shouldBeIgnoredLength, len(ignoredPathsList), "Length of ignoredPathsList was %b but should have been %b", len(ignoredPathsList), test.shouldBeIgnoredLength)
+func TestPlanSync(t *testing.T) {
+	changes := &syncChanges{
+		fileList:      []string{"changed", "new", "unchanged", "missing-on-pfe"},
+		directoryList: []string{},
+		uploads: []syncUpload{
+			{relativePath: "changed"},
+			{relativePath: "new"},
+			{relativePath: "unreadable", err: errors.New("permission denied")},
+		},
+	}
+	t.Run("success case - manifest decides added and modified files", func(t *testing.T) {
+		previous := &syncManifest{
+			Files:  map[string]syncManifestEntry{"changed": {}, "unchanged": {}, "missing-on-pfe": {}, "deleted": {}},
+			exists: true,
+		}
+		got := planSync(previous, changes, []string{"changed", "unchanged", "deleted"})
+		assert.Equal(t, []string{"new", "missing-on-pfe"}, got.Added)
+		assert.Equal(t, []string{"changed"}, got.Modified)
+		assert.Equal(t, []string{"deleted"}, got.Deleted)
+	})
+	t.Run("success case - without a manifest files held by PFE are modified", func(t *testing.T) {
+		got := planSync(newSyncManifest(), changes, []string{"changed", "unchanged", "deleted"})
+		assert.Equal(t, []string{"new", "missing-on-pfe"}, got.Added)
+		assert.Equal(t, []string{"changed"}, got.Modified)
+		assert.Equal(t, []string{"deleted"}, got.Deleted)
+	})
+}
 
-			assert.Equal(t, test.shouldBeIgnored, ignoredPathsList, "ignoredPathsList was %b but should have been %b", ignoredPathsList, test.shouldBeIgnored)
+func TestProjectSyncConcurrency(t *testing.T) {
+	testFolder := "sync_concurrency_test_folder_delete_me"
+	tests := map[string]struct {
+		settings string
+		want     int
+	}{
+		"success case: the concurrency in .cw-settings is used": {
+			settings: `{"syncConcurrency": "8"}`,
+			want:     8,
+		},
+		"success case: the default is used if .cw-settings doesn't set the concurrency": {
+			settings: `{"ignoredPaths": []}`,
+			want:     DefaultSyncConcurrency,
+		},
+		"success case: the default is used if the concurrency isn't a number greater than 0": {
+			settings: `{"syncConcurrency": "0"}`,
+			want:     DefaultSyncConcurrency,
+		},
+		"success case: the default is used if there is no .cw-settings": {
+			want: DefaultSyncConcurrency,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.MkdirAll(testFolder, 0755)
+			defer cleanupTestFolder(t, testFolder)
+			if test.settings != "" {
+				ioutil.WriteFile(path.Join(testFolder, ".cw-settings"), []byte(test.settings), 0644)
+			}
+			assert.Equal(t, test.want, projectSyncConcurrency(testFolder))
 		})
 	}
-	cleanupTestFolder(t, testFolder)
 }
 
 func TestHandleMissingProjectDir(t *testing.T) {
@@ -338,39 +420,6 @@ func TestHandleMissingProjectDir(t *testing.T) {
 	})
 }
 
-func setupIgnoredPathsTests(t *testing.T, testFolder string, createPaths testDirPaths) {
-	t.Helper()
-	os.Mkdir(testFolder, 0777)
-
-	populatedIgnoredPaths := CWSettings{
-		IgnoredPaths: []string{
-			"testfile",
-			"anothertestfile",
-		},
-	}
-
-	emptyIgnoredPaths := CWSettings{
-		IgnoredPaths: []string{},
-	}
-
-	noIgnoredPaths := noIgnoredPaths{
-		Field1: []string{"Something", "Else"},
-		Field2: "Something Else",
-	}
-
-	os.Mkdir(createPaths.cwSettingsPopulated, 0777)
-	file, _ := json.Marshal(populatedIgnoredPaths)
-	ioutil.WriteFile(path.Join(createPaths.cwSettingsPopulated, ".cw-settings"), file, 0644)
-
-	os.Mkdir(createPaths.cwSettingsEmpty, 0777)
-	emptyFile, _ := json.Marshal(emptyIgnoredPaths)
-	ioutil.WriteFile(path.Join(createPaths.cwSettingsEmpty, ".cw-settings"), emptyFile, 0644)
-
-	os.Mkdir(createPaths.cwSettingsNoIgnoredPaths, 0777)
-	NoIgnoredPathsfile, _ := json.Marshal(noIgnoredPaths)
-	ioutil.WriteFile(path.Join(createPaths.cwSettingsNoIgnoredPaths, ".cw-settings"), NoIgnoredPathsfile, 0644)
-}
-
 func cleanupTestFolder(t *testing.T, testFolder string) {
 	t.Helper()
 	err := os.RemoveAll(testFolder)
@@ -0,0 +1,130 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	logr "github.com/sirupsen/logrus"
+)
+
+// The values of the symlinks option in .cw-settings, controlling how sync handles symlinks in a project
+const (
+	symlinksFollow = "follow" // sync the file or directory the link points to, as if it were in the project
+	symlinksLink   = "link"   // sync the link itself
+	symlinksSkip   = "skip"   // don't sync links
+)
+
+// walkFunc is called for each file and directory found by walkPath. path is where the file appears
+// in the walked tree, realPath is where its content can be read from, which differs for followed links
+type walkFunc func(path string, realPath string, info os.FileInfo, err error) error
+
+// symlinkPolicy : Get how a project's .cw-settings says symlinks should be synced, following them by default
+func symlinkPolicy(projectPath string) string {
+	cwSettings, _ := readCWSettings(projectPath)
+	switch cwSettings.Symlinks {
+	case symlinksLink, symlinksSkip:
+		return cwSettings.Symlinks
+	}
+	return symlinksFollow
+}
+
+// walkPath : Walk the tree at target in lexical order like filepath.Walk, reporting paths as if
+// the tree were at root. Symlinks below target are handled according to the symlinks policy, and
+// followed links to directories are not walked if they lead back to a directory containing them
+func walkPath(root string, target string, symlinks string, fn walkFunc) error {
+	info, err := os.Stat(target)
+	if err != nil {
+		return fn(root, target, nil, err)
+	}
+	realTarget, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return fn(root, target, info, err)
+	}
+	err = walkEntry(root, realTarget, info, symlinks, nil, fn)
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+// walkEntry : Walk a file or directory. ancestors holds the real paths of the directories containing it
+func walkEntry(path string, realPath string, info os.FileInfo, symlinks string, ancestors []string, fn walkFunc) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		switch symlinks {
+		case symlinksSkip:
+			return nil
+		case symlinksLink:
+			return fn(path, realPath, info, nil)
+		}
+
+		linkTarget, err := filepath.EvalSymlinks(realPath)
+		if err != nil {
+			return fn(path, realPath, info, err)
+		}
+		targetInfo, err := os.Stat(linkTarget)
+		if err != nil {
+			return fn(path, realPath, info, err)
+		}
+		if targetInfo.IsDir() && stringInSlice(linkTarget, ancestors) {
+			logr.Tracef("Not following symlink %v as it leads back to %v\n", path, linkTarget)
+			return nil
+		}
+		realPath, info = linkTarget, targetInfo
+	}
+
+	err := fn(path, realPath, info, nil)
+	if err != nil || !info.IsDir() {
+		return skipDirOnly(info, err)
+	}
+
+	dir, err := os.Open(realPath)
+	if err != nil {
+		return skipDirOnly(info, fn(path, realPath, info, err))
+	}
+	names, err := dir.Readdirnames(-1)
+	dir.Close()
+	if err != nil {
+		return skipDirOnly(info, fn(path, realPath, info, err))
+	}
+	sort.Strings(names)
+
+	ancestors = append(ancestors, realPath)
+	for _, name := range names {
+		childPath := filepath.Join(path, name)
+		childRealPath := filepath.Join(realPath, name)
+		childInfo, err := os.Lstat(childRealPath)
+		if err != nil {
+			err = fn(childPath, childRealPath, nil, err)
+		} else {
+			err = walkEntry(childPath, childRealPath, childInfo, symlinks, ancestors, fn)
+		}
+		if err == filepath.SkipDir {
+			// a file asked to skip the rest of its directory
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipDirOnly : Stop filepath.SkipDir returned for a directory from skipping the rest of its parent
+func skipDirOnly(info os.FileInfo, err error) error {
+	if err == filepath.SkipDir && info.IsDir() {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkPath(t *testing.T) {
+	testDir, _ := filepath.Abs("sync_walk_test_folder_delete_me")
+	defer cleanupTestFolder(t, testDir)
+
+	// project/
+	//   file
+	//   dir/loop -> project (a cycle)
+	//   shared -> ../shared
+	//   file-link -> file
+	projectPath := filepath.Join(testDir, "project")
+	sharedPath := filepath.Join(testDir, "shared")
+	os.MkdirAll(filepath.Join(projectPath, "dir"), 0777)
+	os.MkdirAll(sharedPath, 0777)
+	ioutil.WriteFile(filepath.Join(projectPath, "file"), []byte("content"), 0644)
+	ioutil.WriteFile(filepath.Join(sharedPath, "lib.js"), []byte("shared"), 0644)
+	os.Symlink(projectPath, filepath.Join(projectPath, "dir", "loop"))
+	os.Symlink(filepath.Join("..", "shared"), filepath.Join(projectPath, "shared"))
+	os.Symlink("file", filepath.Join(projectPath, "file-link"))
+
+	walk := func(symlinks string) []string {
+		var paths []string
+		walkPath(projectPath, projectPath, symlinks, func(path string, realPath string, info os.FileInfo, err error) error {
+			if path != projectPath {
+				paths = append(paths, filepath.ToSlash(path[len(projectPath)+1:]))
+			}
+			return err
+		})
+		return paths
+	}
+
+	t.Run("success case - follow walks linked directories and stops at cycles", func(t *testing.T) {
+		assert.Equal(t, []string{"dir", "file", "file-link", "shared", "shared/lib.js"}, walk(symlinksFollow))
+	})
+
+	t.Run("success case - link reports links without following them", func(t *testing.T) {
+		assert.Equal(t, []string{"dir", "dir/loop", "file", "file-link", "shared"}, walk(symlinksLink))
+	})
+
+	t.Run("success case - skip leaves links out", func(t *testing.T) {
+		assert.Equal(t, []string{"dir", "file"}, walk(symlinksSkip))
+	})
+
+	t.Run("success case - links are synced as links when set in .cw-settings", func(t *testing.T) {
+		ioutil.WriteFile(filepath.Join(projectPath, ".cw-settings"), []byte(`{"symlinks": "link"}`), 0644)
+		defer os.Remove(filepath.Join(projectPath, ".cw-settings"))
+		defer ioutil.WriteFile(filepath.Join(projectPath, "file"), []byte("content"), 0644)
+
+		mockConnection := connections.Connection{ID: "local"}
+		options := syncOptions{lastSync: 0, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency, pfeSupports: func(string) bool { return true }}
+		got, _ := syncFiles(&clientMockUpload{}, projectPath, "mockID", "dummyURL", &mockConnection, options)
+		assert.Equal(t, []string{".cw-settings", "dir/loop", "file", "file-link", "shared"}, got.fileList)
+
+		// the link is recorded by where it points, so changing the file it points to doesn't change it
+		linkEntry := got.manifest.Files["file-link"]
+		wantEntry, _ := hashSymlink(filepath.Join(projectPath, "file-link"))
+		assert.Equal(t, wantEntry, linkEntry)
+		ioutil.WriteFile(filepath.Join(projectPath, "file"), []byte("changed"), 0644)
+		options.manifest = got.manifest
+		got, _ = syncFiles(&clientMockUpload{}, projectPath, "mockID", "dummyURL", &mockConnection, options)
+		assert.Equal(t, []string{"file"}, got.modifiedList)
+	})
+
+	t.Run("success case - links are followed when PFE can't upload them as links", func(t *testing.T) {
+		ioutil.WriteFile(filepath.Join(projectPath, ".cw-settings"), []byte(`{"symlinks": "link"}`), 0644)
+		defer os.Remove(filepath.Join(projectPath, ".cw-settings"))
+
+		mockConnection := connections.Connection{ID: "local"}
+		options := syncOptions{lastSync: 0, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency, pfeSupports: func(string) bool { return false }}
+		got, _ := syncFiles(&clientMockUpload{}, projectPath, "mockID", "dummyURL", &mockConnection, options)
+		assert.Equal(t, []string{".cw-settings", "file", "file-link", "shared/lib.js"}, got.fileList)
+	})
+}
+
+func TestSyncFilesDirectoryRefPath(t *testing.T) {
+	testDir, _ := filepath.Abs("sync_ref_dir_test_folder_delete_me")
+	defer cleanupTestFolder(t, testDir)
+
+	projectPath := filepath.Join(testDir, "project")
+	sharedPath := filepath.Join(testDir, "shared")
+	os.MkdirAll(projectPath, 0777)
+	os.MkdirAll(filepath.Join(sharedPath, "nested"), 0777)
+	ioutil.WriteFile(filepath.Join(sharedPath, "a.js"), []byte("a"), 0644)
+	ioutil.WriteFile(filepath.Join(sharedPath, "nested", "b.js"), []byte("b"), 0644)
+	ioutil.WriteFile(filepath.Join(projectPath, ".cw-refpaths.json"), []byte(`{"refPaths": [{"from": "../shared", "to": "lib/shared"}]}`), 0644)
+
+	mockConnection := connections.Connection{ID: "local"}
+	got, err := syncFiles(&clientMockUpload{}, projectPath, "mockID", "dummyURL", &mockConnection, syncOptions{lastSync: 0, manifest: newSyncManifest(), concurrency: DefaultSyncConcurrency})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{".cw-refpaths.json", "lib/shared/a.js", "lib/shared/nested/b.js"}, got.fileList)
+	assert.Equal(t, []string{"lib/shared", "lib/shared/nested"}, got.directoryList)
+	assert.Equal(t, got.fileList, got.modifiedList)
+}
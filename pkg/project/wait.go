@@ -0,0 +1,92 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// The statuses PFE reports for a project's build and application
+const (
+	buildStatusInProgress = "inProgress"
+	buildStatusQueued     = "queued"
+	buildStatusFailed     = "failed"
+	appStatusStarted      = "started"
+	appStatusStarting     = "starting"
+	appStatusStopped      = "stopped"
+	appStatusFailed       = "failed"
+)
+
+// waitPollInterval : How often a project is polled while waiting for it to start
+var waitPollInterval = time.Second
+
+// WaitForProject : Poll a project until its application has started, calling onChange each time its build or
+// application status changes. Fails if the build fails, if the application stops or fails after starting, or if
+// the project hasn't started within the timeout. After a restart the application may still be reported as
+// started from before, so before is the project as it was before the restart, or nil if it wasn't restarted,
+// and the application only counts as started once the restart has been seen
+func WaitForProject(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, timeout time.Duration, before *Project, onChange func(Project)) (*Project, *ProjectError) {
+	deadline := time.Now().Add(timeout)
+	var last *Project
+	restartSeen := before == nil
+	startingSeen := false
+	for {
+		project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+		if projErr != nil {
+			return nil, projErr
+		}
+		if last == nil || project.AppStatus != last.AppStatus || project.BuildStatus != last.BuildStatus {
+			if onChange != nil {
+				onChange(*project)
+			}
+		}
+		last = project
+
+		building := project.BuildStatus == buildStatusInProgress || project.BuildStatus == buildStatusQueued
+		if project.BuildStatus == buildStatusFailed {
+			err := errors.New(textBuildFailed)
+			return project, &ProjectError{errOpWait, err, textBuildFailed}
+		}
+		if !restartSeen {
+			restartSeen = project.AppStatus != appStatusStarted || building || isNewRun(before, project)
+		}
+		if project.AppStatus == appStatusStarting {
+			startingSeen = true
+		}
+		if restartSeen && !building {
+			if project.AppStatus == appStatusStarted {
+				return project, nil
+			}
+			// stopped is also passed through while restarting, so it is only a failure once starting has been seen
+			if project.AppStatus == appStatusFailed || (project.AppStatus == appStatusStopped && startingSeen) {
+				err := errors.New(textAppStopped)
+				return project, &ProjectError{errOpWait, err, textAppStopped}
+			}
+		}
+
+		if !time.Now().Add(waitPollInterval).Before(deadline) {
+			err := errors.New(textWaitTimeout)
+			return project, &ProjectError{errOpWait, err, textWaitTimeout}
+		}
+		time.Sleep(waitPollInterval)
+	}
+}
+
+// isNewRun : Check whether PFE reports a project as running in a different container or pod than before,
+// which shows it has been restarted even if it was never seen leaving the started state
+func isNewRun(before *Project, project *Project) bool {
+	return project.ContainerID != before.ContainerID || project.PodName != before.PodName
+}
@@ -0,0 +1,112 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clientMockStatuses responds with each of a list of projects in turn, repeating the last
+type clientMockStatuses struct {
+	responses []string
+	calls     int
+}
+
+func (c *clientMockStatuses) Do(req *http.Request) (*http.Response, error) {
+	body := c.responses[len(c.responses)-1]
+	if c.calls < len(c.responses) {
+		body = c.responses[c.calls]
+	}
+	c.calls++
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+}
+
+func TestWaitForProject(t *testing.T) {
+	originalInterval := waitPollInterval
+	waitPollInterval = time.Millisecond
+	defer func() { waitPollInterval = originalInterval }()
+
+	started := `{"name": "p", "buildStatus": "success", "appStatus": "started"}`
+	stopping := `{"name": "p", "buildStatus": "success", "appStatus": "stopping"}`
+	building := `{"name": "p", "buildStatus": "inProgress", "appStatus": "stopped"}`
+	starting := `{"name": "p", "buildStatus": "success", "appStatus": "starting"}`
+	failed := `{"name": "p", "buildStatus": "failed", "appStatus": "stopped"}`
+	stopped := `{"name": "p", "buildStatus": "success", "appStatus": "stopped"}`
+	startedInNewContainer := `{"name": "p", "buildStatus": "success", "appStatus": "started", "containerId": "new"}`
+	beforeRestart := &Project{Name: "p", BuildStatus: "success", AppStatus: "started", ContainerID: "old"}
+
+	tests := map[string]struct {
+		responses   []string
+		before      *Project
+		timeout     time.Duration
+		wantErrOp   string
+		wantChanges []string
+	}{
+		"success case - a bound project builds and starts": {
+			responses:   []string{building, building, starting, started},
+			timeout:     time.Minute,
+			wantChanges: []string{"inProgress/stopped", "success/starting", "success/started"},
+		},
+		"success case - a restarted project must stop before it counts as started": {
+			responses:   []string{`{"name": "p", "buildStatus": "success", "appStatus": "started", "containerId": "old"}`, stopping, stopped, starting, started},
+			before:      beforeRestart,
+			timeout:     time.Minute,
+			wantChanges: []string{"success/started", "success/stopping", "success/stopped", "success/starting", "success/started"},
+		},
+		"success case - a restarted project in a new container counts as started without being seen stopping": {
+			responses:   []string{startedInNewContainer},
+			before:      beforeRestart,
+			timeout:     time.Minute,
+			wantChanges: []string{"success/started"},
+		},
+		"fail case - an application that stops after starting stops the wait": {
+			responses:   []string{starting, stopped},
+			timeout:     time.Minute,
+			wantErrOp:   errOpWait,
+			wantChanges: []string{"success/starting", "success/stopped"},
+		},
+		"fail case - a build failure stops the wait": {
+			responses:   []string{building, failed},
+			timeout:     time.Minute,
+			wantErrOp:   errOpWait,
+			wantChanges: []string{"inProgress/stopped", "failed/stopped"},
+		},
+		"fail case - a project that doesn't start times out": {
+			responses:   []string{starting},
+			timeout:     20 * time.Millisecond,
+			wantErrOp:   errOpWait,
+			wantChanges: []string{"success/starting"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var changes []string
+			onChange := func(project Project) {
+				changes = append(changes, project.BuildStatus+"/"+project.AppStatus)
+			}
+			mockClient := &clientMockStatuses{responses: test.responses}
+			_, err := WaitForProject(mockClient, &mockConnection, "http://mockURL", "mockID", test.timeout, test.before, onChange)
+			if test.wantErrOp != "" {
+				assert.Equal(t, test.wantErrOp, err.Op)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.wantChanges, changes)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	logr "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// syncDebounce is how long to wait for a burst of changes to end before syncing
+const syncDebounce = 500 * time.Millisecond
+
+// projectWatcher watches the directories of a project that are synced to PFE
+type projectWatcher struct {
+	*fsnotify.Watcher
+	projectPath string
+	refPaths    []refPath       // the files referenced in .cw-refpaths.json
+	ignored     *ignoreMatcher  // the paths ignored by the project's settings
+	syncIgnored *ignoreMatcher  // the paths that are not synced, which also excludes the targets of refPaths
+	directories map[string]bool // the directories found, as a path can't be checked once it is removed
+}
+
+// syncRuleFiles : The files in the root of a project that decide which paths are synced
+var syncRuleFiles = []string{settingsFileName, ".cw-refpaths.json", ignoreSourceGitignore, ignoreSourceDocker}
+
+// WatchProject : Sync a project, then sync it again every time its files change.
+// onSync is called with the result of each sync. Only returns if the project can't be watched
+func WatchProject(c *cli.Context, onSync func(*SyncResponse, *ProjectError)) *ProjectError {
+	projectPath := strings.TrimSpace(c.String("path"))
+	projectID := strings.TrimSpace(c.String("id"))
+	synctime := int64(c.Int("time"))
+
+	connection, conURL, projErr := getProjectConnection(projectID)
+	if projErr != nil {
+		return projErr
+	}
+
+	projErr = checkProjectDirExists(projectPath, projectID, connection, conURL)
+	if projErr != nil {
+		return projErr
+	}
+
+	discardSyncManifestForFullSync(c, projectID)
+	concurrency := getSyncConcurrency(c, projectPath)
+
+	watcher, err := newProjectWatcher(projectPath)
+	if err != nil {
+		return &ProjectError{errOpWatch, err, err.Error()}
+	}
+	defer watcher.Close()
+
+	sync := func() {
+		onSync(syncProject(projectPath, projectID, connection, conURL, synctime, concurrency))
+	}
+
+	// sync straight away to pick up anything that changed before watching started
+	sync()
+	watcher.watch(syncDebounce, nil, sync)
+	return nil
+}
+
+// newProjectWatcher : Watch every directory in a project that is not ignored,
+// along with the directories of any referenced files
+func newProjectWatcher(projectPath string) (*projectWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	projectWatcher := &projectWatcher{Watcher: watcher, projectPath: filepath.Clean(projectPath), directories: map[string]bool{}}
+	projectWatcher.loadSyncRules()
+
+	err = projectWatcher.addDirectories(projectWatcher.projectPath)
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	projectWatcher.addRefPaths()
+	return projectWatcher, nil
+}
+
+// watch : Call sync once changes have stopped for the debounce period, until stop is closed
+func (watcher *projectWatcher) watch(debounce time.Duration, stop <-chan struct{}, sync func()) {
+	var debounceTimer <-chan time.Time
+	for {
+		select {
+		case <-stop:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if watcher.isSyncableChange(event) {
+				debounceTimer = time.After(debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logr.Tracef("Error watching project: %v\n", err)
+		case <-debounceTimer:
+			debounceTimer = nil
+			sync()
+		}
+	}
+}
+
+// loadSyncRules : Read which paths are ignored and referenced. This is only done again when one
+// of the syncRuleFiles changes, rather than for every event
+func (watcher *projectWatcher) loadSyncRules() {
+	watcher.refPaths = retrieveRefPathsList(watcher.projectPath)
+	watcher.ignored = loadIgnoreMatcher(watcher.projectPath)
+	watcher.syncIgnored = watcher.ignored.withRefPaths(watcher.refPaths)
+}
+
+// isSyncableChange : Check whether an event is for a file that is synced to PFE,
+// watching any new directories that it creates
+func (watcher *projectWatcher) isSyncableChange(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+
+	for _, refPath := range watcher.refPaths {
+		from := watcher.resolveRefPath(refPath.From)
+		if from == event.Name || strings.HasPrefix(event.Name, from+string(filepath.Separator)) {
+			if event.Op&fsnotify.Create == fsnotify.Create {
+				watcher.addRefPaths()
+			}
+			return true
+		}
+	}
+
+	// other files in the directories of referenced files are not synced
+	if !strings.HasPrefix(event.Name, watcher.projectPath+string(filepath.Separator)) {
+		return false
+	}
+	relativePath := filepath.ToSlash(event.Name[(len(watcher.projectPath) + 1):])
+
+	for _, ruleFile := range syncRuleFiles {
+		if relativePath == ruleFile {
+			// directories that were ignored may now be synced, and new references need watching
+			watcher.loadSyncRules()
+			watcher.addDirectories(watcher.projectPath)
+			watcher.addRefPaths()
+		}
+	}
+
+	// a removed or renamed path no longer exists, so whether it was a directory is remembered
+	isDir := watcher.directories[event.Name]
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		watcher.forgetDirectories(event.Name)
+	} else if info, err := os.Stat(event.Name); err == nil {
+		isDir = info.IsDir()
+		if isDir {
+			watcher.directories[event.Name] = true
+		}
+	}
+	if watcher.syncIgnored.match(relativePath, isDir) != nil {
+		return false
+	}
+
+	if isDir && event.Op&fsnotify.Create == fsnotify.Create {
+		watcher.addDirectories(event.Name)
+	}
+	return true
+}
+
+// addDirectories : Watch a directory and all of its subdirectories that are not ignored,
+// including directories reached through symlinks if the project follows them
+func (watcher *projectWatcher) addDirectories(root string) error {
+	return walkPath(root, root, symlinkPolicy(watcher.projectPath), func(path string, realPath string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		watcher.directories[path] = true
+		if strings.HasPrefix(path, watcher.projectPath+string(filepath.Separator)) {
+			relativePath := filepath.ToSlash(path[(len(watcher.projectPath) + 1):])
+			if watcher.ignored.match(relativePath, true) != nil {
+				return filepath.SkipDir
+			}
+		}
+		return watcher.Add(path)
+	})
+}
+
+// forgetDirectories : Stop recording a removed directory, and the directories in it
+func (watcher *projectWatcher) forgetDirectories(root string) {
+	for path := range watcher.directories {
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) {
+			delete(watcher.directories, path)
+		}
+	}
+}
+
+// addRefPaths : Watch the directories referenced in .cw-refpaths.json, and the directories
+// containing files referenced there
+func (watcher *projectWatcher) addRefPaths() {
+	for _, refPath := range retrieveRefPathsList(watcher.projectPath) {
+		from := watcher.resolveRefPath(refPath.From)
+		info, err := os.Stat(from)
+		if err == nil && info.IsDir() {
+			watcher.addDirectories(from)
+		} else if watcher.Add(filepath.Dir(from)) == nil {
+			watcher.directories[filepath.Dir(from)] = true
+		}
+	}
+}
+
+// resolveRefPath : Get the absolute path of a referenced file
+func (watcher *projectWatcher) resolveRefPath(from string) string {
+	if !filepath.IsAbs(from) {
+		from = filepath.Join(watcher.projectPath, from)
+	}
+	return filepath.Clean(from)
+}
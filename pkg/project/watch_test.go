@@ -0,0 +1,98 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchProject(t *testing.T) {
+	testDir, _ := filepath.Abs("watch_test_folder_delete_me")
+	os.Mkdir(testDir, 0777)
+	defer cleanupTestFolder(t, testDir)
+
+	cwSettings, _ := json.Marshal(CWSettings{IgnoredPaths: []string{"ignored", "*.swp", "build/"}})
+	ioutil.WriteFile(filepath.Join(testDir, ".cw-settings"), cwSettings, 0644)
+	os.Mkdir(filepath.Join(testDir, "ignored"), 0777)
+	os.Mkdir(filepath.Join(testDir, "build"), 0777)
+
+	watcher, err := newProjectWatcher(testDir)
+	if err != nil {
+		t.Fatalf("newProjectWatcher() failed with error: %s", err)
+	}
+	defer watcher.Close()
+
+	syncs := make(chan struct{}, 10)
+	stop := make(chan struct{})
+	defer close(stop)
+	go watcher.watch(50*time.Millisecond, stop, func() { syncs <- struct{}{} })
+
+	// waitForSyncs returns the number of syncs that happen within the wait
+	waitForSyncs := func() int {
+		count := 0
+		timeout := time.After(500 * time.Millisecond)
+		for {
+			select {
+			case <-syncs:
+				count++
+			case <-timeout:
+				return count
+			}
+		}
+	}
+
+	t.Run("success case - a burst of changes results in one sync", func(t *testing.T) {
+		for _, name := range []string{"a", "b", "c"} {
+			ioutil.WriteFile(filepath.Join(testDir, name), []byte(name), 0644)
+		}
+		assert.Equal(t, 1, waitForSyncs())
+	})
+
+	t.Run("success case - changes in new directories are synced", func(t *testing.T) {
+		newDir := filepath.Join(testDir, "new-dir")
+		os.Mkdir(newDir, 0777)
+		assert.Equal(t, 1, waitForSyncs())
+
+		ioutil.WriteFile(filepath.Join(newDir, "file"), []byte("content"), 0644)
+		assert.Equal(t, 1, waitForSyncs())
+	})
+
+	t.Run("success case - changes to ignored paths are not synced", func(t *testing.T) {
+		ioutil.WriteFile(filepath.Join(testDir, "file.swp"), []byte("swap"), 0644)
+		ioutil.WriteFile(filepath.Join(testDir, "ignored", "file"), []byte("ignored"), 0644)
+		assert.Equal(t, 0, waitForSyncs())
+	})
+
+	t.Run("success case - removing an ignored directory is not synced", func(t *testing.T) {
+		os.Remove(filepath.Join(testDir, "build"))
+		assert.Equal(t, 0, waitForSyncs())
+	})
+
+	t.Run("success case - changes to .cw-settings change which paths are ignored", func(t *testing.T) {
+		cwSettings, _ := json.Marshal(CWSettings{IgnoredPaths: []string{"*.log"}})
+		ioutil.WriteFile(filepath.Join(testDir, ".cw-settings"), cwSettings, 0644)
+		assert.Equal(t, 1, waitForSyncs())
+
+		ioutil.WriteFile(filepath.Join(testDir, "app.log"), []byte("log"), 0644)
+		assert.Equal(t, 0, waitForSyncs())
+
+		ioutil.WriteFile(filepath.Join(testDir, "ignored", "file"), []byte("synced"), 0644)
+		assert.Equal(t, 1, waitForSyncs())
+	})
+}
@@ -12,8 +12,12 @@
 package sechttp
 
 import (
+	"bytes"
 	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"strings"
 
@@ -40,51 +44,64 @@ func DispatchHTTPRequest(httpClient utils.HTTPClient, originalRequest *http.Requ
 		return nil, err
 	}
 
-	// Should be a 401 (bearer only) but is infact a 302 (Redirect to a login page)
-	keycloakLoginErrorStatus := http.StatusFound
-	logr.Tracef("Getting Connection: %v\n", connection.ID)
+	// The request is sent again if its access token is rejected, so small bodies are made replayable.
+	// Streamed bodies are sent only once
+	if err := makeReplayable(originalRequest); err != nil {
+		return nil, &HTTPSecError{errOpBodyRead, err, err.Error()}
+	}
+	replayable := isReplayable(originalRequest)
 
-	// Get the current access token from the keychain
-	logr.Traceln("Retrieving an access token from the keychain")
-	conID := strings.TrimSpace(strings.ToLower(connection.ID))
-	accessToken, _ := security.GetSecretFromKeyring(conID, "access_token")
+	if connection.ServiceAccount != nil {
+		return dispatchAsServiceAccount(httpClient, originalRequest, connection)
+	}
 
-	if accessToken == "" {
-		logr.Traceln("Access token not found in keychain")
+	// Get the current access token from the keychain, refreshing it first if it has expired
+	logr.Tracef("Getting an access token for connection: %v\n", connection.ID)
+	sent := false
+	accessToken, secError := security.GetAccessToken(httpClient, connection)
+	if secError != nil {
+		logr.Tracef("Unable to get an access token %v : %v\n", secError.Op, secError.Desc)
 	} else {
-		logr.Traceln("Access token found in keychain, trying request")
 		response, err := sendRequest(httpClient, originalRequest, accessToken)
-		if err == nil && response.StatusCode != keycloakLoginErrorStatus {
+		sent = true
+		if err != nil {
+			logr.Tracef("Request failed: %v", err.Desc)
+			return nil, err
+		}
+		if !isAuthFailure(response) {
 			logr.Tracef("Received HTTP Status code: %v", response.StatusCode)
 			return response, nil
 		}
-		logr.Tracef(" Request failed: %v", err.Desc)
-	}
 
-	// Try refreshing the access token with our cached refresh token
-	logr.Tracef("Retrieving a refresh token from the keychain")
-	refreshToken, _ := security.GetSecretFromKeyring(conID, "refresh_token")
-	if refreshToken == "" {
-		logr.Tracef("Refresh token not found in keychain")
-	} else {
-		logr.Tracef("Try refreshing the access token with our cached refresh token")
-		tokens, secError := security.SecRefreshAccessToken(httpClient, connection, refreshToken)
+		// The token was rejected before it expired, for example because the session was ended
+		logr.Tracef("Access token rejected, refreshing it and trying the original request again")
+		if response.Body != nil {
+			response.Body.Close()
+		}
+		accessToken, secError = security.RefreshAccessToken(httpClient, connection, accessToken)
 		if secError != nil {
 			logr.Tracef("Failed refreshing access token %v : %v\n", secError.Op, secError.Desc)
-		}
-		if tokens != nil {
-			logr.Tracef("New access token received")
-			accessToken = tokens.AccessToken
-			logr.Tracef("Trying the original request again with the new access_token")
+		} else if !replayable {
+			err := errors.New(errAuthRetry)
+			return nil, &HTTPSecError{errOpAuthRetry, err, err.Error()}
+		} else {
 			response, err := sendRequest(httpClient, originalRequest, accessToken)
-			if err == nil && response.StatusCode != keycloakLoginErrorStatus {
+			if err == nil && !isAuthFailure(response) {
 				logr.Tracef("Received HTTP Status code: %v", response.StatusCode)
 				return response, nil
 			}
 		}
 	}
 
+	// Connections logged in to in the browser have no username or password to re-authenticate with
+	if connection.Username == "" {
+		logr.Tracef("No username to re-authenticate with, the user must log in again")
+		err := fmt.Errorf(errLoginRequired, connection.ID)
+		return nil, &HTTPSecError{errOpLoginRequired, err, err.Error()}
+	}
+
 	logr.Tracef("Re-authenticate using cached credentials from the keychain")
+	conID := strings.TrimSpace(strings.ToLower(connection.ID))
 	password, keyErr := security.GetSecretFromKeyring(conID, strings.ToLower(connection.Username))
 	if keyErr != nil {
 		logr.Tracef("ERROR:  %v\n", keyErr.Error())
@@ -107,6 +124,12 @@ func DispatchHTTPRequest(httpClient utils.HTTPClient, originalRequest *http.Requ
 		return nil, &HTTPSecError{errOpAuthFailed, secError.Err, secError.Desc}
 	}
 
+	// A streamed body that was already sent can't be sent again with the new access token
+	if sent && !replayable {
+		err := errors.New(errAuthRetry)
+		return nil, &HTTPSecError{errOpAuthRetry, err, err.Error()}
+	}
+
 	// Try to access the resource again with the new access token
 	logr.Tracef("Try to access the resource again with the new access token")
 	response, err := sendRequest(httpClient, originalRequest, tokens.AccessToken)
@@ -122,9 +145,79 @@ func DispatchHTTPRequest(httpClient utils.HTTPClient, originalRequest *http.Requ
 	return nil, &HTTPSecError{errOpFailed, failedError, failedError.Error()}
 }
 
+// dispatchAsServiceAccount : Perform an HTTP request against PFE as a connection's service account, getting a new
+// access token and trying again if its token is rejected
+func dispatchAsServiceAccount(httpClient utils.HTTPClient, originalRequest *http.Request, connection *connections.Connection) (*http.Response, *HTTPSecError) {
+	accessToken := ""
+	for attempt := 0; attempt < 2; attempt++ {
+		var secError *security.SecError
+		accessToken, secError = security.GetServiceAccountToken(httpClient, connection, accessToken)
+		if secError != nil {
+			logr.Tracef("Service account can not authenticate %v : %v\n", secError.Op, secError.Desc)
+			return nil, &HTTPSecError{errOpAuthFailed, secError.Err, secError.Desc}
+		}
+		if attempt > 0 && !isReplayable(originalRequest) {
+			err := errors.New(errAuthRetry)
+			return nil, &HTTPSecError{errOpAuthRetry, err, err.Error()}
+		}
+		response, err := sendRequest(httpClient, originalRequest, accessToken)
+		if err != nil {
+			return nil, err
+		}
+		if !isAuthFailure(response) {
+			logr.Tracef("Received HTTP Status code: %v", response.StatusCode)
+			return response, nil
+		}
+		logr.Tracef("Service account access token rejected")
+		if response.Body != nil {
+			response.Body.Close()
+		}
+	}
+	err := fmt.Errorf("Service account %s was not allowed to access Codewind", connection.ServiceAccount.ClientID)
+	return nil, &HTTPSecError{errOpAuthFailed, err, err.Error()}
+}
+
+// isAuthFailure : Whether PFE rejected a request's access token. It should be a 401 (bearer only), but is
+// in fact a 302 (redirect to a login page)
+func isAuthFailure(response *http.Response) bool {
+	return response.StatusCode == http.StatusFound || response.StatusCode == http.StatusUnauthorized
+}
+
+// makeReplayable : Buffer the body of a request that can't already be re-read, so it can be sent again, if it is
+// small enough to hold in memory. Bodies of unknown length, such as streamed archives, are left alone
+func makeReplayable(req *http.Request) error {
+	if isReplayable(req) || req.ContentLength <= 0 || req.ContentLength > maxReplayableBody {
+		return nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+// isReplayable : Whether a request can be sent more than once
+func isReplayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
 // Send the HTTP request along with supplied headers and access_token
 func sendRequest(httpClient utils.HTTPClient, originalRequest *http.Request, accessToken string) (*http.Response, *HTTPSecError) {
 
+	// Replace the body a previous attempt read
+	if originalRequest.GetBody != nil {
+		body, err := originalRequest.GetBody()
+		if err != nil {
+			return nil, &HTTPSecError{errOpNoConnection, err, err.Error()}
+		}
+		originalRequest.Body = body
+	}
+
 	// Add auth headers
 	if accessToken != "" {
 		originalRequest.Header.Set("Authorization", "bearer "+accessToken)
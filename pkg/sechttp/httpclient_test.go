@@ -142,6 +142,22 @@ func testDispatchHTTPRequest(t *testing.T) {
 		expectedErr := &HTTPSecError{errOpNoPassword, errors.New(errMissingPassword), errMissingPassword}
 		assert.Equal(t, expectedErr, gotErr)
 	})
+	t.Run("returns the correct error when PFE is not local, "+
+		"we cannot get an access token from the keyring, "+
+		"we cannot get a refresh token from the keyring, "+
+		"and the connection was logged in to without a username", func(t *testing.T) {
+		security.DeleteSecretFromKeyring(connectionID, "access_token")
+		security.DeleteSecretFromKeyring(connectionID, "refresh_token")
+
+		mockClientReturning200 := &MockResponse{StatusCode: http.StatusOK, Body: nil}
+		mockConnection := connections.Connection{ID: connectionID}
+		mockRequest := httptest.NewRequest("GET", "/", nil)
+
+		gotResp, gotErr := DispatchHTTPRequest(mockClientReturning200, mockRequest, &mockConnection)
+		assert.Nil(t, gotResp)
+		assert.Equal(t, errOpLoginRequired, gotErr.Op)
+		assert.Equal(t, "Session for connection testcon has expired, log in again with: cwctl sectoken login --conid testcon --browser (or --device)", gotErr.Desc)
+	})
 	t.Run("returns the response from PFE when PFE is not local, "+
 		"we cannot get an access token from the keyring, "+
 		"we cannot get a refresh token from the keyring, "+
@@ -174,3 +190,94 @@ func testDispatchHTTPRequest(t *testing.T) {
 		security.DeleteSecretFromKeyring(connectionID, mockConnectionUsername)
 	})
 }
+
+// clientMockRejectedToken : Rejects the first request to PFE, answers the token refresh, then accepts the
+// request, recording the bodies PFE received
+type clientMockRejectedToken struct {
+	pfeBodies []string
+	tokens    []string
+}
+
+func (c *clientMockRejectedToken) Do(req *http.Request) (*http.Response, error) {
+	body, _ := ioutil.ReadAll(req.Body)
+	if req.URL.Path == "/auth/realms/mockRealm/protocol/openid-connect/token" {
+		tokens, _ := json.Marshal(&security.AuthToken{AccessToken: "newAccessToken"})
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(tokens))}, nil
+	}
+	c.pfeBodies = append(c.pfeBodies, string(body))
+	c.tokens = append(c.tokens, req.Header.Get("Authorization"))
+	statusCode := http.StatusOK
+	if len(c.pfeBodies) == 1 {
+		statusCode = http.StatusFound
+	}
+	return &http.Response{StatusCode: statusCode, Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+}
+
+func TestDispatchHTTPRequestRetriesRejectedToken(t *testing.T) {
+	originalUseInsecureKeyring := globals.UseInsecureKeyring
+	globals.SetUseInsecureKeyring(true)
+	defer globals.SetUseInsecureKeyring(originalUseInsecureKeyring)
+	security.StoreSecretInKeyring(connectionID, "access_token", "rejectedAccessToken")
+	security.StoreSecretInKeyring(connectionID, "refresh_token", "mockRefreshToken")
+	defer security.DeleteSecretFromKeyring(connectionID, "access_token")
+	defer security.DeleteSecretFromKeyring(connectionID, "refresh_token")
+
+	mockClient := &clientMockRejectedToken{}
+	mockConnection := connections.Connection{ID: connectionID, AuthURL: "http://mockauth", Realm: "mockRealm", ClientID: "mockClientID"}
+	// a body of known length that can only be read once
+	mockRequest := httptest.NewRequest("POST", "/api/v1/projects", ioutil.NopCloser(bytes.NewReader([]byte(`{"name": "api"}`))))
+	mockRequest.ContentLength = int64(len(`{"name": "api"}`))
+
+	gotResp, err := DispatchHTTPRequest(mockClient, mockRequest, &mockConnection)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, gotResp.StatusCode)
+	assert.Equal(t, []string{`{"name": "api"}`, `{"name": "api"}`}, mockClient.pfeBodies)
+	assert.Equal(t, []string{"bearer rejectedAccessToken", "bearer newAccessToken"}, mockClient.tokens)
+}
+
+func TestDispatchHTTPRequestAsServiceAccount(t *testing.T) {
+	os.Setenv("CW_TEST_CLIENT_SECRET", "mockSecret")
+	defer os.Unsetenv("CW_TEST_CLIENT_SECRET")
+
+	mockClient := &clientMockRejectedToken{}
+	mockConnection := connections.Connection{
+		ID:             "pipeline",
+		AuthURL:        "http://mockauth",
+		Realm:          "mockRealm",
+		ServiceAccount: &connections.ServiceAccount{ClientID: "pipeline", SecretEnv: "CW_TEST_CLIENT_SECRET"},
+	}
+	mockRequest := httptest.NewRequest("POST", "/api/v1/projects", ioutil.NopCloser(bytes.NewReader([]byte(`{"name": "api"}`))))
+	mockRequest.ContentLength = int64(len(`{"name": "api"}`))
+
+	gotResp, err := DispatchHTTPRequest(mockClient, mockRequest, &mockConnection)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, gotResp.StatusCode)
+	assert.Equal(t, []string{`{"name": "api"}`, `{"name": "api"}`}, mockClient.pfeBodies)
+}
+
+func TestDispatchHTTPRequestStreamedBody(t *testing.T) {
+	originalUseInsecureKeyring := globals.UseInsecureKeyring
+	globals.SetUseInsecureKeyring(true)
+	defer globals.SetUseInsecureKeyring(originalUseInsecureKeyring)
+	security.StoreSecretInKeyring(connectionID, "access_token", "rejectedAccessToken")
+	security.StoreSecretInKeyring(connectionID, "refresh_token", "mockRefreshToken")
+	defer security.DeleteSecretFromKeyring(connectionID, "access_token")
+	defer security.DeleteSecretFromKeyring(connectionID, "refresh_token")
+
+	mockClient := &clientMockRejectedToken{}
+	mockConnection := connections.Connection{ID: connectionID, AuthURL: "http://mockauth", Realm: "mockRealm", ClientID: "mockClientID"}
+	// a body of unknown length is streamed, and never buffered
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		pipeWriter.Write([]byte("archive"))
+		pipeWriter.Close()
+	}()
+	mockRequest := httptest.NewRequest("PUT", "/api/v1/projects/mockID/upload/archive", pipeReader)
+
+	gotResp, err := DispatchHTTPRequest(mockClient, mockRequest, &mockConnection)
+	assert.Nil(t, gotResp)
+	assert.True(t, err.IsAuthRetryError())
+	assert.Nil(t, mockRequest.GetBody)
+	assert.Equal(t, []string{"archive"}, mockClient.pfeBodies)
+	assert.Equal(t, []string{"bearer rejectedAccessToken"}, mockClient.tokens)
+}
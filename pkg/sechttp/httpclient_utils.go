@@ -21,15 +21,23 @@ type HTTPSecError struct {
 }
 
 const (
-	errOpNoConnection = "tx_connection"
-	errOpAuthFailed   = "tx_auth"
-	errOpFailed       = "tx_failed"
-	errOpNoPassword   = "tx_nopassword"
+	errOpNoConnection  = "tx_connection"
+	errOpAuthFailed    = "tx_auth"
+	errOpFailed        = "tx_failed"
+	errOpNoPassword    = "tx_nopassword"
+	errOpLoginRequired = "tx_login_required"
+	errOpAuthRetry     = "tx_auth_retry"
+	errOpBodyRead      = "tx_body_read"
 )
 
+// maxReplayableBody is the largest request body, in bytes, that is held in memory so it can be sent again
+const maxReplayableBody = 1 << 20
+
 const (
 	errConnetionNotFound = "Cant find a valid connection"
 	errMissingPassword   = "Unable to find password in keychain"
+	errLoginRequired     = "Session for connection %s has expired, log in again with: cwctl sectoken login --conid %[1]s --browser (or --device)"
+	errAuthRetry         = "Access token was rejected and the streamed request can't be sent again, send it with a new body"
 )
 
 // HTTPSecError : Error formatted in JSON containing an errorOp and a description from
@@ -43,3 +51,14 @@ func (se *HTTPSecError) Error() string {
 	jsonError, _ := json.Marshal(tempOutput)
 	return string(jsonError)
 }
+
+// IsConnectionError : Reports whether the request failed because the server could not be reached
+func (se *HTTPSecError) IsConnectionError() bool {
+	return se.Op == errOpNoConnection
+}
+
+// IsAuthRetryError : Reports whether a request with a streamed body was rejected for its access token. A new
+// token is ready, so the caller can send the request again with a new body
+func (se *HTTPSecError) IsAuthRetryError() bool {
+	return se.Op == errOpAuthRetry
+}
@@ -16,6 +16,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
@@ -25,13 +26,14 @@ import (
 
 // AuthToken from the keycloak server after successfully authenticating
 type AuthToken struct {
-	AccessToken     string `json:"access_token"`
-	ExpiresIn       int    `json:"expires_in"`
-	RefreshToken    string `json:"refresh_token"`
-	TokenType       string `json:"token_type"`
-	NotBeforePolicy int    `json:"not-before-policy"`
-	SessionState    string `json:"session_state"`
-	Scope           string `json:"scope"`
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"`
+	TokenType        string `json:"token_type"`
+	NotBeforePolicy  int    `json:"not-before-policy"`
+	SessionState     string `json:"session_state"`
+	Scope            string `json:"scope"`
 }
 
 // SecAuthenticate - sends credentials to the auth server for a specific realm and returns an AuthToken
@@ -160,11 +162,7 @@ func SecAuthenticate(httpClient utils.HTTPClient, c *cli.Context, connectionReal
 
 	// store access and refresh tokens in keyring if a connection is known
 	if connection != nil {
-		secErr := SecKeyUpdate(connectionID, "access_token", authToken.AccessToken)
-		if secErr != nil {
-			return &authToken, secErr
-		}
-		secErr = SecKeyUpdate(connectionID, "refresh_token", authToken.RefreshToken)
+		secErr := storeTokens(connectionID, &authToken)
 		if secErr != nil {
 			return &authToken, secErr
 		}
@@ -192,7 +190,7 @@ func SecRefreshTokens(httpClient utils.HTTPClient, c *cli.Context) (*AuthToken,
 		return nil, secErr
 	}
 	// Read refresh token
-	refreshToken, secErr := SecKeyGetSecret(connection.ID, "refresh_token")
+	refreshToken, secErr := SecKeyGetSecret(connection.ID, refreshTokenKey)
 	if secErr != nil {
 		return nil, secErr
 	}
@@ -204,20 +202,35 @@ func SecRefreshTokens(httpClient utils.HTTPClient, c *cli.Context) (*AuthToken,
 	return authTokens, nil
 }
 
-// SecRefreshAccessToken : Obtain an access token using a refresh token
+// SecRefreshAccessToken : Obtain an access token using a refresh token, and save the new tokens in the keyring
 func SecRefreshAccessToken(httpClient utils.HTTPClient, connection *connections.Connection, refreshToken string) (*AuthToken, *SecError) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {connection.ClientID},
+		"refresh_token": {refreshToken},
+	}
+	authToken, secErr := requestTokens(httpClient, connection, form)
+	if secErr != nil {
+		return nil, secErr
+	}
+	// the refresh token may have been replaced too
+	return authToken, storeTokens(connection.ID, authToken)
+}
 
-	// build REST request
-	url := connection.AuthURL + "/auth/realms/" + connection.Realm + "/protocol/openid-connect/token"
+// tokenURL : The OpenID Connect token endpoint of a connection's realm
+func tokenURL(connection *connections.Connection) string {
+	return connection.AuthURL + "/auth/realms/" + connection.Realm + "/protocol/openid-connect/token"
+}
 
-	payload := strings.NewReader("grant_type=refresh_token&client_id=" + connection.ClientID + "&refresh_token=" + refreshToken)
-	req, err := http.NewRequest("POST", url, payload)
+// requestTokens : Request tokens from a connection's token endpoint, with a form for one of the OAuth grants
+func requestTokens(httpClient utils.HTTPClient, connection *connections.Connection, form url.Values) (*AuthToken, *SecError) {
+	req, err := http.NewRequest("POST", tokenURL(connection), strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, &SecError{errOpConnection, err, err.Error()}
 	}
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Cache-Control", "no-cache")
-	req.Header.Add("cache-control", "no-cache")
 
 	// send request
 	res, err := httpClient.Do(req)
@@ -226,6 +239,9 @@ func SecRefreshAccessToken(httpClient utils.HTTPClient, connection *connections.
 	}
 	defer res.Body.Close()
 	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, &SecError{errOpResponse, err, err.Error()}
+	}
 
 	// Handle special case http status codes
 	switch httpCode := res.StatusCode; {
@@ -233,6 +249,13 @@ func SecRefreshAccessToken(httpClient utils.HTTPClient, connection *connections.
 		keycloakAPIError := parseKeycloakError(string(body), res.StatusCode)
 		kcError := errors.New(string(keycloakAPIError.ErrorDescription))
 		return nil, &SecError{keycloakAPIError.Error, kcError, kcError.Error()}
+	case httpCode == http.StatusNotFound:
+		keycloakAPIError := parseKeycloakError(string(body), res.StatusCode)
+		kcError := errors.New(string(keycloakAPIError.Error))
+		return nil, &SecError{errOpResponse, kcError, kcError.Error()}
+	case httpCode == http.StatusServiceUnavailable:
+		txtError := errors.New(textAuthIsDown)
+		return nil, &SecError{errOpResponse, txtError, txtError.Error()}
 	case httpCode != http.StatusOK:
 		err = errors.New(string(body))
 		return nil, &SecError{errOpResponse, err, err.Error()}
@@ -240,23 +263,25 @@ func SecRefreshAccessToken(httpClient utils.HTTPClient, connection *connections.
 
 	// Parse and return AuthToken
 	authToken := AuthToken{}
-	err = json.Unmarshal([]byte(body), &authToken)
-
+	err = json.Unmarshal(body, &authToken)
 	if err != nil {
-		// re-save the access and refresh token
-		secErr := SecKeyUpdate(connection.ID, "access_token", authToken.AccessToken)
-		if secErr != nil {
-			return &authToken, secErr
-		}
-		secErr = SecKeyUpdate(connection.ID, "refresh_token", authToken.RefreshToken)
+		return nil, &SecError{errOpResponseFormat, err, textUnableToParse}
+	}
+	return &authToken, nil
+}
 
+// storeTokens : Save the access and refresh tokens of a connection, and when they expire, in the keyring
+func storeTokens(connectionID string, authToken *AuthToken) *SecError {
+	secErr := SecKeyUpdate(connectionID, accessTokenKey, authToken.AccessToken)
+	if secErr != nil {
+		return secErr
+	}
+	// keep the current refresh token if the server didn't issue a new one
+	if authToken.RefreshToken != "" {
+		secErr = SecKeyUpdate(connectionID, refreshTokenKey, authToken.RefreshToken)
 		if secErr != nil {
-			return &authToken, secErr
+			return secErr
 		}
-
-		respErr := errors.New(string(body))
-		return nil, &SecError{errOpResponse, respErr, respErr.Error()}
 	}
-
-	return &authToken, nil
+	return storeTokenExpiry(connectionID, authToken)
 }
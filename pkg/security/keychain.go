@@ -13,21 +13,39 @@ package security
 
 import (
 	"bytes"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/globals"
+	logr "github.com/sirupsen/logrus"
 	"github.com/zalando/go-keyring"
 )
 
 var insecureKeyringDir = connections.GetConnectionConfigDir()
 
+// keyringLockTimeout : How long to wait for another process to finish updating the insecure keyring
+var keyringLockTimeout = 10 * time.Second
+
+const (
+	// keyringLockRetryInterval : How often a locked insecure keyring is checked to see if it has been released
+	keyringLockRetryInterval = 20 * time.Millisecond
+	// keyringLockStale : How old a lock on the insecure keyring must be for it to have been abandoned
+	keyringLockStale = time.Minute
+)
+
+// KeyringEntry : A secret in the keyring, identified by its connection and username, without its value
+type KeyringEntry struct {
+	ConnectionID string `json:"conid"`
+	Username     string `json:"username"`
+}
+
 // KeyringSecret : Secret
 type KeyringSecret struct {
 	Service  []byte `json:"service"`
@@ -67,6 +85,94 @@ func SecKeyUpdate(connectionID string, username string, password string) *SecErr
 	return nil
 }
 
+// SecKeyList : List the secrets in the keyring, without their values. The system keyring can't be searched, so
+// only the secrets this CLI saves for the connections it knows about are listed from it
+func SecKeyList() ([]KeyringEntry, *SecError) {
+	entries := []KeyringEntry{}
+	if globals.UseInsecureKeyring {
+		if _, statErr := os.Stat(GetPathToInsecureKeyring()); os.IsNotExist(statErr) {
+			return entries, nil
+		}
+		secrets, secErr := readInsecureKeyring()
+		if secErr != nil {
+			return nil, secErr
+		}
+		for _, secret := range secrets {
+			conID := strings.TrimPrefix(string(secret.Service), KeyringServiceName+".")
+			entries = append(entries, KeyringEntry{ConnectionID: conID, Username: string(secret.Username)})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].ConnectionID != entries[j].ConnectionID {
+				return entries[i].ConnectionID < entries[j].ConnectionID
+			}
+			return entries[i].Username < entries[j].Username
+		})
+		return entries, nil
+	}
+
+	connectionList, conErr := connections.GetAllConnections()
+	if conErr != nil {
+		return nil, &SecError{errOpConConfig, conErr.Err, conErr.Desc}
+	}
+	for _, connection := range connectionList {
+		conID := strings.TrimSpace(strings.ToLower(connection.ID))
+		for _, uName := range keyringUsernames(conID) {
+			if _, secErr := GetSecretFromKeyring(conID, uName); secErr == nil {
+				entries = append(entries, KeyringEntry{ConnectionID: conID, Username: uName})
+			}
+		}
+	}
+	return entries, nil
+}
+
+// SecKeyDelete : Delete a secret from the keyring, or all of a connection's secrets if no username is given,
+// returning the secrets that were deleted
+func SecKeyDelete(connectionID string, username string) ([]KeyringEntry, *SecError) {
+	conID := strings.TrimSpace(strings.ToLower(connectionID))
+	uName := strings.TrimSpace(strings.ToLower(username))
+	if uName != "" {
+		if secErr := DeleteSecretFromKeyring(conID, uName); secErr != nil {
+			return nil, secErr
+		}
+		return []KeyringEntry{{ConnectionID: conID, Username: uName}}, nil
+	}
+
+	// the secrets of removed connections are still in the insecure keyring, so it is searched
+	candidates := keyringUsernames(conID)
+	if globals.UseInsecureKeyring {
+		entries, secErr := SecKeyList()
+		if secErr != nil {
+			return nil, secErr
+		}
+		candidates = []string{}
+		for _, entry := range entries {
+			if entry.ConnectionID == conID {
+				candidates = append(candidates, entry.Username)
+			}
+		}
+	}
+	deleted := []KeyringEntry{}
+	for _, candidate := range candidates {
+		if secErr := DeleteSecretFromKeyring(conID, candidate); secErr == nil {
+			deleted = append(deleted, KeyringEntry{ConnectionID: conID, Username: candidate})
+		}
+	}
+	if len(deleted) == 0 {
+		err := fmt.Errorf("No secrets for connection %s %s", conID, textNotFoundSuffix)
+		return nil, &SecError{errOpNotFound, err, err.Error()}
+	}
+	return deleted, nil
+}
+
+// keyringUsernames : The names of the secrets this CLI saves in the keyring for a connection
+func keyringUsernames(connectionID string) []string {
+	usernames := []string{accessTokenKey, refreshTokenKey, accessTokenExpiryKey, refreshTokenExpiryKey}
+	if connection, conErr := connections.GetConnectionByID(connectionID); conErr == nil && connection.Username != "" {
+		usernames = append([]string{strings.ToLower(connection.Username)}, usernames...)
+	}
+	return usernames
+}
+
 // SecKeyGetSecret : retrieve secret / credentials from the keyring
 func SecKeyGetSecret(connectionID, username string) (string, *SecError) {
 	conID := strings.TrimSpace(strings.ToLower(connectionID))
@@ -82,55 +188,25 @@ func SecKeyGetSecret(connectionID, username string) (string, *SecError) {
 func StoreSecretInKeyring(connectionID, uName, pass string) *SecError {
 	service := connectionIDToService(connectionID)
 	if globals.UseInsecureKeyring {
-		_, statErr := os.Stat(GetPathToInsecureKeyring())
-		if os.IsNotExist(statErr) {
-			mkdirErr := os.MkdirAll(insecureKeyringDir, 0600)
-			if mkdirErr != nil {
-				return &SecError{errOpInsecureKeyring, mkdirErr, mkdirErr.Error()}
-			}
-			_, openFileErr := os.OpenFile(GetPathToInsecureKeyring(), os.O_CREATE, 0600)
-			if openFileErr != nil {
-				return &SecError{errOpInsecureKeyring, openFileErr, openFileErr.Error()}
-			}
-		}
-
-		existingSecrets := []KeyringSecret{}
-		file, readErr := ioutil.ReadFile(GetPathToInsecureKeyring())
-		if readErr != nil {
-			return &SecError{errOpInsecureKeyring, readErr, readErr.Error()}
-		}
-		if len(file) != 0 {
-			unmarshalErr := json.Unmarshal([]byte(file), &existingSecrets)
-			if unmarshalErr != nil {
-				return &SecError{errOpInsecureKeyring, unmarshalErr, unmarshalErr.Error()}
-			}
-		}
 		newSecret := KeyringSecret{
 			Service:  []byte(service),
 			Username: []byte(uName),
 			Password: []byte(pass),
 		}
-		indexOfSecretToUpdate := -1
+		return updateInsecureKeyring(true, func(existingSecrets []KeyringSecret) ([]KeyringSecret, *SecError) {
+			indexOfSecretToUpdate := -1
 
-		for i, existingSecret := range existingSecrets {
-			if doSecretsMatch(existingSecret, newSecret) {
-				indexOfSecretToUpdate = i
+			for i, existingSecret := range existingSecrets {
+				if doSecretsMatch(existingSecret, newSecret) {
+					indexOfSecretToUpdate = i
+				}
 			}
-		}
-		if indexOfSecretToUpdate > -1 {
-			// remove existing secret
-			existingSecrets = append(existingSecrets[:indexOfSecretToUpdate], existingSecrets[indexOfSecretToUpdate+1:]...)
-		}
-		secrets := append(existingSecrets, newSecret)
-		body, marshallErr := json.MarshalIndent(secrets, "", "\t")
-		if marshallErr != nil {
-			return &SecError{errOpInsecureKeyring, marshallErr, marshallErr.Error()}
-		}
-		writeErr := ioutil.WriteFile(GetPathToInsecureKeyring(), body, 0644)
-		if writeErr != nil {
-			return &SecError{errOpInsecureKeyring, writeErr, writeErr.Error()}
-		}
-		return nil
+			if indexOfSecretToUpdate > -1 {
+				// remove existing secret
+				existingSecrets = append(existingSecrets[:indexOfSecretToUpdate], existingSecrets[indexOfSecretToUpdate+1:]...)
+			}
+			return append(existingSecrets, newSecret), nil
+		})
 	}
 
 	// else store it in system keyring
@@ -176,41 +252,23 @@ func GetSecretFromKeyring(connectionID, uName string) (string, *SecError) {
 func DeleteSecretFromKeyring(connectionID, uName string) *SecError {
 	service := connectionIDToService(connectionID)
 	if globals.UseInsecureKeyring {
-		secrets, readErr := readInsecureKeyring()
-		if readErr != nil {
-			return readErr
-		}
-		indexOfSecretToDelete := -1
-		for i, secret := range secrets {
-			sameService := string(secret.Service) == service
-			sameUsername := string(secret.Username) == uName
-			matchingSecret := sameService && sameUsername
-			if matchingSecret {
-				indexOfSecretToDelete = i
+		return updateInsecureKeyring(false, func(secrets []KeyringSecret) ([]KeyringSecret, *SecError) {
+			indexOfSecretToDelete := -1
+			for i, secret := range secrets {
+				sameService := string(secret.Service) == service
+				sameUsername := string(secret.Username) == uName
+				matchingSecret := sameService && sameUsername
+				if matchingSecret {
+					indexOfSecretToDelete = i
+				}
 			}
-		}
-		if indexOfSecretToDelete == -1 {
-			err := fmt.Errorf(textSecretNotFound, service+"."+uName)
-			return &SecError{errOpInsecureKeyring, err, err.Error()}
-		}
-		// remove existing secret
-		secrets = append(secrets[:indexOfSecretToDelete], secrets[indexOfSecretToDelete+1:]...)
-		if len(secrets) == 0 {
-			err := os.Remove(GetPathToInsecureKeyring())
-			if err != nil {
-				return &SecError{errOpInsecureKeyring, err, err.Error()}
+			if indexOfSecretToDelete == -1 {
+				err := fmt.Errorf(textSecretNotFound, service+"."+uName)
+				return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
 			}
-			return nil
-		}
-		body, marshallErr := json.MarshalIndent(secrets, "", "\t")
-		if marshallErr != nil {
-			return &SecError{errOpInsecureKeyring, marshallErr, marshallErr.Error()}
-		}
-		writeErr := ioutil.WriteFile(GetPathToInsecureKeyring(), body, 0644)
-		if writeErr != nil {
-			return &SecError{errOpInsecureKeyring, writeErr, writeErr.Error()}
-		}
-		return nil
+			// remove existing secret
+			return append(secrets[:indexOfSecretToDelete], secrets[indexOfSecretToDelete+1:]...), nil
+		})
 	}
 	// else delete from system keyring
 	err := keyring.Delete(service, uName)
@@ -224,23 +282,121 @@ func DeleteSecretFromKeyring(connectionID, uName string) *SecError {
 	return nil
 }
 
+// readInsecureKeyring : Read and decrypt the secrets in the insecure keyring. A keyring in the plaintext format of
+// older CLIs is encrypted as soon as it is read
 func readInsecureKeyring() ([]KeyringSecret, *SecError) {
+	secrets, plaintext, secErr := loadInsecureKeyring()
+	if secErr != nil {
+		return nil, secErr
+	}
+	if plaintext && len(secrets) > 0 {
+		secErr := updateInsecureKeyring(false, func(secrets []KeyringSecret) ([]KeyringSecret, *SecError) {
+			return secrets, nil
+		})
+		if secErr != nil {
+			logr.Tracef("Unable to encrypt the insecure keyring: %v", secErr.Desc)
+		}
+	}
+	return secrets, nil
+}
+
+// loadInsecureKeyring : Read and decrypt the secrets in the insecure keyring, reporting whether it was plaintext
+func loadInsecureKeyring() ([]KeyringSecret, bool, *SecError) {
 	file, readErr := ioutil.ReadFile(GetPathToInsecureKeyring())
 	if readErr != nil {
 		if os.IsNotExist(readErr) {
 			err := errors.New(textKeyringNotFound)
-			return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+			return nil, false, &SecError{errOpInsecureKeyring, err, err.Error()}
+		}
+		return nil, false, &SecError{errOpInsecureKeyring, readErr, readErr.Error()}
+	}
+	return decryptKeyring(file)
+}
+
+// updateInsecureKeyring : Replace the secrets in the insecure keyring with those returned by update, which is
+// given the current secrets. Other processes are locked out until the keyring is written, so that concurrent
+// updates aren't lost. A missing keyring is only treated as empty if create is set, and one left with no
+// secrets is removed
+func updateInsecureKeyring(create bool, update func([]KeyringSecret) ([]KeyringSecret, *SecError)) *SecError {
+	unlock, secErr := lockInsecureKeyring()
+	if secErr != nil {
+		return secErr
+	}
+	defer unlock()
+
+	secrets, _, secErr := loadInsecureKeyring()
+	if secErr != nil && !(create && secErr.Desc == textKeyringNotFound) {
+		return secErr
+	}
+	secrets, secErr = update(secrets)
+	if secErr != nil {
+		return secErr
+	}
+	if len(secrets) == 0 {
+		if err := os.Remove(GetPathToInsecureKeyring()); err != nil && !os.IsNotExist(err) {
+			return &SecError{errOpInsecureKeyring, err, err.Error()}
 		}
-		return nil, &SecError{errOpInsecureKeyring, readErr, readErr.Error()}
+		return nil
+	}
+	return writeInsecureKeyring(secrets)
+}
+
+// lockInsecureKeyring : Take the lock on the insecure keyring, waiting for another process to release it,
+// and return the function that releases it. The lock is a file created exclusively, so it works on every platform.
+// A lock older than keyringLockStale was left by a process that exited while holding it, so it is broken
+func lockInsecureKeyring() (func(), *SecError) {
+	if mkdirErr := os.MkdirAll(insecureKeyringDir, 0700); mkdirErr != nil {
+		return nil, &SecError{errOpInsecureKeyring, mkdirErr, mkdirErr.Error()}
 	}
-	secrets := []KeyringSecret{}
-	if len(file) != 0 {
-		unmarshalErr := json.Unmarshal([]byte(file), &secrets)
-		if unmarshalErr != nil {
-			return nil, &SecError{errOpInsecureKeyring, unmarshalErr, unmarshalErr.Error()}
+	lockFile := GetPathToInsecureKeyring() + ".lock"
+	deadline := time.Now().Add(keyringLockTimeout)
+	for {
+		file, err := os.OpenFile(lockFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lockFile) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+		}
+		if info, statErr := os.Stat(lockFile); statErr == nil && time.Since(info.ModTime()) > keyringLockStale {
+			os.Remove(lockFile)
+			continue
+		}
+		if time.Now().After(deadline) {
+			err := fmt.Errorf(textKeyringLocked, lockFile)
+			return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
 		}
+		time.Sleep(keyringLockRetryInterval)
 	}
-	return secrets, nil
+}
+
+// writeInsecureKeyring : Encrypt and write the secrets of the insecure keyring. Callers must hold the lock
+func writeInsecureKeyring(secrets []KeyringSecret) *SecError {
+	body, secErr := encryptKeyring(secrets)
+	if secErr != nil {
+		return secErr
+	}
+	if mkdirErr := os.MkdirAll(insecureKeyringDir, 0700); mkdirErr != nil {
+		return &SecError{errOpInsecureKeyring, mkdirErr, mkdirErr.Error()}
+	}
+	// write a new file and rename it over the old one, so a failed write can't lose the secrets
+	tempFile, err := ioutil.TempFile(insecureKeyringDir, path.Base(GetPathToInsecureKeyring())+".*.tmp")
+	if err != nil {
+		return &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	_, err = tempFile.Write(body)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFile.Name(), GetPathToInsecureKeyring())
+	}
+	if err != nil {
+		os.Remove(tempFile.Name())
+		return &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	return nil
 }
 
 func connectionIDToService(connectionID string) string {
@@ -0,0 +1,297 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// The environment variables that configure how the insecure keyring is encrypted. A passphrase takes precedence
+// over a key file, and without either a key file is created next to the keyring
+const (
+	envKeyringPassphrase = "INSECURE_KEYRING_PASSPHRASE"
+	envKeyringKeyFile    = "INSECURE_KEYRING_KEYFILE"
+)
+
+// The sources of the key an insecure keyring is encrypted with
+const (
+	keySourcePassphrase = "passphrase"
+	keySourceKeyFile    = "keyfile"
+)
+
+// How often, and how many times, a key file another process has just created is checked for its key
+const (
+	keyFileReadRetryInterval = 10 * time.Millisecond
+	keyFileReadRetries       = 50
+)
+
+// passphraseKey : A key derived from the keyring passphrase, and the passphrase it was derived from
+type passphraseKey struct {
+	passphrase string
+	key        []byte
+}
+
+var (
+	// passphraseKeys : The keys derived from the keyring passphrase, by salt. Deriving a key is deliberately slow,
+	// so each is only derived once in the life of the CLI process
+	passphraseKeys      = map[string]passphraseKey{}
+	passphraseKeysMutex sync.Mutex
+
+	// scryptKey : Derives a key from a passphrase, replaced in tests
+	scryptKey = scrypt.Key
+)
+
+// encryptedKeyringVersion : The version of the encrypted keyring format, which is increased when it changes
+const encryptedKeyringVersion = 1
+
+// encryptedKeyring : The insecure keyring file, holding its secrets encrypted with AES-256-GCM, and the salt the
+// key was derived from a passphrase with, if it was
+type encryptedKeyring struct {
+	Version    int    `json:"version"`
+	KeySource  string `json:"keysource"`
+	Salt       []byte `json:"salt,omitempty"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// encryptKeyring : Encrypt the secrets of the insecure keyring with the configured key
+func encryptKeyring(secrets []KeyringSecret) ([]byte, *SecError) {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	keyring := encryptedKeyring{Version: encryptedKeyringVersion, KeySource: keySourceKeyFile}
+	if passphrase := os.Getenv(envKeyringPassphrase); passphrase != "" {
+		keyring.KeySource = keySourcePassphrase
+		if keyring.Salt, err = passphraseSalt(passphrase); err != nil {
+			return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+		}
+	}
+	key, secErr := keyringKey(keyring.KeySource, keyring.Salt, true)
+	if secErr != nil {
+		return nil, secErr
+	}
+	gcm, err := newKeyringCipher(key)
+	if err != nil {
+		return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	if keyring.Nonce, err = randomBytes(gcm.NonceSize()); err != nil {
+		return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	keyring.Ciphertext = gcm.Seal(nil, keyring.Nonce, plaintext, nil)
+	body, err := json.MarshalIndent(keyring, "", "\t")
+	if err != nil {
+		return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	return body, nil
+}
+
+// decryptKeyring : Decrypt the secrets of the insecure keyring, reporting whether the file was in the plaintext
+// format of older CLIs, so it can be encrypted
+func decryptKeyring(file []byte) ([]KeyringSecret, bool, *SecError) {
+	secrets := []KeyringSecret{}
+	content := strings.TrimSpace(string(file))
+	if content == "" {
+		return secrets, false, nil
+	}
+	if strings.HasPrefix(content, "[") {
+		if err := json.Unmarshal(file, &secrets); err != nil {
+			return nil, false, &SecError{errOpInsecureKeyring, err, err.Error()}
+		}
+		return secrets, true, nil
+	}
+
+	keyring := encryptedKeyring{}
+	if err := json.Unmarshal(file, &keyring); err != nil {
+		return nil, false, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	if keyring.Version != encryptedKeyringVersion {
+		err := fmt.Errorf("Keyring version %d is not supported", keyring.Version)
+		return nil, false, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	key, secErr := keyringKey(keyring.KeySource, keyring.Salt, false)
+	if secErr != nil {
+		return nil, false, secErr
+	}
+	gcm, err := newKeyringCipher(key)
+	if err != nil {
+		return nil, false, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	if len(keyring.Nonce) != gcm.NonceSize() {
+		err := errors.New("Keyring is corrupt")
+		return nil, false, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	plaintext, err := gcm.Open(nil, keyring.Nonce, keyring.Ciphertext, nil)
+	if err != nil {
+		err := fmt.Errorf("Unable to decrypt the keyring, the %s is wrong or the keyring is corrupt", keyring.KeySource)
+		return nil, false, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, false, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	return secrets, false, nil
+}
+
+// keyringKey : The key of the insecure keyring, derived from the passphrase or read from the key file. A missing
+// key file is created if the keyring is being written
+func keyringKey(keySource string, salt []byte, create bool) ([]byte, *SecError) {
+	switch keySource {
+	case keySourcePassphrase:
+		passphrase := os.Getenv(envKeyringPassphrase)
+		if passphrase == "" {
+			err := fmt.Errorf("Keyring is encrypted with a passphrase, set it in %s", envKeyringPassphrase)
+			return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+		}
+		key, err := derivePassphraseKey(passphrase, salt)
+		if err != nil {
+			return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+		}
+		return key, nil
+	case keySourceKeyFile:
+		return readKeyFile(getPathToKeyFile(), create)
+	}
+	err := fmt.Errorf("Keyring key source %s is not supported", keySource)
+	return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+}
+
+// derivePassphraseKey : Derive the key of the insecure keyring from a passphrase and salt, reusing the key if it
+// has already been derived
+func derivePassphraseKey(passphrase string, salt []byte) ([]byte, error) {
+	passphraseKeysMutex.Lock()
+	defer passphraseKeysMutex.Unlock()
+
+	if cached, ok := passphraseKeys[string(salt)]; ok && cached.passphrase == passphrase {
+		return cached.key, nil
+	}
+	key, err := scryptKey([]byte(passphrase), salt, 32768, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	passphraseKeys[string(salt)] = passphraseKey{passphrase, key}
+	return key, nil
+}
+
+// passphraseSalt : The salt to encrypt the insecure keyring with a passphrase with. A salt a key has already been
+// derived from with the passphrase is reused, so that writing the keyring doesn't derive a new key
+func passphraseSalt(passphrase string) ([]byte, error) {
+	passphraseKeysMutex.Lock()
+	defer passphraseKeysMutex.Unlock()
+
+	for salt, cached := range passphraseKeys {
+		if cached.passphrase == passphrase {
+			return []byte(salt), nil
+		}
+	}
+	return randomBytes(16)
+}
+
+// readKeyFile : Read the key in a key file, which must only be readable by its owner, creating it if asked to
+func readKeyFile(keyFile string, create bool) ([]byte, *SecError) {
+	info, err := os.Stat(keyFile)
+	if os.IsNotExist(err) && create {
+		key, created, secErr := createKeyFile(keyFile)
+		if secErr != nil || created {
+			return key, secErr
+		}
+		// another process created the key file first, so its key is used once it has been written
+		for retry := 0; retry < keyFileReadRetries; retry++ {
+			if info, err = os.Stat(keyFile); err != nil || info.Size() > 0 {
+				break
+			}
+			time.Sleep(keyFileReadRetryInterval)
+		}
+	}
+	if err != nil {
+		err := fmt.Errorf("Unable to read keyring key file: %v", err)
+		return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	// Windows doesn't have Unix permissions, so the file's ACL must be relied on
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		err := fmt.Errorf("Keyring key file %s must only be readable by its owner, run: chmod 600 %[1]s", keyFile)
+		return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	content, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(key) != 32 {
+		err := fmt.Errorf("Keyring key file %s must hold a base64 encoded 32 byte key", keyFile)
+		return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	return key, nil
+}
+
+// createKeyFile : Create a key file holding a new random key, unless the file already exists, in which case
+// created is false. The file is created exclusively so that processes creating it at once agree on the key
+func createKeyFile(keyFile string) ([]byte, bool, *SecError) {
+	key, err := randomBytes(32)
+	if err != nil {
+		return nil, false, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	os.MkdirAll(path.Dir(keyFile), 0700)
+	file, err := os.OpenFile(keyFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	_, err = file.Write([]byte(base64.StdEncoding.EncodeToString(key) + "\n"))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(keyFile)
+		return nil, false, &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	return key, true, nil
+}
+
+// getPathToKeyFile : The path of the key file the insecure keyring is encrypted with, when it has no passphrase
+func getPathToKeyFile() string {
+	if keyFile := os.Getenv(envKeyringKeyFile); keyFile != "" {
+		return keyFile
+	}
+	return path.Join(insecureKeyringDir, "insecureKeychain.key")
+}
+
+func newKeyringCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(size int) ([]byte, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return nil, err
+	}
+	return bytes, nil
+}
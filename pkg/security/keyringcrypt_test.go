@@ -0,0 +1,210 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/stretchr/testify/assert"
+)
+
+// useTestKeyring : Point the insecure keyring and its key file at a temporary directory, returning a function
+// that restores them
+func useTestKeyring(t *testing.T) func() {
+	originalDir := insecureKeyringDir
+	originalUseInsecureKeyring := globals.UseInsecureKeyring
+	insecureKeyringDir, _ = ioutil.TempDir("", "keyring")
+	globals.SetUseInsecureKeyring(true)
+	os.Unsetenv(envKeyringPassphrase)
+	os.Unsetenv(envKeyringKeyFile)
+	passphraseKeys = map[string]passphraseKey{}
+	return func() {
+		os.RemoveAll(insecureKeyringDir)
+		insecureKeyringDir = originalDir
+		globals.SetUseInsecureKeyring(originalUseInsecureKeyring)
+		os.Unsetenv(envKeyringPassphrase)
+		os.Unsetenv(envKeyringKeyFile)
+	}
+}
+
+func TestEncryptKeyring(t *testing.T) {
+	secrets := []KeyringSecret{{Service: []byte("org.eclipse.codewind.remote"), Username: []byte("refresh_token"), Password: []byte("mockRefreshToken")}}
+
+	t.Run("success case - a key file is created and used", func(t *testing.T) {
+		defer useTestKeyring(t)()
+		body, secErr := encryptKeyring(secrets)
+		assert.Nil(t, secErr)
+		assert.False(t, strings.Contains(string(body), "mockRefreshToken"))
+		var keyring encryptedKeyring
+		json.Unmarshal(body, &keyring)
+		assert.Equal(t, keySourceKeyFile, keyring.KeySource)
+		if runtime.GOOS != "windows" {
+			info, _ := os.Stat(getPathToKeyFile())
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+
+		decrypted, plaintext, secErr := decryptKeyring(body)
+		assert.Nil(t, secErr)
+		assert.False(t, plaintext)
+		assert.Equal(t, secrets, decrypted)
+	})
+
+	t.Run("success case - a passphrase is used", func(t *testing.T) {
+		defer useTestKeyring(t)()
+		os.Setenv(envKeyringPassphrase, "correct horse")
+		body, secErr := encryptKeyring(secrets)
+		assert.Nil(t, secErr)
+		_, statErr := os.Stat(getPathToKeyFile())
+		assert.True(t, os.IsNotExist(statErr))
+
+		decrypted, _, secErr := decryptKeyring(body)
+		assert.Nil(t, secErr)
+		assert.Equal(t, secrets, decrypted)
+
+		os.Setenv(envKeyringPassphrase, "wrong horse")
+		_, _, secErr = decryptKeyring(body)
+		assert.Equal(t, "Unable to decrypt the keyring, the passphrase is wrong or the keyring is corrupt", secErr.Desc)
+
+		os.Unsetenv(envKeyringPassphrase)
+		_, _, secErr = decryptKeyring(body)
+		assert.Equal(t, "Keyring is encrypted with a passphrase, set it in INSECURE_KEYRING_PASSPHRASE", secErr.Desc)
+	})
+
+	t.Run("success case - a key is only derived from the passphrase once", func(t *testing.T) {
+		defer useTestKeyring(t)()
+		os.Setenv(envKeyringPassphrase, "battery staple")
+		originalScryptKey := scryptKey
+		defer func() { scryptKey = originalScryptKey }()
+		derivations := 0
+		scryptKey = func(password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
+			derivations++
+			return originalScryptKey(password, salt, N, r, p, keyLen)
+		}
+
+		for i := 0; i < 3; i++ {
+			body, secErr := encryptKeyring(secrets)
+			assert.Nil(t, secErr)
+			decrypted, _, secErr := decryptKeyring(body)
+			assert.Nil(t, secErr)
+			assert.Equal(t, secrets, decrypted)
+		}
+		assert.Equal(t, 1, derivations)
+	})
+
+	t.Run("fail case - a key file others can read", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("file permissions are not checked on Windows")
+		}
+		defer useTestKeyring(t)()
+		body, _ := encryptKeyring(secrets)
+		os.Chmod(getPathToKeyFile(), 0644)
+		_, _, secErr := decryptKeyring(body)
+		assert.Equal(t, errOpInsecureKeyring, secErr.Op)
+		assert.Contains(t, secErr.Desc, "must only be readable by its owner")
+	})
+
+	t.Run("success case - a plaintext keyring is read", func(t *testing.T) {
+		plaintextFile, _ := json.MarshalIndent(secrets, "", "\t")
+		decrypted, plaintext, secErr := decryptKeyring(plaintextFile)
+		assert.Nil(t, secErr)
+		assert.True(t, plaintext)
+		assert.Equal(t, secrets, decrypted)
+	})
+}
+
+func TestInsecureKeyringMigration(t *testing.T) {
+	defer useTestKeyring(t)()
+	os.Setenv(envKeyringKeyFile, filepath.Join(insecureKeyringDir, "other.key"))
+	plaintextFile, _ := json.MarshalIndent([]KeyringSecret{
+		{Service: []byte("org.eclipse.codewind.remote"), Username: []byte("developer"), Password: []byte("mockPassword")},
+		{Service: []byte("org.eclipse.codewind.remote"), Username: []byte("refresh_token"), Password: []byte("mockRefreshToken")},
+		{Service: []byte("org.eclipse.codewind.removed"), Username: []byte("access_token"), Password: []byte("mockAccessToken")},
+	}, "", "\t")
+	ioutil.WriteFile(GetPathToInsecureKeyring(), plaintextFile, 0644)
+
+	t.Run("success case - a plaintext keyring is encrypted when it is read", func(t *testing.T) {
+		secret, secErr := GetSecretFromKeyring("remote", "developer")
+		assert.Nil(t, secErr)
+		assert.Equal(t, "mockPassword", secret)
+		file, _ := ioutil.ReadFile(GetPathToInsecureKeyring())
+		assert.False(t, strings.Contains(string(file), "mockPassword"))
+		assert.FileExists(t, filepath.Join(insecureKeyringDir, "other.key"))
+	})
+
+	t.Run("success case - lists the secrets without their values", func(t *testing.T) {
+		entries, secErr := SecKeyList()
+		assert.Nil(t, secErr)
+		assert.Equal(t, []KeyringEntry{{"remote", "developer"}, {"remote", "refresh_token"}, {"removed", "access_token"}}, entries)
+	})
+
+	t.Run("success case - deletes all the secrets of a removed connection", func(t *testing.T) {
+		deleted, secErr := SecKeyDelete("REMOVED", "")
+		assert.Nil(t, secErr)
+		assert.Equal(t, []KeyringEntry{{"removed", "access_token"}}, deleted)
+		entries, _ := SecKeyList()
+		assert.Equal(t, []KeyringEntry{{"remote", "developer"}, {"remote", "refresh_token"}}, entries)
+	})
+
+	t.Run("fail case - a connection without secrets", func(t *testing.T) {
+		_, secErr := SecKeyDelete("removed", "")
+		assert.Equal(t, errOpNotFound, secErr.Op)
+	})
+}
+
+func TestConcurrentKeyringUpdates(t *testing.T) {
+	defer useTestKeyring(t)()
+	const writers = 8
+
+	t.Run("success case - keys created at once are the same key", func(t *testing.T) {
+		keyFile := filepath.Join(insecureKeyringDir, "concurrent.key")
+		keys := make([][]byte, writers)
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				keys[i], _ = readKeyFile(keyFile, true)
+			}(i)
+		}
+		wg.Wait()
+		for i := 1; i < writers; i++ {
+			assert.Equal(t, keys[0], keys[i])
+		}
+	})
+
+	t.Run("success case - secrets stored at once are all kept", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				StoreSecretInKeyring("remote", fmt.Sprintf("user%d", i), "mockPassword")
+			}(i)
+		}
+		wg.Wait()
+		entries, secErr := SecKeyList()
+		assert.Nil(t, secErr)
+		assert.Len(t, entries, writers)
+		tempFiles, _ := filepath.Glob(filepath.Join(insecureKeyringDir, "*.tmp"))
+		assert.Empty(t, tempFiles)
+		assert.True(t, noFileExists(GetPathToInsecureKeyring()+".lock"))
+	})
+}
@@ -0,0 +1,317 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+// callbackPath : The path of the loopback redirect URI the authentication server sends the browser back to
+const callbackPath = "/callback"
+
+// The errors the token endpoint returns while a device login is waiting for the user
+const (
+	deviceAuthorizationPending = "authorization_pending"
+	deviceSlowDown             = "slow_down"
+)
+
+// deviceAuthorization : The codes the authentication server issues to start a device login
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// loginCallback : The authorization code, or the error, the authentication server redirected the browser with
+type loginCallback struct {
+	code string
+	err  error
+}
+
+// SecLogin : Log in to a connection with the login flow chosen by the command line flags, without a password
+func SecLogin(httpClient utils.HTTPClient, c *cli.Context) (*AuthToken, *SecError) {
+	connectionID := strings.TrimSpace(c.String("conid"))
+	browser, device := c.Bool("browser"), c.Bool("device")
+	switch {
+	case browser && device:
+		err := errors.New("Must choose only one of --browser and --device")
+		return nil, &SecError{errOpCLICommand, err, err.Error()}
+	case browser:
+		return SecBrowserLogin(httpClient, connectionID, c.Int("port"), c.Duration("timeout"))
+	case device:
+		return SecDeviceLogin(httpClient, connectionID)
+	}
+	err := errors.New("Must choose how to log in with --browser or --device")
+	return nil, &SecError{errOpCLICommand, err, err.Error()}
+}
+
+// SecBrowserLogin : Log in to a connection in the browser, using the authorization code flow with PKCE, and save
+// the tokens in the keyring. The browser is sent back to a listener on the loopback interface, on the given port
+// or on any free port if it is 0, so the connection's client must allow http://127.0.0.1 redirect URIs
+func SecBrowserLogin(httpClient utils.HTTPClient, connectionID string, port int, timeout time.Duration) (*AuthToken, *SecError) {
+	connection, secErr := getLoginConnection(connectionID)
+	if secErr != nil {
+		return nil, secErr
+	}
+	authToken, secErr := browserLogin(httpClient, connection, port, timeout, openBrowser)
+	if secErr != nil {
+		return nil, secErr
+	}
+	return authToken, storeTokens(connection.ID, authToken)
+}
+
+// getLoginConnection : Get a connection that has an authentication server to log in to
+func getLoginConnection(connectionID string) (*connections.Connection, *SecError) {
+	conID := strings.TrimSpace(strings.ToLower(connectionID))
+	connection, conErr := connections.GetConnectionByID(conID)
+	if conErr != nil {
+		return nil, &SecError{errOpConConfig, conErr.Err, conErr.Desc}
+	}
+	if connection.AuthURL == "" || connection.Realm == "" || connection.ClientID == "" {
+		err := fmt.Errorf("Connection %s has no authentication server to log in to", connection.ID)
+		return nil, &SecError{errOpConConfig, err, err.Error()}
+	}
+	return connection, nil
+}
+
+func browserLogin(httpClient utils.HTTPClient, connection *connections.Connection, port int, timeout time.Duration, open func(string)) (*AuthToken, *SecError) {
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, &SecError{errOpLogin, err, err.Error()}
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, &SecError{errOpLogin, err, err.Error()}
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, &SecError{errOpLogin, err, err.Error()}
+	}
+	redirectURI := fmt.Sprintf("http://127.0.0.1:%d%s", listener.Addr().(*net.TCPAddr).Port, callbackPath)
+	callbacks := make(chan loginCallback, 1)
+	server := &http.Server{Handler: callbackHandler(state, callbacks)}
+	go server.Serve(listener)
+	defer server.Close()
+
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {connection.ClientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {"openid"},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	authURL := connection.AuthURL + "/auth/realms/" + connection.Realm + "/protocol/openid-connect/auth?" + query.Encode()
+	open(authURL)
+
+	var callback loginCallback
+	select {
+	case callback = <-callbacks:
+	case <-time.After(timeout):
+		err := fmt.Errorf("Login was not completed in the browser within %v", timeout)
+		return nil, &SecError{errOpLogin, err, err.Error()}
+	}
+	if callback.err != nil {
+		return nil, &SecError{errOpLogin, callback.err, callback.err.Error()}
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {connection.ClientID},
+		"code":          {callback.code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	}
+	return requestTokens(httpClient, connection, form)
+}
+
+// SecDeviceLogin : Log in to a connection on a machine without a browser, using the device authorization grant,
+// and save the tokens in the keyring. The user logs in by visiting the printed URL on any other device and
+// entering the printed code, while the CLI polls the token endpoint until the login completes
+func SecDeviceLogin(httpClient utils.HTTPClient, connectionID string) (*AuthToken, *SecError) {
+	connection, secErr := getLoginConnection(connectionID)
+	if secErr != nil {
+		return nil, secErr
+	}
+	authToken, secErr := deviceLogin(httpClient, connection, printDeviceCode, time.Sleep)
+	if secErr != nil {
+		return nil, secErr
+	}
+	return authToken, storeTokens(connection.ID, authToken)
+}
+
+func deviceLogin(httpClient utils.HTTPClient, connection *connections.Connection, prompt func(*deviceAuthorization), wait func(time.Duration)) (*AuthToken, *SecError) {
+	authorization, secErr := requestDeviceAuthorization(httpClient, connection)
+	if secErr != nil {
+		return nil, secErr
+	}
+	prompt(authorization)
+
+	// poll every 5 seconds for 10 minutes, unless the server says otherwise
+	interval, expiresIn := 5, 600
+	if authorization.Interval > 0 {
+		interval = authorization.Interval
+	}
+	if authorization.ExpiresIn > 0 {
+		expiresIn = authorization.ExpiresIn
+	}
+	form := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"client_id":   {connection.ClientID},
+		"device_code": {authorization.DeviceCode},
+	}
+	for waited := 0; waited < expiresIn; waited += interval {
+		wait(time.Duration(interval) * time.Second)
+		authToken, secErr := requestTokens(httpClient, connection, form)
+		switch {
+		case secErr == nil:
+			return authToken, nil
+		case secErr.Op == deviceAuthorizationPending:
+			continue
+		case secErr.Op == deviceSlowDown:
+			interval += 5
+			continue
+		}
+		return nil, secErr
+	}
+	err := fmt.Errorf("Login was not completed within %d seconds", expiresIn)
+	return nil, &SecError{errOpLogin, err, err.Error()}
+}
+
+// requestDeviceAuthorization : Start a device login, getting the code the user enters and the code the CLI polls with
+func requestDeviceAuthorization(httpClient utils.HTTPClient, connection *connections.Connection) (*deviceAuthorization, *SecError) {
+	form := url.Values{"client_id": {connection.ClientID}, "scope": {"openid"}}
+	authURL := connection.AuthURL + "/auth/realms/" + connection.Realm + "/protocol/openid-connect/auth/device"
+	req, err := http.NewRequest("POST", authURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, &SecError{errOpConnection, err, err.Error()}
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Accept", "application/json")
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, &SecError{errOpConnection, err, err.Error()}
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, &SecError{errOpResponse, err, err.Error()}
+	}
+	if res.StatusCode != http.StatusOK {
+		keycloakAPIError := parseKeycloakError(string(body), res.StatusCode)
+		err := fmt.Errorf("Unable to start a device login: %s %s", keycloakAPIError.Error, keycloakAPIError.ErrorDescription)
+		return nil, &SecError{errOpLogin, err, err.Error()}
+	}
+
+	authorization := deviceAuthorization{}
+	if err := json.Unmarshal(body, &authorization); err != nil || authorization.DeviceCode == "" {
+		if err == nil {
+			err = errors.New("no device code")
+		}
+		return nil, &SecError{errOpResponseFormat, err, textUnableToParse}
+	}
+	return &authorization, nil
+}
+
+// printDeviceCode : Tell the user where to log in and the code to enter
+func printDeviceCode(authorization *deviceAuthorization) {
+	fmt.Fprintf(os.Stderr, "To log in, visit %s on any device and enter the code: %s\n", authorization.VerificationURI, authorization.UserCode)
+	if authorization.VerificationURIComplete != "" {
+		fmt.Fprintf(os.Stderr, "Or visit this URL, which includes the code:\n\n%s\n\n", authorization.VerificationURIComplete)
+	}
+	fmt.Fprintln(os.Stderr, "Waiting for the login to complete...")
+}
+
+// callbackHandler : Handles the redirect back from the authentication server, passing on the first
+// authorization code or error it is sent
+func callbackHandler(state string, callbacks chan<- loginCallback) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != callbackPath {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		var callback loginCallback
+		switch {
+		case query.Get("state") != state:
+			callback.err = errors.New("Login response did not match the login request")
+		case query.Get("error") != "":
+			callback.err = fmt.Errorf("Login failed: %s %s", query.Get("error"), query.Get("error_description"))
+		case query.Get("code") == "":
+			callback.err = errors.New("Login response did not include an authorization code")
+		default:
+			callback.code = query.Get("code")
+		}
+
+		if callback.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "%s. Return to the command line for details.", callback.err.Error())
+		} else {
+			fmt.Fprint(w, "Login complete. You can close this window and return to the command line.")
+		}
+		select {
+		case callbacks <- callback:
+		default:
+		}
+	})
+}
+
+// randomString : A URL safe string of random bytes, used for PKCE verifiers and login states
+func randomString(size int) (string, error) {
+	bytes, err := randomBytes(size)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// openBrowser : Open a URL in the default browser, printing it in case the browser can't be opened
+func openBrowser(authURL string) {
+	fmt.Fprintf(os.Stderr, "Log in using your browser. If it doesn't open, visit:\n\n%s\n\n", authURL)
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", authURL)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", authURL)
+	default:
+		cmd = exec.Command("xdg-open", authURL)
+	}
+	// the URL has been printed, so there's nothing more to do if no browser can be found
+	if cmd.Start() == nil {
+		go cmd.Wait()
+	}
+}
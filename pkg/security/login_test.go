@@ -0,0 +1,197 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+// clientMockTokens : Responds to token requests with tokens, recording the form of the last request
+type clientMockTokens struct {
+	url  string
+	form url.Values
+}
+
+func (c *clientMockTokens) Do(req *http.Request) (*http.Response, error) {
+	c.url = req.URL.String()
+	body, _ := ioutil.ReadAll(req.Body)
+	c.form, _ = url.ParseQuery(string(body))
+	tokens := `{"access_token": "mockAccessToken", "refresh_token": "mockRefreshToken", "expires_in": 300}`
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(tokens))}, nil
+}
+
+func TestBrowserLogin(t *testing.T) {
+	connection := &connections.Connection{ID: "remote", AuthURL: "https://mockauth", Realm: "codewind", ClientID: "codewind-cli"}
+
+	tests := map[string]struct {
+		// redirect : the query the mock browser is redirected back to the CLI with, given the login request
+		redirect  func(login url.Values) url.Values
+		timeout   time.Duration
+		wantErr   string
+		wantToken string
+	}{
+		"success case - exchanges the code with the verifier": {
+			redirect: func(login url.Values) url.Values {
+				return url.Values{"code": {"mockCode"}, "state": {login.Get("state")}}
+			},
+			wantToken: "mockAccessToken",
+		},
+		"fail case - the state doesn't match": {
+			redirect: func(login url.Values) url.Values {
+				return url.Values{"code": {"mockCode"}, "state": {"otherState"}}
+			},
+			wantErr: "Login response did not match the login request",
+		},
+		"fail case - the user denied the login": {
+			redirect: func(login url.Values) url.Values {
+				return url.Values{"error": {"access_denied"}, "error_description": {"User denied"}, "state": {login.Get("state")}}
+			},
+			wantErr: "Login failed: access_denied User denied",
+		},
+		"fail case - the browser is never redirected": {
+			timeout: 100 * time.Millisecond,
+			wantErr: "Login was not completed in the browser within 100ms",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var login url.Values
+			browser := func(authURL string) {
+				parsed, _ := url.Parse(authURL)
+				login = parsed.Query()
+				if test.redirect != nil {
+					go http.Get(login.Get("redirect_uri") + "?" + test.redirect(login).Encode())
+				}
+			}
+
+			timeout := test.timeout
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			mockClient := &clientMockTokens{}
+			tokens, secErr := browserLogin(mockClient, connection, 0, timeout, browser)
+			assert.Equal(t, "codewind-cli", login.Get("client_id"))
+			assert.Equal(t, "S256", login.Get("code_challenge_method"))
+			assert.True(t, strings.HasPrefix(login.Get("redirect_uri"), "http://127.0.0.1:"))
+			if test.wantErr != "" {
+				assert.Equal(t, errOpLogin, secErr.Op)
+				assert.Equal(t, test.wantErr, secErr.Desc)
+				assert.Nil(t, mockClient.form)
+				return
+			}
+
+			assert.Nil(t, secErr)
+			assert.Equal(t, test.wantToken, tokens.AccessToken)
+			assert.Equal(t, "https://mockauth/auth/realms/codewind/protocol/openid-connect/token", mockClient.url)
+			assert.Equal(t, "authorization_code", mockClient.form.Get("grant_type"))
+			assert.Equal(t, "mockCode", mockClient.form.Get("code"))
+			assert.Equal(t, login.Get("redirect_uri"), mockClient.form.Get("redirect_uri"))
+			challenge := sha256.Sum256([]byte(mockClient.form.Get("code_verifier")))
+			assert.Equal(t, login.Get("code_challenge"), base64.RawURLEncoding.EncodeToString(challenge[:]))
+		})
+	}
+}
+
+// clientMockDevice : Starts a device login, then answers each poll of the token endpoint with the next response
+type clientMockDevice struct {
+	polls []*http.Response
+	forms []url.Values
+}
+
+func (c *clientMockDevice) Do(req *http.Request) (*http.Response, error) {
+	body, _ := ioutil.ReadAll(req.Body)
+	form, _ := url.ParseQuery(string(body))
+	if strings.HasSuffix(req.URL.Path, "/auth/device") {
+		authorization := `{"device_code": "mockDeviceCode", "user_code": "ABCD-EFGH", "verification_uri": "https://mockauth/device", "expires_in": 30, "interval": 5}`
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(authorization))}, nil
+	}
+	c.forms = append(c.forms, form)
+	response := c.polls[0]
+	c.polls = c.polls[1:]
+	return response, nil
+}
+
+func mockTokenResponse(statusCode int, body string) *http.Response {
+	return &http.Response{StatusCode: statusCode, Body: ioutil.NopCloser(strings.NewReader(body))}
+}
+
+func TestDeviceLogin(t *testing.T) {
+	connection := &connections.Connection{ID: "remote", AuthURL: "https://mockauth", Realm: "codewind", ClientID: "codewind-cli"}
+	pending := `{"error": "authorization_pending", "error_description": "The authorization request is still pending"}`
+
+	tests := map[string]struct {
+		polls     []*http.Response
+		wantWaits []time.Duration
+		wantErr   string
+	}{
+		"success case - polls until the user logs in, slowing down when asked": {
+			polls: []*http.Response{
+				mockTokenResponse(http.StatusBadRequest, pending),
+				mockTokenResponse(http.StatusBadRequest, `{"error": "slow_down"}`),
+				mockTokenResponse(http.StatusOK, `{"access_token": "mockAccessToken", "refresh_token": "mockRefreshToken"}`),
+			},
+			wantWaits: []time.Duration{5 * time.Second, 5 * time.Second, 10 * time.Second},
+		},
+		"fail case - the user denies the login": {
+			polls: []*http.Response{
+				mockTokenResponse(http.StatusBadRequest, `{"error": "access_denied", "error_description": "The end user denied the authorization request"}`),
+			},
+			wantWaits: []time.Duration{5 * time.Second},
+			wantErr:   "The end user denied the authorization request",
+		},
+		"fail case - the code expires": {
+			polls: []*http.Response{
+				mockTokenResponse(http.StatusBadRequest, pending),
+				mockTokenResponse(http.StatusBadRequest, pending),
+				mockTokenResponse(http.StatusBadRequest, pending),
+				mockTokenResponse(http.StatusBadRequest, pending),
+				mockTokenResponse(http.StatusBadRequest, pending),
+				mockTokenResponse(http.StatusBadRequest, pending),
+			},
+			wantWaits: []time.Duration{5 * time.Second, 5 * time.Second, 5 * time.Second, 5 * time.Second, 5 * time.Second, 5 * time.Second},
+			wantErr:   "Login was not completed within 30 seconds",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var prompted *deviceAuthorization
+			waits := []time.Duration{}
+			mockClient := &clientMockDevice{polls: test.polls}
+			tokens, secErr := deviceLogin(mockClient, connection,
+				func(authorization *deviceAuthorization) { prompted = authorization },
+				func(d time.Duration) { waits = append(waits, d) })
+
+			assert.Equal(t, "ABCD-EFGH", prompted.UserCode)
+			assert.Equal(t, test.wantWaits, waits)
+			for _, form := range mockClient.forms {
+				assert.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", form.Get("grant_type"))
+				assert.Equal(t, "mockDeviceCode", form.Get("device_code"))
+			}
+			if test.wantErr != "" {
+				assert.Equal(t, test.wantErr, secErr.Desc)
+				return
+			}
+			assert.Nil(t, secErr)
+			assert.Equal(t, "mockAccessToken", tokens.AccessToken)
+		})
+	}
+}
@@ -52,6 +52,8 @@ const (
 	errOpConConfig             = "sec_con_config"               // Connection configuration errors
 	errOpCLICommand            = "sec_cli_options"              // Invalid command line options
 	errOpPasswordRead          = "sec_password_read"            // Unable to fetch password
+	errOpLogin                 = "sec_login"                    // Browser or device login failed
+	errOpTokenExpired          = "sec_token_expired"            // Refresh token has expired
 )
 
 const (
@@ -63,6 +65,7 @@ const (
 	textNotFoundSuffix  = "not found in keyring"
 	textSecretNotFound  = "Secret %s " + textNotFoundSuffix
 	textKeyringNotFound = "Keyring not found"
+	textKeyringLocked   = "Keyring is locked by another process, remove %s if no other process is using it"
 )
 
 // SecError : Error formatted in JSON containing an errorOp and a description from
@@ -0,0 +1,106 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// serviceAccountToken : An access token issued to a service account, and when it expires
+type serviceAccountToken struct {
+	accessToken string
+	expiry      int64
+}
+
+var (
+	// serviceAccountTokens : The access tokens of service accounts, by connection. They are only kept for the
+	// life of the CLI process, as pipelines often have no keyring, and a new token is cheap to get
+	serviceAccountTokens      = map[string]serviceAccountToken{}
+	serviceAccountTokensMutex sync.Mutex
+)
+
+// GetServiceAccountToken : Get an access token for a connection's service account, requesting a new one with the
+// client credentials grant if there isn't one, if it has expired or is about to, or if it is the rejected token
+func GetServiceAccountToken(httpClient utils.HTTPClient, connection *connections.Connection, rejectedAccessToken string) (string, *SecError) {
+	conID := strings.TrimSpace(strings.ToLower(connection.ID))
+	serviceAccountTokensMutex.Lock()
+	defer serviceAccountTokensMutex.Unlock()
+
+	token, ok := serviceAccountTokens[conID]
+	fresh := token.expiry == 0 || timeNow().Add(tokenExpirySkew).Before(time.Unix(token.expiry, 0))
+	if ok && fresh && token.accessToken != rejectedAccessToken {
+		return token.accessToken, nil
+	}
+
+	authToken, secErr := SecClientCredentials(httpClient, connection)
+	if secErr != nil {
+		return "", secErr
+	}
+	serviceAccountTokens[conID] = serviceAccountToken{authToken.AccessToken, tokenExpiry(authToken.AccessToken, authToken.ExpiresIn)}
+	return authToken.AccessToken, nil
+}
+
+// SecClientCredentials : Authenticate as a connection's service account using the client credentials grant
+func SecClientCredentials(httpClient utils.HTTPClient, connection *connections.Connection) (*AuthToken, *SecError) {
+	account := connection.ServiceAccount
+	if account == nil {
+		err := fmt.Errorf("Connection %s has no service account", connection.ID)
+		return nil, &SecError{errOpConConfig, err, err.Error()}
+	}
+	secret, secErr := readServiceAccountSecret(account)
+	if secErr != nil {
+		return nil, secErr
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {account.ClientID},
+		"client_secret": {secret},
+	}
+	return requestTokens(httpClient, connection, form)
+}
+
+// readServiceAccountSecret : Read a service account's secret from the environment variable or file it is kept in
+func readServiceAccountSecret(account *connections.ServiceAccount) (string, *SecError) {
+	if account.SecretEnv != "" {
+		secret := strings.TrimSpace(os.Getenv(account.SecretEnv))
+		if secret == "" {
+			err := fmt.Errorf("Service account secret environment variable %s is not set", account.SecretEnv)
+			return "", &SecError{errOpConConfig, err, err.Error()}
+		}
+		return secret, nil
+	}
+	if account.SecretFile != "" {
+		content, err := ioutil.ReadFile(account.SecretFile)
+		if err != nil {
+			err := fmt.Errorf("Unable to read service account secret file: %v", err)
+			return "", &SecError{errOpConConfig, err, err.Error()}
+		}
+		secret := strings.TrimSpace(string(content))
+		if secret == "" {
+			err := fmt.Errorf("Service account secret file %s is empty", account.SecretFile)
+			return "", &SecError{errOpConConfig, err, err.Error()}
+		}
+		return secret, nil
+	}
+	err := errors.New("Service account has no secret environment variable or file")
+	return "", &SecError{errOpConConfig, err, err.Error()}
+}
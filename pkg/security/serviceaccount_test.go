@@ -0,0 +1,99 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecClientCredentials(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "serviceaccount")
+	defer os.RemoveAll(dir)
+	secretFile := filepath.Join(dir, "secret")
+	ioutil.WriteFile(secretFile, []byte("fileSecret\n"), 0600)
+	os.Setenv("CW_TEST_CLIENT_SECRET", "envSecret")
+	defer os.Unsetenv("CW_TEST_CLIENT_SECRET")
+
+	tests := map[string]struct {
+		account    *connections.ServiceAccount
+		wantSecret string
+		wantErr    string
+	}{
+		"success case - secret from an environment variable": {
+			account:    &connections.ServiceAccount{ClientID: "pipeline", SecretEnv: "CW_TEST_CLIENT_SECRET"},
+			wantSecret: "envSecret",
+		},
+		"success case - secret from a file": {
+			account:    &connections.ServiceAccount{ClientID: "pipeline", SecretFile: secretFile},
+			wantSecret: "fileSecret",
+		},
+		"fail case - environment variable not set": {
+			account: &connections.ServiceAccount{ClientID: "pipeline", SecretEnv: "CW_TEST_UNSET_SECRET"},
+			wantErr: "Service account secret environment variable CW_TEST_UNSET_SECRET is not set",
+		},
+		"fail case - no service account": {
+			wantErr: "Connection remote has no service account",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			connection := &connections.Connection{ID: "remote", AuthURL: "https://mockauth", Realm: "codewind", ServiceAccount: test.account}
+			mockClient := &clientMockTokens{}
+			tokens, secErr := SecClientCredentials(mockClient, connection)
+			if test.wantErr != "" {
+				assert.Equal(t, errOpConConfig, secErr.Op)
+				assert.Equal(t, test.wantErr, secErr.Desc)
+				assert.Nil(t, mockClient.form)
+				return
+			}
+			assert.Nil(t, secErr)
+			assert.Equal(t, "mockAccessToken", tokens.AccessToken)
+			assert.Equal(t, "client_credentials", mockClient.form.Get("grant_type"))
+			assert.Equal(t, "pipeline", mockClient.form.Get("client_id"))
+			assert.Equal(t, test.wantSecret, mockClient.form.Get("client_secret"))
+		})
+	}
+}
+
+func TestGetServiceAccountToken(t *testing.T) {
+	os.Setenv("CW_TEST_CLIENT_SECRET", "envSecret")
+	defer os.Unsetenv("CW_TEST_CLIENT_SECRET")
+	connection := &connections.Connection{
+		ID:             "pipeline",
+		AuthURL:        "https://mockauth",
+		Realm:          "codewind",
+		ServiceAccount: &connections.ServiceAccount{ClientID: "pipeline", SecretEnv: "CW_TEST_CLIENT_SECRET"},
+	}
+	defer delete(serviceAccountTokens, "pipeline")
+
+	mockClient := &clientMockTokens{}
+	token, secErr := GetServiceAccountToken(mockClient, connection, "")
+	assert.Nil(t, secErr)
+	assert.Equal(t, "mockAccessToken", token)
+
+	// the token is reused until it is rejected
+	mockClient.form = nil
+	token, secErr = GetServiceAccountToken(mockClient, connection, "")
+	assert.Nil(t, secErr)
+	assert.Equal(t, "mockAccessToken", token)
+	assert.Nil(t, mockClient.form)
+
+	_, secErr = GetServiceAccountToken(mockClient, connection, "mockAccessToken")
+	assert.Nil(t, secErr)
+	assert.Equal(t, "client_credentials", mockClient.form.Get("grant_type"))
+}
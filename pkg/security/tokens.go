@@ -0,0 +1,176 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
+)
+
+// The keyring entries tokens and their expiry times are saved in. Expiry times are in seconds since the
+// epoch, or 0 if the server didn't say when the token expires
+const (
+	accessTokenKey          = "access_token"
+	refreshTokenKey         = "refresh_token"
+	accessTokenExpiryKey    = "access_token_expiry"
+	refreshTokenExpiryKey   = "refresh_token_expiry"
+	tokenExpirySkew         = 30 * time.Second // refresh tokens this long before they expire
+	refreshLockTimeout      = 10 * time.Second // how long to wait for another refresh of the same tokens
+	refreshLockStaleAfter   = 30 * time.Second // a lock this old was left by a refresh that didn't finish
+	refreshLockPollInterval = 100 * time.Millisecond
+)
+
+var (
+	// timeNow : The current time, replaced in tests
+	timeNow = time.Now
+
+	// refreshLockDir : The directory of the files that serialise refreshes between CLI processes
+	refreshLockDir = connections.GetConnectionConfigDir()
+
+	// refreshMutex : Serialises refreshes within a CLI process
+	refreshMutex sync.Mutex
+)
+
+// GetAccessToken : Get a connection's access token from the keyring, refreshing it first if it has expired,
+// or is about to, or if there isn't one
+func GetAccessToken(httpClient utils.HTTPClient, connection *connections.Connection) (string, *SecError) {
+	conID := strings.TrimSpace(strings.ToLower(connection.ID))
+	accessToken, _ := GetSecretFromKeyring(conID, accessTokenKey)
+	if accessToken != "" && !tokenExpired(conID, accessTokenExpiryKey) {
+		return accessToken, nil
+	}
+	logr.Traceln("Access token missing or about to expire, refreshing it")
+	return RefreshAccessToken(httpClient, connection, accessToken)
+}
+
+// RefreshAccessToken : Refresh a connection's access token, which has expired or been rejected, using the
+// refresh token in the keyring. Only one refresh of a connection's tokens runs at a time, across CLI processes,
+// and if another refresh replaced the stale access token while this one waited, its token is used
+func RefreshAccessToken(httpClient utils.HTTPClient, connection *connections.Connection, staleAccessToken string) (string, *SecError) {
+	conID := strings.TrimSpace(strings.ToLower(connection.ID))
+	unlock := lockTokenRefresh(conID)
+	defer unlock()
+
+	accessToken, _ := GetSecretFromKeyring(conID, accessTokenKey)
+	if accessToken != "" && accessToken != staleAccessToken && !tokenExpired(conID, accessTokenExpiryKey) {
+		logr.Traceln("Access token was refreshed by another request")
+		return accessToken, nil
+	}
+
+	refreshToken, secErr := GetSecretFromKeyring(conID, refreshTokenKey)
+	if secErr != nil {
+		return "", secErr
+	}
+	if tokenExpired(conID, refreshTokenExpiryKey) {
+		err := errors.New("Refresh token expired")
+		return "", &SecError{errOpTokenExpired, err, err.Error()}
+	}
+	authToken, secErr := SecRefreshAccessToken(httpClient, connection, refreshToken)
+	if authToken == nil {
+		return "", secErr
+	}
+	if secErr != nil {
+		// the new token can still be used, it will be refreshed again next time
+		logr.Tracef("Unable to save refreshed tokens: %v", secErr.Desc)
+	}
+	return authToken.AccessToken, nil
+}
+
+// tokenExpired : Whether a token expires within the skew window, according to its expiry time in the keyring.
+// Tokens that don't have an expiry time, such as those saved by older CLIs, are assumed not to have expired
+func tokenExpired(connectionID string, expiryKey string) bool {
+	expiry, secErr := GetSecretFromKeyring(connectionID, expiryKey)
+	if secErr != nil {
+		return false
+	}
+	seconds, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || seconds == 0 {
+		return false
+	}
+	return !timeNow().Add(tokenExpirySkew).Before(time.Unix(seconds, 0))
+}
+
+// tokenExpiry : When a token expires, in seconds since the epoch, from its exp claim if it is a JWT, or from the
+// lifetime the server gave with it, or 0 if neither is known
+func tokenExpiry(token string, expiresIn int) int64 {
+	parts := strings.Split(token, ".")
+	if len(parts) == 3 {
+		payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+		claims := struct {
+			Exp int64 `json:"exp"`
+		}{}
+		if err == nil && json.Unmarshal(payload, &claims) == nil && claims.Exp > 0 {
+			return claims.Exp
+		}
+	}
+	if expiresIn > 0 {
+		return timeNow().Unix() + int64(expiresIn)
+	}
+	return 0
+}
+
+// storeTokenExpiry : Save when a connection's tokens expire in the keyring
+func storeTokenExpiry(connectionID string, authToken *AuthToken) *SecError {
+	accessExpiry := tokenExpiry(authToken.AccessToken, authToken.ExpiresIn)
+	secErr := SecKeyUpdate(connectionID, accessTokenExpiryKey, strconv.FormatInt(accessExpiry, 10))
+	if secErr != nil || authToken.RefreshToken == "" {
+		return secErr
+	}
+	// Keycloak's offline tokens have no exp claim and a refresh_expires_in of 0, as they don't expire
+	refreshExpiry := tokenExpiry(authToken.RefreshToken, authToken.RefreshExpiresIn)
+	return SecKeyUpdate(connectionID, refreshTokenExpiryKey, strconv.FormatInt(refreshExpiry, 10))
+}
+
+// lockTokenRefresh : Wait for any other refresh of a connection's tokens to finish, then lock them until the
+// returned function is called. The lock between processes is a file that exists while a refresh runs. If it
+// can't be taken, the refresh goes ahead anyway, as a wasted refresh is better than a failed request
+func lockTokenRefresh(connectionID string) func() {
+	refreshMutex.Lock()
+	lockFile := filepath.Join(refreshLockDir, connectionID+".refresh.lock")
+	os.MkdirAll(refreshLockDir, 0700)
+	deadline := time.Now().Add(refreshLockTimeout)
+	for {
+		file, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+			return func() {
+				os.Remove(lockFile)
+				refreshMutex.Unlock()
+			}
+		}
+		info, statErr := os.Stat(lockFile)
+		if !os.IsExist(err) || statErr != nil && !os.IsNotExist(statErr) {
+			logr.Tracef("Unable to lock token refresh: %v", err)
+			return refreshMutex.Unlock
+		}
+		if statErr == nil && time.Since(info.ModTime()) > refreshLockStaleAfter {
+			os.Remove(lockFile)
+			continue
+		}
+		if time.Now().After(deadline) {
+			logr.Traceln("Timed out waiting for another token refresh")
+			return refreshMutex.Unlock
+		}
+		time.Sleep(refreshLockPollInterval)
+	}
+}
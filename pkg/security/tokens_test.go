@@ -0,0 +1,102 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenExpiry(t *testing.T) {
+	originalTimeNow := timeNow
+	defer func() { timeNow = originalTimeNow }()
+	timeNow = func() time.Time { return time.Unix(1000, 0) }
+
+	jwt := func(payload string) string {
+		return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+	}
+	tests := map[string]struct {
+		token     string
+		expiresIn int
+		want      int64
+	}{
+		"success case - a JWT's exp claim is used": {
+			token:     jwt(`{"exp": 1300, "sub": "user"}`),
+			expiresIn: 60,
+			want:      1300,
+		},
+		"success case - the lifetime is used for a JWT without an exp claim": {
+			token:     jwt(`{"sub": "user"}`),
+			expiresIn: 60,
+			want:      1060,
+		},
+		"success case - the lifetime is used for an opaque token": {
+			token:     "opaque.token",
+			expiresIn: 300,
+			want:      1300,
+		},
+		"success case - an offline token never expires": {
+			token: jwt(`{"typ": "Offline"}`),
+			want:  0,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, tokenExpiry(test.token, test.expiresIn))
+		})
+	}
+}
+
+func TestLockTokenRefresh(t *testing.T) {
+	originalLockDir := refreshLockDir
+	defer func() { refreshLockDir = originalLockDir }()
+	refreshLockDir, _ = ioutil.TempDir("", "locks")
+	defer os.RemoveAll(refreshLockDir)
+	lockFile := filepath.Join(refreshLockDir, "remote.refresh.lock")
+
+	t.Run("success case - a refresh waits for the one before it", func(t *testing.T) {
+		unlock := lockTokenRefresh("remote")
+		_, err := os.Stat(lockFile)
+		assert.Nil(t, err)
+
+		locked := make(chan bool)
+		go func() {
+			unlockSecond := lockTokenRefresh("remote")
+			locked <- true
+			unlockSecond()
+		}()
+		select {
+		case <-locked:
+			t.Fatal("second refresh did not wait")
+		case <-time.After(50 * time.Millisecond):
+		}
+		unlock()
+		<-locked
+	})
+
+	t.Run("success case - a lock left by a refresh that didn't finish is taken over", func(t *testing.T) {
+		ioutil.WriteFile(lockFile, nil, 0600)
+		stale := time.Now().Add(-time.Minute)
+		os.Chtimes(lockFile, stale, stale)
+
+		unlock := lockTokenRefresh("remote")
+		unlock()
+		_, err := os.Stat(lockFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
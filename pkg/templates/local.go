@@ -0,0 +1,341 @@
+package templates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// localTemplateRepos is the file recording the template repos on disk,
+	// which are read by the CLI rather than by PFE
+	localTemplateRepos struct {
+		Repos []localTemplateRepo `json:"repos"`
+	}
+
+	// localTemplateRepo is a template repo on disk, which is only used by the connection it was added to
+	localTemplateRepo struct {
+		utils.TemplateRepo
+		ConnectionID string `json:"conid"`
+	}
+
+	// templateIndexEntry is a template listed in the index of a local template repo.
+	// Both the fields of a PFE template repo index and of the templates API are accepted
+	templateIndexEntry struct {
+		DisplayName  string `json:"displayName"`
+		Label        string `json:"label"`
+		Description  string `json:"description"`
+		Language     string `json:"language"`
+		ProjectType  string `json:"projectType"`
+		ProjectStyle string `json:"projectStyle"`
+		Location     string `json:"location"`
+		URL          string `json:"url"`
+	}
+)
+
+// templateIndexFilenames are the files looked for in a local template repo directory, in order
+var templateIndexFilenames = []string{"templates.json", "index.json"}
+
+// defaultProjectStyle is the style PFE gives templates that don't have one
+const defaultProjectStyle = "Codewind"
+
+// GetTemplates gets project templates from PFE and from the local template repos.
+// Filter them using the function arguments
+func GetTemplates(conID, projectStyle string, showEnabledOnly bool) ([]apiroutes.Template, error) {
+	templates, err := apiroutes.GetTemplates(conID, projectStyle, showEnabledOnly)
+	if err != nil {
+		return nil, err
+	}
+	localTemplates, err := getLocalTemplates(conID, projectStyle, showEnabledOnly)
+	if err != nil {
+		return nil, err
+	}
+	return append(templates, localTemplates...), nil
+}
+
+// GetTemplateRepos gets the template repos of which PFE is aware, and the local template repos
+func GetTemplateRepos(conID string) ([]utils.TemplateRepo, error) {
+	repos, err := apiroutes.GetTemplateRepos(conID)
+	if err != nil {
+		return nil, err
+	}
+	localRepos, err := loadLocalTemplateRepos(conID)
+	if err != nil {
+		return nil, err
+	}
+	return append(repos, localRepos...), nil
+}
+
+// getReposAfterLocalChange gets all template repos after a local repo was added or removed,
+// returning only the local repos if PFE can't be reached
+func getReposAfterLocalChange(conID string) ([]utils.TemplateRepo, error) {
+	repos, err := GetTemplateRepos(conID)
+	if err != nil {
+		return loadLocalTemplateRepos(conID)
+	}
+	return repos, nil
+}
+
+// addLocalTemplateRepo records a template repo on disk for a connection, after checking its index can be read
+func addLocalTemplateRepo(conID, URL, description, name string) error {
+	URL = normaliseLocalURL(URL)
+	repoTemplates, err := readLocalTemplateIndex(URL)
+	if err != nil {
+		return err
+	}
+
+	repos, err := loadLocalTemplateRepoFile()
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if repo.ConnectionID == conID && repo.URL == URL {
+			return fmt.Errorf("Error: template repo '%s' has already been added", URL)
+		}
+	}
+
+	localPath, _ := utils.LocalPathFromURL(URL)
+	if name == "" {
+		name = filepath.Base(localPath)
+	}
+	var styles []string
+	for _, template := range repoTemplates {
+		styles = append(styles, template.ProjectStyle)
+	}
+	styles = utils.RemoveDuplicateEntries(styles)
+	sort.Strings(styles)
+	repos = append(repos, localTemplateRepo{
+		TemplateRepo: utils.TemplateRepo{
+			Description:   description,
+			URL:           URL,
+			Name:          name,
+			ID:            localTemplateRepoID(URL),
+			Enabled:       true,
+			ProjectStyles: styles,
+		},
+		ConnectionID: conID,
+	})
+	return saveLocalTemplateRepoFile(repos)
+}
+
+// removeLocalTemplateRepo removes a connection's template repo on disk, returning false if it hadn't been added
+func removeLocalTemplateRepo(conID, URL string) (bool, error) {
+	URL = normaliseLocalURL(URL)
+	repos, err := loadLocalTemplateRepoFile()
+	if err != nil {
+		return false, err
+	}
+	for i, repo := range repos {
+		if repo.ConnectionID == conID && repo.URL == URL {
+			return true, saveLocalTemplateRepoFile(append(repos[:i], repos[i+1:]...))
+		}
+	}
+	return false, nil
+}
+
+// setLocalTemplateReposEnabled enables or disables those of the given repos that are a connection's template
+// repos on disk, returning the URLs of the others, which PFE must be asked to change
+func setLocalTemplateReposEnabled(conID string, URLs []string, enabled bool) ([]string, error) {
+	repos, err := loadLocalTemplateRepoFile()
+	if err != nil {
+		return nil, err
+	}
+	var pfeURLs []string
+	changed := false
+	for _, URL := range URLs {
+		isLocal := false
+		if _, ok := utils.LocalPathFromURL(URL); ok {
+			normalisedURL := normaliseLocalURL(URL)
+			for i := range repos {
+				if repos[i].ConnectionID == conID && repos[i].URL == normalisedURL {
+					repos[i].Enabled = enabled
+					isLocal = true
+					changed = true
+				}
+			}
+		}
+		if !isLocal {
+			pfeURLs = append(pfeURLs, URL)
+		}
+	}
+	if changed {
+		if err := saveLocalTemplateRepoFile(repos); err != nil {
+			return nil, err
+		}
+	}
+	return pfeURLs, nil
+}
+
+// getLocalTemplates gets the templates in a connection's local template repos, filtered by project style
+// and, if showEnabledOnly is set, leaving out the templates of disabled repos
+func getLocalTemplates(conID, projectStyle string, showEnabledOnly bool) ([]apiroutes.Template, error) {
+	repos, err := loadLocalTemplateRepos(conID)
+	if err != nil {
+		return nil, err
+	}
+	var localTemplates []apiroutes.Template
+	for _, repo := range repos {
+		if showEnabledOnly && !repo.Enabled {
+			continue
+		}
+		repoTemplates, err := readLocalTemplateIndex(repo.URL)
+		if err != nil {
+			// a repo on a share that isn't mounted shouldn't hide the other templates
+			continue
+		}
+		for _, template := range repoTemplates {
+			if projectStyle != "" && template.ProjectStyle != projectStyle {
+				continue
+			}
+			template.Source = repo.Name
+			template.SourceID = repo.ID
+			localTemplates = append(localTemplates, template)
+		}
+	}
+	return localTemplates, nil
+}
+
+// readLocalTemplateIndex reads the templates listed in a local template repo. The URL may be
+// a file:// URL or path of the index file, or of a directory containing templates.json or index.json.
+// Template locations relative to the index are resolved to absolute paths
+func readLocalTemplateIndex(URL string) ([]apiroutes.Template, error) {
+	indexPath, ok := utils.LocalPathFromURL(URL)
+	if !ok {
+		return nil, fmt.Errorf("Error: '%s' is not a local file or directory", URL)
+	}
+	info, err := os.Stat(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		dir := indexPath
+		indexPath = ""
+		for _, filename := range templateIndexFilenames {
+			if utils.PathExists(filepath.Join(dir, filename)) {
+				indexPath = filepath.Join(dir, filename)
+				break
+			}
+		}
+		if indexPath == "" {
+			return nil, fmt.Errorf("Error: no %s found in '%s'", strings.Join(templateIndexFilenames, " or "), dir)
+		}
+	}
+
+	file, err := ioutil.ReadFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	var entries []templateIndexEntry
+	err = json.Unmarshal(file, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("Error: unable to parse template index '%s': %v", indexPath, err)
+	}
+
+	templates := []apiroutes.Template{}
+	for _, entry := range entries {
+		template := apiroutes.Template{
+			Label:        entry.DisplayName,
+			Description:  entry.Description,
+			Language:     entry.Language,
+			URL:          entry.Location,
+			ProjectType:  entry.ProjectType,
+			ProjectStyle: entry.ProjectStyle,
+		}
+		if template.Label == "" {
+			template.Label = entry.Label
+		}
+		if template.URL == "" {
+			template.URL = entry.URL
+		}
+		if template.ProjectStyle == "" {
+			template.ProjectStyle = defaultProjectStyle
+		}
+		if !strings.Contains(template.URL, "://") && !filepath.IsAbs(template.URL) {
+			template.URL = filepath.Join(filepath.Dir(indexPath), template.URL)
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// normaliseLocalURL makes plain paths absolute, so that local repos can be found from any directory
+func normaliseLocalURL(URL string) string {
+	if strings.Contains(URL, "://") {
+		return URL
+	}
+	absPath, err := filepath.Abs(URL)
+	if err != nil {
+		return URL
+	}
+	return absPath
+}
+
+// localTemplateRepoID gives a local repo an ID that can't clash with the IDs PFE gives repos
+func localTemplateRepoID(URL string) string {
+	hash := sha256.Sum256([]byte(URL))
+	return "local-" + hex.EncodeToString(hash[:8])
+}
+
+// loadLocalTemplateRepos gets the template repos on disk that were added to a connection
+func loadLocalTemplateRepos(conID string) ([]utils.TemplateRepo, error) {
+	repos, err := loadLocalTemplateRepoFile()
+	if err != nil {
+		return nil, err
+	}
+	connectionRepos := []utils.TemplateRepo{}
+	for _, repo := range repos {
+		if repo.ConnectionID == conID {
+			connectionRepos = append(connectionRepos, repo.TemplateRepo)
+		}
+	}
+	return connectionRepos, nil
+}
+
+func loadLocalTemplateRepoFile() ([]localTemplateRepo, error) {
+	file, err := ioutil.ReadFile(getLocalTemplateReposFilename())
+	if os.IsNotExist(err) {
+		return []localTemplateRepo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var localRepos localTemplateRepos
+	err = json.Unmarshal(file, &localRepos)
+	if err != nil {
+		return nil, err
+	}
+	for i := range localRepos.Repos {
+		// repos recorded before they were scoped to a connection belong to the local one
+		if localRepos.Repos[i].ConnectionID == "" {
+			localRepos.Repos[i].ConnectionID = "local"
+		}
+	}
+	return localRepos.Repos, nil
+}
+
+func saveLocalTemplateRepoFile(repos []localTemplateRepo) error {
+	err := os.MkdirAll(connections.GetConnectionConfigDir(), 0755)
+	if err != nil {
+		return err
+	}
+	body, err := json.MarshalIndent(localTemplateRepos{repos}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(getLocalTemplateReposFilename(), body, 0644)
+}
+
+func getLocalTemplateReposFilename() string {
+	return path.Join(connections.GetConnectionConfigDir(), "template-repos.json")
+}
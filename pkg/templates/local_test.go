@@ -0,0 +1,96 @@
+package templates
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalTemplateRepos(t *testing.T) {
+	testFolder, _ := filepath.Abs("local_repos_test_folder_delete_me")
+	os.MkdirAll(filepath.Join(testFolder, "repo", "nodeTemplate"), 0777)
+	defer os.RemoveAll(testFolder)
+
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", testFolder)
+	defer os.Setenv("HOME", originalHome)
+
+	repoDir := filepath.Join(testFolder, "repo")
+	ioutil.WriteFile(filepath.Join(repoDir, "templates.json"), []byte(`[
+		{"displayName": "Node.js", "language": "nodejs", "projectType": "nodejs", "location": "nodeTemplate"},
+		{"label": "Java", "language": "java", "projectType": "docker", "projectStyle": "Other", "url": "https://github.com/org/javaTemplate"}
+	]`), 0644)
+
+	t.Run("success case - templates are read from the index in a directory", func(t *testing.T) {
+		repoTemplates, err := readLocalTemplateIndex("file://" + filepath.ToSlash(repoDir))
+		assert.Nil(t, err)
+		assert.Len(t, repoTemplates, 2)
+		assert.Equal(t, "Node.js", repoTemplates[0].Label)
+		assert.Equal(t, filepath.Join(repoDir, "nodeTemplate"), repoTemplates[0].URL)
+		assert.Equal(t, defaultProjectStyle, repoTemplates[0].ProjectStyle)
+		assert.Equal(t, "Java", repoTemplates[1].Label)
+		assert.Equal(t, "https://github.com/org/javaTemplate", repoTemplates[1].URL)
+	})
+
+	t.Run("fail case - directory without an index", func(t *testing.T) {
+		_, err := readLocalTemplateIndex(filepath.Join(repoDir, "nodeTemplate"))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("success case - a local repo can be added, listed and removed", func(t *testing.T) {
+		err := addLocalTemplateRepo("local", repoDir, "team templates", "")
+		assert.Nil(t, err)
+
+		err = addLocalTemplateRepo("local", repoDir, "team templates", "")
+		assert.NotNil(t, err, "a repo can't be added twice")
+
+		repos, _ := loadLocalTemplateRepos("local")
+		assert.Len(t, repos, 1)
+		assert.Equal(t, "repo", repos[0].Name)
+		assert.Equal(t, []string{defaultProjectStyle, "Other"}, repos[0].ProjectStyles)
+
+		localTemplates, _ := getLocalTemplates("local", "Other", true)
+		assert.Len(t, localTemplates, 1)
+		assert.Equal(t, repos[0].ID, localTemplates[0].SourceID)
+
+		removed, err := removeLocalTemplateRepo("local", repoDir)
+		assert.True(t, removed)
+		assert.Nil(t, err)
+		repos, _ = loadLocalTemplateRepos("local")
+		assert.Empty(t, repos)
+	})
+
+	t.Run("success case - a local repo is only used by the connection it was added to", func(t *testing.T) {
+		addLocalTemplateRepo("remote", repoDir, "team templates", "")
+		defer removeLocalTemplateRepo("remote", repoDir)
+
+		repos, _ := loadLocalTemplateRepos("local")
+		assert.Empty(t, repos)
+		localTemplates, _ := getLocalTemplates("local", "", false)
+		assert.Empty(t, localTemplates)
+		removed, _ := removeLocalTemplateRepo("local", repoDir)
+		assert.False(t, removed)
+
+		repos, _ = loadLocalTemplateRepos("remote")
+		assert.Len(t, repos, 1)
+	})
+
+	t.Run("success case - the templates of a disabled local repo are only listed when asked for", func(t *testing.T) {
+		addLocalTemplateRepo("local", repoDir, "team templates", "")
+		defer removeLocalTemplateRepo("local", repoDir)
+
+		pfeURLs, err := setLocalTemplateReposEnabled("local", []string{repoDir, "https://github.com/org/repo"}, false)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"https://github.com/org/repo"}, pfeURLs)
+
+		repos, _ := loadLocalTemplateRepos("local")
+		assert.False(t, repos[0].Enabled)
+		enabledTemplates, _ := getLocalTemplates("local", "", true)
+		assert.Empty(t, enabledTemplates)
+		allTemplates, _ := getLocalTemplates("local", "", false)
+		assert.Len(t, allTemplates, 2)
+	})
+}
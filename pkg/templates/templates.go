@@ -6,13 +6,50 @@ import (
 	"net/url"
 
 	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/security"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 )
 
+// PulledTemplate is the result of downloading a template to the local cache
+type PulledTemplate struct {
+	URL   string `json:"url"`
+	Label string `json:"label"`
+	Error string `json:"error,omitempty"`
+}
+
+// PullTemplates downloads every template from the enabled and local template repos
+// to the local cache, so that projects can be created from them offline
+func PullTemplates(conID string) ([]PulledTemplate, error) {
+	templates, err := GetTemplates(conID, "", true)
+	if err != nil {
+		return nil, err
+	}
+	pulled := []PulledTemplate{}
+	for _, template := range templates {
+		result := PulledTemplate{URL: template.URL, Label: template.Label}
+		gitCredentials, err := getGitCredentialsForSource(conID, template.SourceID)
+		if err != nil {
+			result.Error = err.Error()
+		} else if err := utils.CacheTemplate(utils.TemplateCacheDir(connections.GetConnectionConfigDir()), template.URL, gitCredentials); err != nil {
+			result.Error = err.Error()
+		}
+		pulled = append(pulled, result)
+	}
+	return pulled, nil
+}
+
 // AddTemplateRepo adds the provided template repo to PFE and
 // stores provided gitCredentials to the keyring
 func AddTemplateRepo(conID, URL, description, name string, gitCredentials *utils.GitCredentials) ([]utils.TemplateRepo, error) {
+	// PFE can't read repos on this machine, so the CLI keeps track of them
+	if _, isLocal := utils.LocalPathFromURL(URL); isLocal {
+		if err := addLocalTemplateRepo(conID, URL, description, name); err != nil {
+			return nil, err
+		}
+		return getReposAfterLocalChange(conID)
+	}
+
 	if _, err := url.ParseRequestURI(URL); err != nil {
 		return nil, fmt.Errorf("Error: '%s' is not a valid URL", URL)
 	}
@@ -62,6 +99,14 @@ func storeGitCredentialsInKeyring(
 // deletes any matching credentials from the keyring and
 // returns the new list of existing repos
 func DeleteTemplateRepo(conID, URL string) ([]utils.TemplateRepo, error) {
+	removed, err := removeLocalTemplateRepo(conID, URL)
+	if err != nil {
+		return nil, err
+	}
+	if removed {
+		return getReposAfterLocalChange(conID)
+	}
+
 	if _, err := url.ParseRequestURI(URL); err != nil {
 		return nil, fmt.Errorf("Error: '%s' is not a valid URL", URL)
 	}
@@ -82,6 +127,37 @@ func DeleteTemplateRepo(conID, URL string) ([]utils.TemplateRepo, error) {
 	return apiroutes.DeleteTemplateRepoFromPFE(conID, URL)
 }
 
+// EnableTemplateRepos enables template repos, in PFE or on disk, and
+// returns the new list of template repos
+func EnableTemplateRepos(conID string, repoURLs []string) ([]utils.TemplateRepo, error) {
+	return setTemplateReposEnabled(conID, repoURLs, true)
+}
+
+// DisableTemplateRepos disables template repos, in PFE or on disk, and
+// returns the new list of template repos
+func DisableTemplateRepos(conID string, repoURLs []string) ([]utils.TemplateRepo, error) {
+	return setTemplateReposEnabled(conID, repoURLs, false)
+}
+
+func setTemplateReposEnabled(conID string, repoURLs []string, enabled bool) ([]utils.TemplateRepo, error) {
+	pfeURLs, err := setLocalTemplateReposEnabled(conID, repoURLs, enabled)
+	if err != nil {
+		return nil, err
+	}
+	if len(repoURLs) > 0 && len(pfeURLs) == 0 {
+		return getReposAfterLocalChange(conID)
+	}
+	if enabled {
+		_, err = apiroutes.EnableTemplateRepos(conID, pfeURLs)
+	} else {
+		_, err = apiroutes.DisableTemplateRepos(conID, pfeURLs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return GetTemplateRepos(conID)
+}
+
 // findTemplateSourceID matches a template URL to its SourceID if it has one
 func findTemplateSourceID(conID, templateURL string) (string, error) {
 	templates, err := apiroutes.GetTemplates(conID, "", false)
@@ -116,6 +192,11 @@ func GetGitCredentialsFromKeychain(conID, templateURL string) (*utils.GitCredent
 	if findErr != nil {
 		return nil, findErr
 	}
+	return getGitCredentialsForSource(conID, sourceID)
+}
+
+// getGitCredentialsForSource gets GitHub credentials for a template repo from the keychain
+func getGitCredentialsForSource(conID, sourceID string) (*utils.GitCredentials, error) {
 	if sourceID == "" {
 		return nil, nil
 	}
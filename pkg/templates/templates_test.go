@@ -94,7 +94,7 @@ func TestSuccessfulAddAndDeleteTemplateRepos(t *testing.T) {
 				assert.Nil(t, keychainErr)
 				assert.Equal(t, test.inGitCredentials, gitCredentials)
 
-				result, projectErr := project.DownloadTemplate(testDir, URLOfAddedTemplate, gitCredentials)
+				result, projectErr := project.DownloadTemplate(testDir, URLOfAddedTemplate, gitCredentials, false)
 				assert.Nil(t, projectErr)
 				if result != nil {
 					assert.Equal(t, result.Status, "success")
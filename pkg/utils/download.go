@@ -20,6 +20,8 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
@@ -39,6 +41,10 @@ type (
 // DownloadFromURLThenExtract downloads files from a URL
 // to a destination, extracting them if necessary
 func DownloadFromURLThenExtract(inURL, destination string, gitCredentials *GitCredentials) error {
+	if localPath, ok := LocalPathFromURL(inURL); ok {
+		return CopyFromLocalPath(localPath, destination)
+	}
+
 	URL, err := url.ParseRequestURI(inURL)
 	if err != nil {
 		return err
@@ -260,6 +266,46 @@ func DownloadFile(URL *url.URL, destination string) error {
 	return err
 }
 
+// LocalPathFromURL returns the path on disk that a template source refers to,
+// if it is a file:// URL or the path of an existing file or directory
+func LocalPathFromURL(inURL string) (string, bool) {
+	if strings.HasPrefix(inURL, "file://") {
+		URL, err := url.Parse(inURL)
+		if err != nil {
+			return "", false
+		}
+		localPath := URL.Path
+		// file:///C:/templates has the path /C:/templates
+		if runtime.GOOS == "windows" {
+			localPath = strings.TrimPrefix(localPath, "/")
+		}
+		return filepath.FromSlash(localPath), true
+	}
+	if strings.Contains(inURL, "://") {
+		return "", false
+	}
+	if _, err := os.Stat(inURL); err != nil {
+		return "", false
+	}
+	return inURL, true
+}
+
+// CopyFromLocalPath copies a template from a directory, leaving out any git metadata,
+// or extracts it from a local tar.gz file
+func CopyFromLocalPath(localPath, destination string) error {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return CopyDir(localPath, destination, ".git")
+	}
+	if strings.HasSuffix(localPath, ".tar.gz") {
+		return UnTar(localPath, destination)
+	}
+	return fmt.Errorf("%s must be a directory or a tar.gz file", localPath)
+}
+
 // IsTarGzURL returns whether the provided URL is a tar.gz file
 func IsTarGzURL(URL *url.URL) bool {
 	return strings.HasSuffix(URL.Path, ".tar.gz")
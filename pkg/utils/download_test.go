@@ -397,3 +397,63 @@ func toURL(inURL string) *url.URL {
 	URL, _ := url.ParseRequestURI(inURL)
 	return URL
 }
+
+func TestLocalPathFromURL(t *testing.T) {
+	existingDir, removeDir := CreateTempTestDir(t)
+	defer removeDir()
+
+	tests := map[string]struct {
+		inURL     string
+		wantPath  string
+		wantLocal bool
+	}{
+		"file URL": {
+			inURL:     "file:///srv/templates",
+			wantPath:  filepath.FromSlash("/srv/templates"),
+			wantLocal: true,
+		},
+		"existing directory": {
+			inURL:     existingDir,
+			wantPath:  existingDir,
+			wantLocal: true,
+		},
+		"path that doesn't exist": {
+			inURL:     filepath.Join(existingDir, "missing"),
+			wantLocal: false,
+		},
+		"GitHub URL": {
+			inURL:     test.PublicGHRepoURL,
+			wantLocal: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gotPath, gotLocal := LocalPathFromURL(test.inURL)
+			assert.Equal(t, test.wantLocal, gotLocal)
+			assert.Equal(t, test.wantPath, gotPath)
+		})
+	}
+}
+
+func TestCopyFromLocalPath(t *testing.T) {
+	sourceDir, removeSourceDir := CreateTempTestDir(t)
+	defer removeSourceDir()
+	targetDir, removeTargetDir := CreateTempTestDir(t)
+	defer removeTargetDir()
+
+	os.Mkdir(filepath.Join(sourceDir, ".git"), 0755)
+	ioutil.WriteFile(filepath.Join(sourceDir, ".git", "HEAD"), []byte("ref: refs/heads/master"), 0644)
+	ioutil.WriteFile(filepath.Join(sourceDir, "package.json"), []byte(`{"name": "[PROJ_NAME_PLACEHOLDER]"}`), 0644)
+
+	t.Run("success case: directory is copied without git metadata", func(t *testing.T) {
+		destination := filepath.Join(targetDir, "fromDir")
+		err := DownloadFromURLThenExtract("file://"+filepath.ToSlash(sourceDir), destination, nil)
+		assert.Nil(t, err)
+		assert.True(t, PathExists(filepath.Join(destination, "package.json")))
+		assert.False(t, PathExists(filepath.Join(destination, ".git")))
+	})
+	t.Run("fail case: file that isn't a tar.gz", func(t *testing.T) {
+		err := CopyFromLocalPath(filepath.Join(sourceDir, "package.json"), filepath.Join(targetDir, "fromFile"))
+		assert.NotNil(t, err)
+	})
+}
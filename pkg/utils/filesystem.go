@@ -133,6 +133,67 @@ func ExtractTarToFileSystem(tarReader *tar.Reader, destination string) error {
 	return nil
 }
 
+// TarFile is a file on disk to be written into a tar archive
+type TarFile struct {
+	Path string      // the path of the file on disk
+	Name string      // the name of the file in the archive
+	Mode os.FileMode // the permissions recorded in the archive
+
+	// Linkname is the target of the symlink written to the archive in place of the file, if set
+	Linkname string
+}
+
+// WriteTarGz writes files to a writer as a gzipped tar stream, which can be read back with UnTar
+func WriteTarGz(writer io.Writer, files []TarFile) error {
+	gzipWriter := gzip.NewWriter(writer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, file := range files {
+		err := addFileToTar(tarWriter, file)
+		if err != nil {
+			return err
+		}
+	}
+	err := tarWriter.Close()
+	if err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+func addFileToTar(tarWriter *tar.Writer, file TarFile) error {
+	if file.Linkname != "" {
+		return tarWriter.WriteHeader(&tar.Header{
+			Name:     file.Name,
+			Mode:     int64(file.Mode.Perm()),
+			Linkname: file.Linkname,
+			Typeflag: tar.TypeSymlink,
+		})
+	}
+
+	fileReader, err := readFile(file.Path)
+	if err != nil {
+		return err
+	}
+	defer fileReader.Close()
+	fileInfo, err := fileReader.Stat()
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{
+		Name:     file.Name,
+		Mode:     int64(file.Mode.Perm()),
+		Size:     fileInfo.Size(),
+		ModTime:  fileInfo.ModTime(),
+		Typeflag: tar.TypeReg,
+	}
+	err = tarWriter.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tarWriter, fileReader)
+	return err
+}
+
 func extractFile(target string, tarReader *tar.Reader, header *tar.Header) {
 	fileToOverwrite, err := overwriteFile(target)
 	defer fileToOverwrite.Close()
@@ -255,6 +316,41 @@ func CopyFile(sourceFilePath, targetFilePath string) error {
 	return err
 }
 
+// CopyDir - copies a directory and everything in it to a target directory, keeping file permissions.
+// Files and directories with any of the exclude names are not copied
+func CopyDir(sourceDir, targetDir string, exclude ...string) error {
+	return filepath.Walk(sourceDir, func(sourcePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if sourcePath != sourceDir {
+			for _, name := range exclude {
+				if info.Name() == name && info.IsDir() {
+					return filepath.SkipDir
+				} else if info.Name() == name {
+					return nil
+				}
+			}
+		}
+		relativePath, err := filepath.Rel(sourceDir, sourcePath)
+		if err != nil {
+			return err
+		}
+		targetPath := filepath.Join(targetDir, relativePath)
+		if info.IsDir() {
+			return os.MkdirAll(targetPath, info.Mode().Perm()|0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		err = CopyFile(sourcePath, targetPath)
+		if err != nil {
+			return err
+		}
+		return os.Chmod(targetPath, info.Mode().Perm())
+	})
+}
+
 //Zip - creates a zip file in the target directory and populates it with the contents of that directory
 func Zip(zipFileName, targetDirectory string) error {
 	newZipFile, zipCreateErr := os.Create(filepath.Join(targetDirectory, zipFileName))
@@ -131,3 +131,58 @@ func TestReplaceInFiles(t *testing.T) {
 		assert.Equal(t, wantFileContent, fileContent)
 	})
 }
+
+func TestWriteTarGz(t *testing.T) {
+	t.Run("writes files that can be extracted with UnTar", func(t *testing.T) {
+		sourceDir, removeSourceDir := CreateTempTestDir(t)
+		defer removeSourceDir()
+		targetDir, removeTargetDir := CreateTempTestDir(t)
+		defer removeTargetDir()
+
+		ioutil.WriteFile(path.Join(sourceDir, "a"), []byte("file a"), 0644)
+		ioutil.WriteFile(path.Join(sourceDir, "b"), []byte("file b"), 0755)
+		os.Mkdir(path.Join(targetDir, "dir"), 0777)
+
+		tarFile, removeTarFile := CreateTempTestFile(t, "")
+		defer removeTarFile()
+		err := WriteTarGz(tarFile, []TarFile{
+			{Path: path.Join(sourceDir, "a"), Name: "a", Mode: 0644},
+			{Path: path.Join(sourceDir, "b"), Name: "dir/b", Mode: 0755},
+		})
+		assert.Nil(t, err)
+		tarFile.Close()
+
+		err = UnTar(tarFile.Name(), targetDir)
+		assert.Nil(t, err)
+		contentA, _ := ioutil.ReadFile(path.Join(targetDir, "a"))
+		contentB, _ := ioutil.ReadFile(path.Join(targetDir, "dir", "b"))
+		assert.Equal(t, "file a", string(contentA))
+		assert.Equal(t, "file b", string(contentB))
+		infoB, _ := os.Stat(path.Join(targetDir, "dir", "b"))
+		assert.Equal(t, os.FileMode(0755), infoB.Mode().Perm())
+	})
+	t.Run("returns error when a file doesn't exist", func(t *testing.T) {
+		tarFile, removeTarFile := CreateTempTestFile(t, "")
+		defer removeTarFile()
+		err := WriteTarGz(tarFile, []TarFile{{Path: "not-created", Name: "a", Mode: 0644}})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCopyDir(t *testing.T) {
+	sourceDir, removeSourceDir := CreateTempTestDir(t)
+	defer removeSourceDir()
+	targetDir, removeTargetDir := CreateTempTestDir(t)
+	defer removeTargetDir()
+
+	os.Mkdir(path.Join(sourceDir, "nested"), 0755)
+	ioutil.WriteFile(path.Join(sourceDir, "a"), []byte("file a"), 0644)
+	ioutil.WriteFile(path.Join(sourceDir, "nested", "run.sh"), []byte("#!/bin/sh"), 0755)
+
+	err := CopyDir(sourceDir, path.Join(targetDir, "copy"))
+	assert.Nil(t, err)
+	contentA, _ := ioutil.ReadFile(path.Join(targetDir, "copy", "a"))
+	assert.Equal(t, "file a", string(contentA))
+	info, _ := os.Stat(path.Join(targetDir, "copy", "nested", "run.sh"))
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
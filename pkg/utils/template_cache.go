@@ -0,0 +1,125 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// CachedTemplate describes a template held in the local template cache
+type CachedTemplate struct {
+	URL      string    `json:"url"`
+	PulledAt time.Time `json:"pulledAt"`
+}
+
+// TemplateCacheDir gets the directory templates are cached in, next to the directory connections are configured in
+func TemplateCacheDir(connectionConfigDir string) string {
+	return path.Join(path.Dir(connectionConfigDir), "templates")
+}
+
+// CacheTemplate downloads a template into the template cache in cacheDir, replacing any copy already there
+func CacheTemplate(cacheDir string, url string, gitCredentials *GitCredentials) error {
+	err := os.MkdirAll(cacheDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	// download next to the cached copy, so that a failed download leaves the cached copy intact
+	key := templateCacheKey(url)
+	downloadDir, err := ioutil.TempDir(cacheDir, key+".download-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(downloadDir)
+
+	err = DownloadFromURLThenExtract(url, downloadDir, gitCredentials)
+	if err != nil {
+		return err
+	}
+
+	templateDir := path.Join(cacheDir, key)
+	os.RemoveAll(templateDir)
+	err = os.Rename(downloadDir, templateDir)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(CachedTemplate{URL: url, PulledAt: time.Now()})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(templateDir+".json", metadata, 0644)
+}
+
+// GetCachedTemplate gets the details of a template in the template cache in cacheDir,
+// returning false if it isn't cached
+func GetCachedTemplate(cacheDir string, url string) (*CachedTemplate, bool) {
+	templateDir := path.Join(cacheDir, templateCacheKey(url))
+	if !PathExists(templateDir) {
+		return nil, false
+	}
+	file, err := ioutil.ReadFile(templateDir + ".json")
+	if err != nil {
+		return nil, false
+	}
+	var cached CachedTemplate
+	err = json.Unmarshal(file, &cached)
+	if err != nil || cached.URL != url {
+		return nil, false
+	}
+	return &cached, true
+}
+
+// CopyCachedTemplate copies a template from the template cache in cacheDir to destination, which must be
+// empty or not exist, returning false if it isn't cached. The template is copied next to destination and
+// then moved into place, so a failed copy doesn't leave part of a template there
+func CopyCachedTemplate(cacheDir string, url string, destination string) (bool, error) {
+	if _, ok := GetCachedTemplate(cacheDir, url); !ok {
+		return false, nil
+	}
+	parentDir := filepath.Dir(destination)
+	err := os.MkdirAll(parentDir, 0755)
+	if err != nil {
+		return true, err
+	}
+	copyDir, err := ioutil.TempDir(parentDir, filepath.Base(destination)+".copy-")
+	if err != nil {
+		return true, err
+	}
+	err = CopyDir(path.Join(cacheDir, templateCacheKey(url)), copyDir)
+	if err == nil {
+		err = os.Chmod(copyDir, 0755)
+	}
+	if err == nil {
+		// an empty destination directory is replaced
+		os.Remove(destination)
+		err = os.Rename(copyDir, destination)
+	}
+	if err != nil {
+		os.RemoveAll(copyDir)
+		return true, err
+	}
+	return true, nil
+}
+
+// templateCacheKey gets the name a template is cached under
+func templateCacheKey(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(hash[:8])
+}
@@ -0,0 +1,75 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package utils
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateCache(t *testing.T) {
+	testFolder, _ := filepath.Abs("template_cache_test_folder_delete_me")
+	cacheDir := filepath.Join(testFolder, "templates")
+	defer os.RemoveAll(testFolder)
+
+	// put a template in the cache as `templates pull` would
+	cachedURL := "https://github.com/codewind-resources/nodeExpressTemplate"
+	templateDir := path.Join(cacheDir, templateCacheKey(cachedURL))
+	os.MkdirAll(templateDir, 0755)
+	ioutil.WriteFile(path.Join(templateDir, "package.json"), []byte(`{"name": "[PROJ_NAME_PLACEHOLDER]"}`), 0644)
+	metadata, _ := json.Marshal(CachedTemplate{URL: cachedURL, PulledAt: time.Now()})
+	ioutil.WriteFile(templateDir+".json", metadata, 0644)
+
+	t.Run("success case - cached template details are only returned for the same URL", func(t *testing.T) {
+		cached, ok := GetCachedTemplate(cacheDir, cachedURL)
+		assert.True(t, ok)
+		assert.Equal(t, cachedURL, cached.URL)
+		_, ok = GetCachedTemplate(cacheDir, cachedURL+"/other")
+		assert.False(t, ok)
+	})
+
+	t.Run("success case - a cached template is copied into an empty directory", func(t *testing.T) {
+		dest := filepath.Join(testFolder, "projects", "empty")
+		os.MkdirAll(dest, 0755)
+		cached, err := CopyCachedTemplate(cacheDir, cachedURL, dest)
+		assert.True(t, cached)
+		assert.Nil(t, err)
+		assert.FileExists(t, filepath.Join(dest, "package.json"))
+	})
+
+	t.Run("success case - a template that isn't cached isn't copied", func(t *testing.T) {
+		dest := filepath.Join(testFolder, "projects", "uncached")
+		cached, err := CopyCachedTemplate(cacheDir, cachedURL+"/other", dest)
+		assert.False(t, cached)
+		assert.Nil(t, err)
+		assert.False(t, PathExists(dest))
+	})
+
+	t.Run("fail case - a failed copy leaves nothing behind", func(t *testing.T) {
+		dest := filepath.Join(testFolder, "projects", "nonempty")
+		os.MkdirAll(dest, 0755)
+		ioutil.WriteFile(filepath.Join(dest, "existing"), []byte("existing"), 0644)
+		cached, err := CopyCachedTemplate(cacheDir, cachedURL, dest)
+		assert.True(t, cached)
+		assert.NotNil(t, err)
+		assert.False(t, PathExists(filepath.Join(dest, "package.json")))
+		leftovers, _ := filepath.Glob(filepath.Join(testFolder, "projects", "nonempty.copy-*"))
+		assert.Empty(t, leftovers)
+	})
+}
@@ -0,0 +1,93 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package workspace
+
+import (
+	"net/http"
+
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/templates"
+)
+
+// Apply : Make the live state of the connections a manifest refers to match it. Changes that are
+// already applied are skipped, so applying the same manifest again does nothing. A change that
+// fails does not stop the others, and is reported with its error
+func Apply(manifest *Manifest) ([]Change, *WorkspaceError) {
+	live, wsErr := getLiveState(manifest)
+	if wsErr != nil {
+		return nil, wsErr
+	}
+	changes := diffWorkspace(manifest, live)
+
+	// the IDs of projects, including those bound while applying, by connection and name
+	projectIDs := map[string]string{}
+	for conID, state := range live {
+		for _, liveProject := range state.projects {
+			projectIDs[conID+"/"+liveProject.Name] = liveProject.ProjectID
+		}
+	}
+
+	for i := range changes {
+		change := &changes[i]
+		switch change.Action {
+		case ActionNone:
+			continue
+		case ActionConflict:
+			change.Error = change.Detail
+			continue
+		}
+
+		switch change.Resource {
+		case ResourceTemplateRepo:
+			spec := change.repo
+			if _, err := templates.AddTemplateRepo(spec.Connection, spec.URL, spec.Description, spec.Name, nil); err != nil {
+				change.Error = err.Error()
+			}
+		case ResourceProject:
+			spec := change.project
+			response, projErr := project.Bind(spec.Path, spec.Name, spec.Language, spec.Type, spec.Connection)
+			if projErr != nil {
+				change.Error = projErr.Desc
+			}
+			if response != nil && response.ProjectID != "" {
+				projectIDs[spec.Connection+"/"+spec.Name] = response.ProjectID
+			}
+		case ResourceLink:
+			change.Error = applyLink(change, live[change.Connection], projectIDs)
+		}
+	}
+	return changes, nil
+}
+
+// applyLink : Create a link, deleting the link it replaces first, returning the error if it fails
+func applyLink(change *Change, state *connectionState, projectIDs map[string]string) string {
+	spec := change.link
+	sourceID := projectIDs[spec.Connection+"/"+spec.Project]
+	targetID := projectIDs[spec.Connection+"/"+spec.Target]
+	if sourceID == "" {
+		return "project " + spec.Project + " was not bound"
+	}
+	if targetID == "" {
+		return "target project " + spec.Target + " was not bound"
+	}
+	if change.Action == ActionReplace {
+		projErr := project.DeleteProjectLink(http.DefaultClient, state.connection, state.url, sourceID, spec.Env)
+		if projErr != nil {
+			return projErr.Desc
+		}
+	}
+	projErr := project.CreateProjectLink(http.DefaultClient, state.connection, state.url, sourceID, targetID, spec.Env)
+	if projErr != nil {
+		return projErr.Desc
+	}
+	return ""
+}
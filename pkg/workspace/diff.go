@@ -0,0 +1,226 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package workspace
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/templates"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// Change : A difference between the manifest and the live state of a connection, and
+	// what applying the manifest does about it
+	Change struct {
+		Resource   string `json:"resource"`
+		Action     string `json:"action"`
+		Connection string `json:"connection"`
+		Name       string `json:"name"`
+		Detail     string `json:"detail,omitempty"`
+		Error      string `json:"error,omitempty"`
+
+		project *ProjectSpec
+		link    *LinkSpec
+		repo    *TemplateRepoSpec
+	}
+
+	// connectionState : The projects, links and template repos that exist on a connection
+	connectionState struct {
+		connection *connections.Connection
+		url        string
+		projects   []project.Project
+		links      map[string][]project.Link // by project ID
+		repos      []utils.TemplateRepo
+	}
+)
+
+// The resources a manifest describes
+const (
+	ResourceTemplateRepo = "templateRepo"
+	ResourceProject      = "project"
+	ResourceLink         = "link"
+)
+
+// The actions a change can have
+const (
+	ActionNone     = "none"     // the resource already matches the manifest
+	ActionAdd      = "add"      // the resource is created
+	ActionReplace  = "replace"  // the resource exists but differs, so is deleted and created again
+	ActionConflict = "conflict" // the resource differs in a way that can't be applied
+)
+
+// Diff : Compare a manifest with the live state of the connections it refers to
+func Diff(manifest *Manifest) ([]Change, *WorkspaceError) {
+	live, wsErr := getLiveState(manifest)
+	if wsErr != nil {
+		return nil, wsErr
+	}
+	return diffWorkspace(manifest, live), nil
+}
+
+// getLiveState : Get the projects and their links on each connection the manifest refers to,
+// and the template repos of connections the manifest adds repos to
+func getLiveState(manifest *Manifest) (map[string]*connectionState, *WorkspaceError) {
+	live := map[string]*connectionState{}
+	for _, conID := range manifest.connections() {
+		conInfo, conInfoErr := connections.GetConnectionByID(conID)
+		if conInfoErr != nil {
+			return nil, &WorkspaceError{errOpConNotFound, conInfoErr.Err, conInfoErr.Desc}
+		}
+		conURL, conURLErr := config.PFEOriginFromConnection(conInfo)
+		if conURLErr != nil {
+			return nil, &WorkspaceError{errOpConNotFound, conURLErr.Err, conURLErr.Desc}
+		}
+
+		state := &connectionState{connection: conInfo, url: conURL, links: map[string][]project.Link{}}
+		projects, projErr := project.GetAll(http.DefaultClient, conInfo, conURL)
+		if projErr != nil {
+			return nil, &WorkspaceError{errOpLiveState, projErr.Err, projErr.Desc}
+		}
+		state.projects = projects
+		for _, spec := range manifest.Links {
+			if spec.Connection != conID {
+				continue
+			}
+			if liveProject := findProject(projects, spec.Project); liveProject != nil {
+				if _, fetched := state.links[liveProject.ProjectID]; fetched {
+					continue
+				}
+				links, projErr := project.GetProjectLinks(http.DefaultClient, conInfo, conURL, liveProject.ProjectID)
+				if projErr != nil {
+					return nil, &WorkspaceError{errOpLiveState, projErr.Err, projErr.Desc}
+				}
+				state.links[liveProject.ProjectID] = links
+			}
+		}
+		for _, spec := range manifest.TemplateRepos {
+			if spec.Connection == conID {
+				repos, err := templates.GetTemplateRepos(conID)
+				if err != nil {
+					return nil, &WorkspaceError{errOpLiveState, err, err.Error()}
+				}
+				state.repos = repos
+				break
+			}
+		}
+		live[conID] = state
+	}
+	return live, nil
+}
+
+// diffWorkspace : List the changes needed to make the live state match the manifest, in the order
+// they must be applied. Resources that aren't in the manifest are left alone
+func diffWorkspace(manifest *Manifest, live map[string]*connectionState) []Change {
+	changes := []Change{}
+
+	for i := range manifest.TemplateRepos {
+		spec := &manifest.TemplateRepos[i]
+		change := Change{Resource: ResourceTemplateRepo, Action: ActionAdd, Connection: spec.Connection, Name: spec.URL, repo: spec}
+		for _, repo := range live[spec.Connection].repos {
+			if repo.URL == spec.URL {
+				change.Action = ActionNone
+				break
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	// projects the manifest binds, by connection and name
+	adding := map[string]bool{}
+	for i := range manifest.Projects {
+		spec := &manifest.Projects[i]
+		change := Change{Resource: ResourceProject, Action: ActionAdd, Connection: spec.Connection, Name: spec.Name, project: spec}
+		liveProject := findProject(live[spec.Connection].projects, spec.Name)
+		switch {
+		case liveProject == nil:
+			change.Detail = fmt.Sprintf("bind %s (%s, %s)", spec.Path, spec.Language, spec.Type)
+			adding[spec.Connection+"/"+spec.Name] = true
+		default:
+			if differences := projectDifferences(spec, liveProject); len(differences) > 0 {
+				change.Action = ActionConflict
+				change.Detail = fmt.Sprintf("already bound with %s, unbind it to change it", strings.Join(differences, ", "))
+			} else {
+				change.Action = ActionNone
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	for i := range manifest.Links {
+		spec := &manifest.Links[i]
+		state := live[spec.Connection]
+		change := Change{Resource: ResourceLink, Action: ActionAdd, Connection: spec.Connection, Name: spec.Project + ":" + spec.Env, link: spec}
+		source := findProject(state.projects, spec.Project)
+		target := findProject(state.projects, spec.Target)
+		switch {
+		case source == nil && !adding[spec.Connection+"/"+spec.Project]:
+			change.Action = ActionConflict
+			change.Detail = fmt.Sprintf("project %s is not bound or in the manifest", spec.Project)
+		case target == nil && !adding[spec.Connection+"/"+spec.Target]:
+			change.Action = ActionConflict
+			change.Detail = fmt.Sprintf("target project %s is not bound or in the manifest", spec.Target)
+		default:
+			change.Detail = "link to " + spec.Target
+			if source == nil {
+				break
+			}
+			for _, link := range state.links[source.ProjectID] {
+				if link.EnvName != spec.Env {
+					continue
+				}
+				if link.ProjectName == spec.Target {
+					change.Action = ActionNone
+					change.Detail = ""
+				} else {
+					change.Action = ActionReplace
+					change.Detail = fmt.Sprintf("link to %s instead of %s", spec.Target, link.ProjectName)
+				}
+				break
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// projectDifferences : Describe how a bound project differs from its spec. The type and path are only
+// compared if PFE reports them
+func projectDifferences(spec *ProjectSpec, liveProject *project.Project) []string {
+	differences := []string{}
+	if liveProject.Language != spec.Language {
+		differences = append(differences, fmt.Sprintf("language %s instead of %s", liveProject.Language, spec.Language))
+	}
+	if liveProject.ProjectType != "" && liveProject.ProjectType != spec.Type {
+		differences = append(differences, fmt.Sprintf("type %s instead of %s", liveProject.ProjectType, spec.Type))
+	}
+	if liveProject.LocationOnDisk != "" && filepath.Clean(liveProject.LocationOnDisk) != filepath.Clean(spec.Path) {
+		differences = append(differences, fmt.Sprintf("path %s instead of %s", liveProject.LocationOnDisk, spec.Path))
+	}
+	return differences
+}
+
+// findProject : Find a project by name
+func findProject(projects []project.Project, name string) *project.Project {
+	for i := range projects {
+		if projects[i].Name == name {
+			return &projects[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package workspace
+
+import (
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffWorkspace(t *testing.T) {
+	manifest := &Manifest{
+		Projects: []ProjectSpec{
+			{"api", "/work/api", "java", "spring", "local"},
+			{"web", "/work/web", "javascript", "nodejs", "local"},
+			{"worker", "/work/worker", "python", "docker", "local"},
+			{"db", "/work/db", "go", "docker", "local"},
+			{"cache", "/work/cache", "go", "docker", "local"},
+			{"queue", "/work/queue", "go", "docker", "local"},
+		},
+		Links: []LinkSpec{
+			{"web", "API_URL", "api", "local"},
+			{"web", "WORKER_URL", "worker", "local"},
+			{"web", "DB_URL", "db", "local"},
+			{"worker", "API_URL", "api", "local"},
+			{"web", "AUTH_URL", "auth", "local"},
+		},
+		TemplateRepos: []TemplateRepoSpec{
+			{URL: "https://example.com/existing.json", Connection: "local"},
+			{URL: "https://example.com/new.json", Connection: "local"},
+		},
+	}
+	live := map[string]*connectionState{
+		"local": {
+			projects: []project.Project{
+				{ProjectID: "1", Name: "api", Language: "java", ProjectType: "spring", LocationOnDisk: "/work/api"},
+				{ProjectID: "2", Name: "web", Language: "javascript"},
+				{ProjectID: "3", Name: "db", Language: "python"},
+				{ProjectID: "4", Name: "cache", Language: "go", ProjectType: "appsodyExtension", LocationOnDisk: "/work/cache"},
+				{ProjectID: "5", Name: "queue", Language: "go", ProjectType: "docker", LocationOnDisk: "/old/queue/"},
+			},
+			links: map[string][]project.Link{
+				"2": {
+					{ProjectID: "1", ProjectName: "api", EnvName: "API_URL"},
+					{ProjectID: "3", ProjectName: "db", EnvName: "WORKER_URL"},
+				},
+			},
+			repos: []utils.TemplateRepo{{URL: "https://example.com/existing.json"}},
+		},
+	}
+
+	type result struct{ resource, action, name string }
+	var got []result
+	details := map[string]string{}
+	for _, change := range diffWorkspace(manifest, live) {
+		got = append(got, result{change.Resource, change.Action, change.Name})
+		details[change.Name] = change.Detail
+	}
+	assert.Equal(t, []result{
+		{ResourceTemplateRepo, ActionNone, "https://example.com/existing.json"},
+		{ResourceTemplateRepo, ActionAdd, "https://example.com/new.json"},
+		{ResourceProject, ActionNone, "api"},
+		{ResourceProject, ActionNone, "web"},
+		{ResourceProject, ActionAdd, "worker"},
+		{ResourceProject, ActionConflict, "db"},
+		{ResourceProject, ActionConflict, "cache"},
+		{ResourceProject, ActionConflict, "queue"},
+		{ResourceLink, ActionNone, "web:API_URL"},
+		{ResourceLink, ActionReplace, "web:WORKER_URL"},
+		{ResourceLink, ActionAdd, "web:DB_URL"},
+		{ResourceLink, ActionAdd, "worker:API_URL"},
+		{ResourceLink, ActionConflict, "web:AUTH_URL"},
+	}, got)
+	assert.Equal(t, "already bound with language python instead of go, unbind it to change it", details["db"])
+	assert.Equal(t, "already bound with type appsodyExtension instead of docker, unbind it to change it", details["cache"])
+	assert.Equal(t, "already bound with path /old/queue/ instead of /work/queue, unbind it to change it", details["queue"])
+}
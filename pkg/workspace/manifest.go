@@ -0,0 +1,189 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// Manifest : The projects, links between them and template repos a workspace should have
+	Manifest struct {
+		Projects      []ProjectSpec      `yaml:"projects" json:"projects"`
+		Links         []LinkSpec         `yaml:"links" json:"links"`
+		TemplateRepos []TemplateRepoSpec `yaml:"templateRepos" json:"templateRepos"`
+	}
+
+	// ProjectSpec : A project to bind. A relative path is relative to the manifest
+	ProjectSpec struct {
+		Name       string `yaml:"name" json:"name"`
+		Path       string `yaml:"path" json:"path"`
+		Language   string `yaml:"language" json:"language"`
+		Type       string `yaml:"type" json:"type"`
+		Connection string `yaml:"connection" json:"connection"`
+	}
+
+	// LinkSpec : An environment variable in a project that gives the URL of a target project
+	// on the same connection
+	LinkSpec struct {
+		Project    string `yaml:"project" json:"project"`
+		Env        string `yaml:"env" json:"env"`
+		Target     string `yaml:"target" json:"target"`
+		Connection string `yaml:"connection" json:"connection"`
+	}
+
+	// TemplateRepoSpec : A template repo to add. A relative local path is relative to the manifest
+	TemplateRepoSpec struct {
+		URL         string `yaml:"url" json:"url"`
+		Name        string `yaml:"name" json:"name"`
+		Description string `yaml:"description" json:"description"`
+		Connection  string `yaml:"connection" json:"connection"`
+	}
+
+	// WorkspaceError : A workspace error
+	WorkspaceError struct {
+		Op   string
+		Err  error
+		Desc string
+	}
+)
+
+const (
+	errOpManifestLoad  = "workspace_manifest_load"
+	errOpManifestParse = "workspace_manifest_parse"
+	errOpConNotFound   = "connection_notfound"
+	errOpLiveState     = "workspace_live_state"
+)
+
+// DefaultManifestFilename : The manifest read when no file is given
+const DefaultManifestFilename = "codewind.workspace.yaml"
+
+// defaultConnection : The connection used for entries that don't name one
+const defaultConnection = "local"
+
+// WorkspaceError : Error formatted in JSON containing an errorOp and a description from
+// either a fault condition in the CLI, or an error payload from a REST request
+func (we *WorkspaceError) Error() string {
+	type Output struct {
+		Operation   string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	tempOutput := &Output{Operation: we.Op, Description: we.Err.Error()}
+	jsonError, _ := json.Marshal(tempOutput)
+	return string(jsonError)
+}
+
+// LoadManifest : Read and check a workspace manifest, filling in default connections and
+// resolving relative paths against the directory containing it
+func LoadManifest(filename string) (*Manifest, *WorkspaceError) {
+	file, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, &WorkspaceError{errOpManifestLoad, err, err.Error()}
+	}
+	var manifest Manifest
+	err = yaml.UnmarshalStrict(file, &manifest)
+	if err != nil {
+		err = fmt.Errorf("unable to parse workspace manifest %s: %v", filename, err)
+		return nil, &WorkspaceError{errOpManifestParse, err, err.Error()}
+	}
+
+	baseDir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, &WorkspaceError{errOpManifestLoad, err, err.Error()}
+	}
+	err = manifest.normalise(baseDir)
+	if err != nil {
+		err = fmt.Errorf("invalid workspace manifest %s: %v", filename, err)
+		return nil, &WorkspaceError{errOpManifestParse, err, err.Error()}
+	}
+	return &manifest, nil
+}
+
+// normalise : Check each entry has its required fields, and fill in defaults
+func (manifest *Manifest) normalise(baseDir string) error {
+	connectionOf := map[string]string{}
+	for i := range manifest.Projects {
+		spec := &manifest.Projects[i]
+		if spec.Name == "" || spec.Path == "" || spec.Language == "" || spec.Type == "" {
+			return fmt.Errorf("project %d must have a name, path, language and type", i+1)
+		}
+		spec.Connection = normaliseConnection(spec.Connection)
+		if !filepath.IsAbs(spec.Path) {
+			spec.Path = filepath.Join(baseDir, spec.Path)
+		}
+		if _, exists := connectionOf[spec.Name]; exists {
+			return fmt.Errorf("project '%s' is listed more than once", spec.Name)
+		}
+		connectionOf[spec.Name] = spec.Connection
+	}
+
+	for i := range manifest.Links {
+		spec := &manifest.Links[i]
+		if spec.Project == "" || spec.Env == "" || spec.Target == "" {
+			return fmt.Errorf("link %d must have a project, env and target", i+1)
+		}
+		// a link is on the connection of the project in the manifest, unless it says otherwise
+		if spec.Connection == "" {
+			spec.Connection = connectionOf[spec.Project]
+		}
+		spec.Connection = normaliseConnection(spec.Connection)
+	}
+
+	for i := range manifest.TemplateRepos {
+		spec := &manifest.TemplateRepos[i]
+		if spec.URL == "" {
+			return fmt.Errorf("template repo %d must have a url", i+1)
+		}
+		spec.Connection = normaliseConnection(spec.Connection)
+		if !strings.Contains(spec.URL, "://") && !filepath.IsAbs(spec.URL) {
+			spec.URL = filepath.Join(baseDir, spec.URL)
+		}
+	}
+	return nil
+}
+
+// connections : Get the connections the manifest refers to, in the order they first appear
+func (manifest *Manifest) connections() []string {
+	var conIDs []string
+	add := func(conID string) {
+		for _, existing := range conIDs {
+			if existing == conID {
+				return
+			}
+		}
+		conIDs = append(conIDs, conID)
+	}
+	for _, spec := range manifest.TemplateRepos {
+		add(spec.Connection)
+	}
+	for _, spec := range manifest.Projects {
+		add(spec.Connection)
+	}
+	for _, spec := range manifest.Links {
+		add(spec.Connection)
+	}
+	return conIDs
+}
+
+func normaliseConnection(conID string) string {
+	conID = strings.TrimSpace(strings.ToLower(conID))
+	if conID == "" {
+		return defaultConnection
+	}
+	return conID
+}
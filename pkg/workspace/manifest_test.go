@@ -0,0 +1,98 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadManifest(t *testing.T) {
+	testDir, _ := ioutil.TempDir("", "workspace-manifest")
+	defer os.RemoveAll(testDir)
+
+	tests := map[string]struct {
+		manifest     string
+		wantManifest *Manifest
+		wantErrOp    string
+	}{
+		"success case - defaults are filled in and relative paths resolved": {
+			manifest: `
+projects:
+  - name: api
+    path: services/api
+    language: java
+    type: spring
+  - name: web
+    path: /work/web
+    language: javascript
+    type: nodejs
+    connection: Remote1
+links:
+  - project: web
+    env: API_URL
+    target: api
+templateRepos:
+  - url: https://example.com/templates.json
+  - url: ./templates
+    connection: remote1
+`,
+			wantManifest: &Manifest{
+				Projects: []ProjectSpec{
+					{"api", filepath.Join(testDir, "services", "api"), "java", "spring", "local"},
+					{"web", "/work/web", "javascript", "nodejs", "remote1"},
+				},
+				Links: []LinkSpec{{"web", "API_URL", "api", "remote1"}},
+				TemplateRepos: []TemplateRepoSpec{
+					{URL: "https://example.com/templates.json", Connection: "local"},
+					{URL: filepath.Join(testDir, "templates"), Connection: "remote1"},
+				},
+			},
+		},
+		"fail case - a project without a type": {
+			manifest:  "projects:\n  - name: api\n    path: api\n    language: java\n",
+			wantErrOp: errOpManifestParse,
+		},
+		"fail case - a project listed twice": {
+			manifest:  "projects:\n  - {name: api, path: a, language: go, type: docker}\n  - {name: api, path: b, language: go, type: docker}\n",
+			wantErrOp: errOpManifestParse,
+		},
+		"fail case - an unknown field": {
+			manifest:  "project:\n  - name: api\n",
+			wantErrOp: errOpManifestParse,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(testDir, DefaultManifestFilename)
+			ioutil.WriteFile(filename, []byte(test.manifest), 0644)
+
+			got, err := LoadManifest(filename)
+			if test.wantErrOp != "" {
+				assert.Nil(t, got)
+				assert.Equal(t, test.wantErrOp, err.Op)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.wantManifest, got)
+		})
+	}
+
+	t.Run("fail case - a missing manifest", func(t *testing.T) {
+		_, err := LoadManifest(filepath.Join(testDir, "missing.yaml"))
+		assert.Equal(t, errOpManifestLoad, err.Op)
+	})
+}